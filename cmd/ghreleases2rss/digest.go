@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// digestCmd writes a digest of recent releases.
+//
+// The release feeds of the repos listed in the input sources are fetched once
+// through the feed cache, and the releases published since --since, such as
+// 7d, 2w or 2026-10-01, are listed grouped by repo. --category limits the
+// digest to the repos under one [Category] section header of the input. A
+// summary at the top lists the releases that stand out, labeled as serve
+// --classify does: major version bumps over the previous stable release, and
+// releases whose notes mention BREAKING changes, deprecations, security or a
+// CVE or GHSA advisory ID, with the lines that mention them quoted.
+// Filter options after a repo apply to its releases.
+//
+// The digest is written as Markdown, or as an HTML page with --format html
+// or an --output file ending in .html, to stdout or the --output file. With
+// --smtp-addr it is emailed to the --smtp-to recipients instead, using
+// SMTP_USERNAME and SMTP_PASSWORD as for watch; digests without releases are
+// not sent. Run it weekly from cron for a weekly digest. Miniflux
+// configuration is not required.
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Write a Markdown or HTML digest of recent releases",
+	Long:  `Fetch release feeds of the repos in input files and write the releases published since a date, grouped by repo with major version bumps, breaking changes and security fixes highlighted, to stdout, a file or email recipients`,
+	Args:  cobra.ExactArgs(0),
+	Run:   digestCmdRun,
+}
+
+// digestCmdRun passes the loaded configuration to the ghreleases2rss.Digest function.
+func digestCmdRun(cmd *cobra.Command, args []string) {
+	ghreleases2rss.Digest(cmd, args, conf)
+}
+
+func init() {
+	digestCmd.Flags().StringArrayP("file", "f", nil, "Input file, glob pattern, - for stdin, HTTPS URL or git+https://host/org/repo.git//path@ref source with GitHub repo URLs or names under [Category] headers; repeatable (required)")
+	digestCmd.Flags().String("since", "7d", "Start of the digest as days or weeks ago (7d, 2w), a duration (36h) or a date (2026-10-01)")
+	digestCmd.Flags().StringP("category", "c", "", "Only include repos of this [Category] section (optional)")
+	digestCmd.Flags().StringP("output", "o", "", "File to write the digest to (default stdout, or none with --smtp-addr)")
+	digestCmd.Flags().String("format", "", "Digest format, markdown or html (default html for --output files ending in .html, otherwise markdown)")
+	digestCmd.Flags().String("title", "", "Digest title (default \"Release digest\" followed by the category)")
+	digestCmd.Flags().String("smtp-addr", "", "SMTP server host:port to email the digest through (optional)")
+	digestCmd.Flags().String("smtp-from", "", "Sender address of the digest email")
+	digestCmd.Flags().StringArray("smtp-to", nil, "Recipient address of the digest email; repeatable")
+	digestCmd.Flags().String("default-category", "Releases", "Category of repos listed outside a [Category] section")
+	digestCmd.Flags().Bool("enrich", false, "Add assets, authors, compare links and rendered notes from the GitHub API to release entries")
+	digestCmd.Flags().Duration("max-age", cache.DefaultMaxAge, "How long a cached feed is used before it is revalidated upstream")
+	digestCmd.Flags().Int("concurrency", cache.DefaultPerHost, "Number of feeds fetched at once per upstream host")
+	_ = digestCmd.MarkFlagRequired("file")
+}
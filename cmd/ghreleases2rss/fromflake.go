@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// fromFlakeCmd subscribes to the repositories of Nix flake inputs.
+//
+// It walks each path for flake.lock files and maps every input of type github
+// to its owner/repo directly. Inputs of other types are ignored. By default
+// transitive inputs of dependencies are included; --direct-only keeps just the
+// inputs the root flake declares.
+var fromFlakeCmd = &cobra.Command{
+	Use:   "from-flake path...",
+	Short: "Subscribe to releases of the github inputs locked in flake.lock files",
+	Long:  `Subscribe to GitHub repo release feeds for the github-type inputs of Nix flake.lock files`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   subscribeCmdRun(ghreleases2rss.FromFlake),
+}
+
+func init() {
+	addSubscribeFlags(fromFlakeCmd)
+	fromFlakeCmd.Flags().Bool("direct-only", false, "Only include inputs declared by the root flake")
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// fromGoModCmd subscribes to the repositories a Go module depends on.
+//
+// Each require directive is mapped to a GitHub repository. Modules hosted on
+// github.com are mapped directly, and vanity import paths such as
+// golang.org/x/net or gopkg.in/yaml.v3 are resolved through their go-import
+// and go-source meta tags.
+var fromGoModCmd = &cobra.Command{
+	Use:   "from-gomod path/to/go.mod...",
+	Short: "Subscribe to releases of the dependencies in go.mod files",
+	Long:  `Subscribe to GitHub repo release feeds for the modules required by go.mod files`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   subscribeCmdRun(ghreleases2rss.FromGoMod),
+}
+
+func init() {
+	addSubscribeFlags(fromGoModCmd)
+	fromGoModCmd.Flags().Bool("skip-indirect", false, "Skip dependencies marked // indirect")
+}
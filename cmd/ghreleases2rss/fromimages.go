@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// fromImagesCmd subscribes to the upstream repositories of container images.
+//
+// It walks each path for Dockerfiles (FROM), Compose files and Kubernetes or
+// Helm-rendered manifests (image:). GHCR images are mapped to their GitHub
+// repository directly, and images on other registries such as Docker Hub or
+// Quay are mapped through their org.opencontainers.image.source label.
+// Registry lookups are cached in the cache directory.
+var fromImagesCmd = &cobra.Command{
+	Use:   "from-images path...",
+	Short: "Subscribe to releases of the container images used in Dockerfiles and manifests",
+	Long:  `Subscribe to GitHub repo release feeds for the upstreams of images in Dockerfiles, Compose files and Kubernetes manifests`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   subscribeCmdRun(ghreleases2rss.FromImages),
+}
+
+func init() {
+	addSubscribeFlags(fromImagesCmd)
+}
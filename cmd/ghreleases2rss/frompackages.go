@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// fromPackagesCmd subscribes to the source repositories of package dependencies.
+//
+// Each path is a manifest or a directory that is searched for package.json,
+// requirements*.txt, pyproject.toml and Cargo.toml files. Dependencies are
+// mapped to GitHub through the repository and homepage fields of their npm,
+// PyPI or crates.io metadata, and the registries can be replaced with
+// NPM_REGISTRY_URL, PYPI_URL and CRATES_URL. Packages that cannot be mapped
+// are listed after subscribing.
+var fromPackagesCmd = &cobra.Command{
+	Use:   "from-packages path...",
+	Short: "Subscribe to releases of the npm, PyPI and crates.io packages a project depends on",
+	Long:  `Subscribe to GitHub repo release feeds for dependencies in package.json, requirements.txt, pyproject.toml and Cargo.toml files`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   subscribeCmdRun(ghreleases2rss.FromPackages),
+}
+
+func init() {
+	addSubscribeFlags(fromPackagesCmd)
+}
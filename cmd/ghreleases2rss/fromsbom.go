@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// fromSBOMCmd subscribes to the source repositories of SBOM components.
+//
+// It reads CycloneDX and SPDX JSON documents and maps each component through
+// its VCS references and purl: pkg:github and pkg:golang/github.com purls map
+// directly, while other Go, npm, PyPI and Cargo purls are resolved like the
+// from-gomod and from-packages sources. Components can be limited by type
+// (e.g. library, application, container) and by CycloneDX scope.
+var fromSBOMCmd = &cobra.Command{
+	Use:   "from-sbom sbom.json...",
+	Short: "Subscribe to releases of the components listed in CycloneDX or SPDX SBOMs",
+	Long:  `Subscribe to GitHub repo release feeds for the components of CycloneDX and SPDX JSON SBOMs`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   subscribeCmdRun(ghreleases2rss.FromSBOM),
+}
+
+func init() {
+	addSubscribeFlags(fromSBOMCmd)
+	fromSBOMCmd.Flags().StringSlice("type", nil, "Only include components of these types, e.g. library,application")
+	fromSBOMCmd.Flags().StringSlice("scope", nil, "Only include CycloneDX components with these scopes, e.g. required")
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// fromTerraformCmd subscribes to the source repositories of Terraform providers.
+//
+// It walks each path for .terraform.lock.hcl files and looks every provider
+// address up in its registry's /v1/providers API, which lists the provider's
+// source repository. TERRAFORM_REGISTRY_URL replaces the registry, e.g. with
+// a mirror or https://registry.opentofu.org.
+var fromTerraformCmd = &cobra.Command{
+	Use:   "from-terraform path...",
+	Short: "Subscribe to releases of the providers pinned in Terraform lock files",
+	Long:  `Subscribe to GitHub repo release feeds for the providers in .terraform.lock.hcl files`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   subscribeCmdRun(ghreleases2rss.FromTerraform),
+}
+
+func init() {
+	addSubscribeFlags(fromTerraformCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// fromToolsCmd subscribes to the repositories of CLIs pinned by tool managers.
+//
+// It walks each path for asdf .tool-versions, mise.toml, aqua.yaml and
+// Homebrew Brewfile manifests. Tools are mapped to GitHub through a built-in
+// registry of well-known tools, which --registry files can extend or
+// override with a `tools:` map of name to owner/repo. mise github:, ubi: and
+// aqua: backends and aqua owner/repo packages map directly. Tools without a
+// mapping are listed after subscribing.
+var fromToolsCmd = &cobra.Command{
+	Use:   "from-tools path...",
+	Short: "Subscribe to releases of the CLIs pinned in asdf, mise, aqua and Homebrew manifests",
+	Long:  `Subscribe to GitHub repo release feeds for tools in .tool-versions, mise.toml, aqua.yaml and Brewfile manifests`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   subscribeCmdRun(ghreleases2rss.FromTools),
+}
+
+func init() {
+	addSubscribeFlags(fromToolsCmd)
+	fromToolsCmd.Flags().StringArray("registry", nil, "Tool registry YAML file that extends the built-in mappings (repeatable)")
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// fromWorkflowsCmd subscribes to the GitHub Actions a repository uses.
+//
+// It parses .github/workflows/*.yml files and composite action.yml files
+// below each directory and extracts `uses: owner/repo[/path]@ref` references.
+// Local ./ actions and docker:// references are ignored.
+var fromWorkflowsCmd = &cobra.Command{
+	Use:   "from-workflows dir...",
+	Short: "Subscribe to releases of the GitHub Actions used in workflow files",
+	Long:  `Subscribe to GitHub repo release feeds for the actions referenced by workflow and composite action files`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   subscribeCmdRun(ghreleases2rss.FromWorkflows),
+}
+
+func init() {
+	addSubscribeFlags(fromWorkflowsCmd)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// outdatedCmd reports how far pinned versions are behind the latest release.
+//
+// Input lines may pin the version in use as owner/repo@v1.4.2. Each pinned
+// repo's release feed is fetched and compared with the pin as semver, falling
+// back to release dates for tags that are not semantic versions. A table of
+// current and latest versions and the number of releases in between is
+// printed, and the release notes of the skipped releases are concatenated
+// into a Markdown upgrade brief per repo. Miniflux configuration is not
+// required.
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Report pinned repos that are behind their latest release",
+	Long:  `Compare owner/repo@version pins in input files with each repo's releases and write upgrade briefs`,
+	Args:  cobra.ExactArgs(0),
+	Run:   outdatedCmdRun,
+}
+
+// outdatedCmdRun passes the loaded configuration to the ghreleases2rss.Outdated function.
+func outdatedCmdRun(cmd *cobra.Command, args []string) {
+	ghreleases2rss.Outdated(cmd, args, conf)
+}
+
+func init() {
+	outdatedCmd.Flags().StringArrayP("file", "f", nil, "Input file, glob pattern, - for stdin, HTTPS URL or git+https://host/org/repo.git//path@ref source with pinned owner/repo@version lines; repeatable (required)")
+	outdatedCmd.Flags().String("brief-dir", "upgrade-briefs", "Directory to write a Markdown upgrade brief per outdated repo to, empty to disable")
+	_ = outdatedCmd.MarkFlagRequired("file")
+}
@@ -38,6 +38,10 @@ import (
 // When true, debug-level logging is enabled through logrus.
 var debug bool
 
+// allowedDirs holds extra directories that local files may be read from.
+// They are combined with the ALLOWED_DIRS environment variable.
+var allowedDirs []string
+
 // conf holds the application configuration loaded from environment variables.
 var conf config.Config
 
@@ -72,16 +76,17 @@ func rootCmdRun(cmd *cobra.Command, args []string) {
 // rootCmdPreRun performs setup operations before executing the root command.
 // This function is called before both the root command and any subcommands.
 //
-// It loads configuration from environment variables and configures the logging
-// level based on the debug flag. When debug mode is enabled, logrus is set to
-// DebugLevel for detailed logging output.
+// It loads configuration from environment variables, reading files only from
+// the --allowed-dir directories, and configures the logging level based on the
+// debug flag. When debug mode is enabled, logrus is set to DebugLevel for
+// detailed logging output.
 //
 // Parameters:
 //   - cmd: The cobra command being executed
 //   - args: Command-line arguments
 func rootCmdPreRun(cmd *cobra.Command, args []string) {
 	// Load configuration from environment variables
-	conf = config.GetEnvVars()
+	conf = config.GetEnvVars(allowedDirs...)
 
 	if debug {
 		log.SetLevel(log.DebugLevel)
@@ -112,24 +117,65 @@ func Execute() {
 // This function performs the following setup operations:
 //   - Defines persistent flags that are available to all commands
 //   - Sets up command-specific flags for the root command
-//   - Registers subcommands (man pages and version information)
+//   - Registers subcommands (man pages, version information and the
+//     validate, from-*, scan, outdated, serve, build-site, watch and digest
+//     commands)
 //
 // The debug flag (-d, --debug) enables debug-level logging and is persistent,
-// meaning it's inherited by all subcommands. The clearCategoryFeeds flag (-r)
-// allows clearing existing feeds in a category before adding new ones. The file
-// flag (-f) specifies the input file containing GitHub repository URLs, and the
-// category flag (-c) allows organizing feeds into categories.
+// meaning it's inherited by all subcommands, as is the allowed-dir flag for
+// directories that input files may be read from. The clearCategoryFeeds flag
+// (-r) allows clearing existing feeds in a category before adding new ones.
+// The file flag (-f) specifies the input sources containing GitHub
+// repository URLs and may be repeated.
 func init() {
 	// create rootCmd-level flags
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug-level logging")
 	rootCmd.PersistentFlags().BoolP("clearCategoryFeeds", "r", false, "Delete all feeds within category before subscribing to new feeds")
-	rootCmd.Flags().StringP("file", "f", "", "Input file with GitHub repo URLs or names (required)")
-	rootCmd.Flags().StringP("category", "c", "", "RSS feed category name (optional)")
+	rootCmd.PersistentFlags().StringArrayVar(&allowedDirs, "allowed-dir", nil, "Directory that input files may be read from; repeatable (default: current directory)")
+	rootCmd.Flags().StringArrayP("file", "f", nil, "Input file, glob pattern, - for stdin, HTTPS URL or git+https://host/org/repo.git//path@ref source with GitHub repo URLs or names; repeatable (required)")
+	addSubscribeFlags(rootCmd)
 	_ = rootCmd.MarkFlagRequired("file")
 
 	// add sub-commands
 	rootCmd.AddCommand(
 		man.NewManCmd(),
 		version.Command(),
+		validateCmd,
+		fromGoModCmd,
+		fromWorkflowsCmd,
+		fromImagesCmd,
+		fromPackagesCmd,
+		fromSBOMCmd,
+		fromTerraformCmd,
+		fromFlakeCmd,
+		fromToolsCmd,
+		scanCmd,
+		outdatedCmd,
+		serveCmd,
+		buildSiteCmd,
+		watchCmd,
+		digestCmd,
 	)
 }
+
+// addSubscribeFlags defines the flags shared by every command that subscribes
+// to release feeds: the category flag (-c), the JUnit report path and the
+// URL of a filtering feed proxy run with the serve command.
+func addSubscribeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("category", "c", "", "RSS feed category name for every feed, as [Category] headers of the input are not used here (optional)")
+	cmd.Flags().String("junit", "", "Write a JUnit XML report with one test case per input line to this path (optional)")
+	cmd.Flags().String("proxy-url", "", "Subscribe through the feed proxy of ghreleases2rss serve at this base URL, applying each line's filter options (optional)")
+}
+
+// subscribeCmdRun returns a cobra Run function that validates the Miniflux
+// configuration before handing over to a subscribing ghreleases2rss function.
+func subscribeCmdRun(run func(*cobra.Command, []string, config.Config)) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := config.ValidateRequired(conf); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		run(cmd, args, conf)
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// scanCmd subscribes to every repo referenced by the manifests in a project tree.
+//
+// It walks each directory and detects input lists, go.mod files, workflows,
+// Dockerfiles, Compose and Kubernetes manifests, package manifests, SBOMs,
+// Terraform and flake lock files and tool manifests by name and content.
+// Paths ignored by .gitignore files are skipped unless --no-gitignore is set,
+// and --include and --exclude globs narrow the walk further. The repos found
+// are deduplicated and subscribed like any other source, or with --print
+// written out as an input file whose comments record where each repo came
+// from. Miniflux configuration is not required with --print.
+var scanCmd = &cobra.Command{
+	Use:   "scan dir...",
+	Short: "Subscribe to releases of every repo referenced by the manifests in a project tree",
+	Long:  `Walk a project tree, detect every supported manifest and subscribe to the GitHub repo release feeds they reference`,
+	Args:  cobra.MinimumNArgs(1),
+	Run:   scanCmdRun,
+}
+
+// scanCmdRun only requires the Miniflux configuration when scan subscribes.
+func scanCmdRun(cmd *cobra.Command, args []string) {
+	if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+		ghreleases2rss.Scan(cmd, args, conf)
+		return
+	}
+	subscribeCmdRun(ghreleases2rss.Scan)(cmd, args)
+}
+
+func init() {
+	addSubscribeFlags(scanCmd)
+	scanCmd.Flags().StringArray("include", nil, "Only detect files matching this glob, relative to the scanned directory (repeatable)")
+	scanCmd.Flags().StringArray("exclude", nil, "Skip files and directories matching this glob (repeatable)")
+	scanCmd.Flags().Bool("no-gitignore", false, "Also scan paths ignored by .gitignore files")
+	scanCmd.Flags().Bool("print", false, "Write the repos found as an input file instead of subscribing")
+	scanCmd.Flags().StringP("output", "o", "", "File to write with --print (default stdout)")
+}
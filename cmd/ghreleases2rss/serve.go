@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+	"github.com/toozej/ghreleases2rss/internal/imagetags"
+	"github.com/toozej/ghreleases2rss/internal/server"
+)
+
+// serveCmd serves merged release feeds per category, a caching feed proxy,
+// security advisories and image tag feeds over HTTP. Its Long help lists the
+// routes and options.
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve merged release feeds per category and a caching feed proxy over HTTP",
+	Long: `Fetch release feeds of the repos in input files in the background, serve one
+merged Atom feed per category and proxy release and tag feeds through a cache
+and filters. Miniflux configuration is not required.
+
+Repos are grouped by the [Category] section headers of the input, with the
+rest in --default-category, and their release feeds are fetched every
+--interval, spread across --spread. The input is re-read on every refresh.
+
+Routes:
+  /feeds/{category}.atom                 merged releases of a category, with
+                                         entry titles prefixed by owner/repo
+  /proxy/{owner}/{repo}/releases.atom    cached release feed of a repo
+  /proxy/{owner}/{repo}/tags.atom        cached tag feed of a repo
+  /proxy/{owner}/{repo}/advisories.atom  GitHub security advisories of a repo
+  /advisories/{category}.atom            advisories of a category's repos
+  /image-tags/{image}.atom               newly seen tags of a container image
+
+Filter options after a repo on an input line, such as prefix=cli/,
+prerelease=false or level=minor, apply to that repo's entries. The same
+options given as query parameters filter any release, tag or image tag feed,
+e.g. /feeds/dev-tools.atom?level=major.
+
+Only the repos in the input are proxied unless --proxy-any is given, which
+subscribing commands pointed at the proxy with --proxy-url need for other
+repos.
+
+Feeds are cached in the cache directory: copies younger than --max-age are
+served as is, older ones are revalidated, and the cached copy is served when
+GitHub fails. At most --concurrency requests run at once per host.
+
+Every feed is also served as JSON Feed (.json), RSS (.rss) and iCalendar
+(.ics), or without an extension in the format the Accept header prefers.
+
+With --enrich, release entries get assets, authors, compare links and
+rendered notes from the GitHub API. With --classify, major version bumps get
+a [MAJOR] title prefix, notes announcing breaking changes [BREAKING] and notes
+naming a CVE or GHSA ID [SECURITY], along with the Atom categories major,
+breaking, deprecation, security and the advisory IDs.
+
+Advisories are read from GITHUB_API_URL with GITHUB_TOKEN and only served
+for the repos in the input, even with --proxy-any.
+
+Image tags are only served for the --image images on the --image-registry
+hosts. The first request for an image records its existing tags, and every
+tag pushed later becomes an entry with its digest and docker pull command.`,
+	Args: cobra.ExactArgs(0),
+	Run:  serveCmdRun,
+}
+
+// serveCmdRun passes the loaded configuration to the ghreleases2rss.Serve function.
+func serveCmdRun(cmd *cobra.Command, args []string) {
+	ghreleases2rss.Serve(cmd, args, conf)
+}
+
+func init() {
+	serveCmd.Flags().StringArrayP("file", "f", nil, "Input file, glob pattern, - for stdin, HTTPS URL or git+https://host/org/repo.git//path@ref source with GitHub repo URLs or names under [Category] headers; repeatable (optional, only the proxy is served without it, which needs --proxy-any)")
+	serveCmd.Flags().String("listen", ":8080", "Address to serve feeds on")
+	serveCmd.Flags().Duration("interval", server.DefaultInterval, "Time between release feed refreshes")
+	serveCmd.Flags().Duration("spread", 15*time.Minute, "Time window each refresh spreads its upstream requests across, capped at --interval")
+	serveCmd.Flags().Duration("max-age", cache.DefaultMaxAge, "How long a cached feed is served before it is revalidated upstream")
+	serveCmd.Flags().String("default-category", "Releases", "Category of repos listed outside a [Category] section")
+	serveCmd.Flags().Int("limit", 100, "Maximum number of entries per category feed, 0 for no limit")
+	serveCmd.Flags().Bool("enrich", false, "Add assets, authors, compare links and rendered notes from the GitHub API to release entries")
+	serveCmd.Flags().Bool("proxy-any", false, "Proxy the feeds of any repo rather than only the repos in the input")
+	serveCmd.Flags().Bool("classify", false, "Mark major, breaking, deprecation and security releases with title prefixes such as [MAJOR] and Atom categories")
+	serveCmd.Flags().StringArray("image", nil, "Container image, such as nginx or ghcr.io/owner/image, whose new tags are served at /image-tags/{image}; repeatable")
+	serveCmd.Flags().StringArray("image-registry", imagetags.DefaultRegistries, "Registry host whose image tags are served at /image-tags/{image}; repeatable")
+	serveCmd.Flags().Int("concurrency", cache.DefaultPerHost, "Number of feeds fetched at once per upstream host")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+	"github.com/toozej/ghreleases2rss/internal/site"
+)
+
+// buildSiteCmd writes a static release dashboard.
+//
+// The repos listed in the input sources are grouped into categories by the
+// [Category] section headers of the input, as for serve, and their release
+// feeds are fetched once through the feed cache. The output directory then
+// receives an index.html of the latest --limit releases across all repos, a
+// page per category under categories/, a release history page per repo under
+// repos/{owner}/{repo}.html, the merged feeds of the index and of each
+// category in Atom, JSON Feed, RSS and iCalendar, and a subscriptions.opml
+// file listing each repo's release feed by category. Filter options after a
+// repo apply to its releases, and with --proxy-url the OPML file points
+// filtered repos at the proxy. Pages link to each other relatively, so the
+// directory can be published on any static host; --base-url adds self links
+// to the feeds and lists them in the OPML file. With --enrich, release
+// entries get assets, authors and rendered notes from the GitHub API as in
+// serve. Miniflux configuration is not required.
+var buildSiteCmd = &cobra.Command{
+	Use:   "build-site",
+	Short: "Write a static HTML release dashboard with merged feeds and OPML",
+	Long:  `Fetch release feeds of the repos in input files and write static HTML pages of the latest releases, each category and each repo's history, along with merged feeds and an OPML file`,
+	Args:  cobra.ExactArgs(0),
+	Run:   buildSiteCmdRun,
+}
+
+// buildSiteCmdRun passes the loaded configuration to the ghreleases2rss.BuildSite function.
+func buildSiteCmdRun(cmd *cobra.Command, args []string) {
+	ghreleases2rss.BuildSite(cmd, args, conf)
+}
+
+func init() {
+	buildSiteCmd.Flags().StringArrayP("file", "f", nil, "Input file, glob pattern, - for stdin, HTTPS URL or git+https://host/org/repo.git//path@ref source with GitHub repo URLs or names under [Category] headers; repeatable (required)")
+	buildSiteCmd.Flags().StringP("output", "o", "public", "Directory to write the site to")
+	buildSiteCmd.Flags().String("title", site.DefaultTitle, "Site title")
+	buildSiteCmd.Flags().String("base-url", "", "URL the site is published at, for feed self links and the OPML file (optional)")
+	buildSiteCmd.Flags().String("proxy-url", "", "List repos with filter options in the OPML file through the feed proxy of ghreleases2rss serve at this base URL (optional)")
+	buildSiteCmd.Flags().String("default-category", "Releases", "Category of repos listed outside a [Category] section")
+	buildSiteCmd.Flags().Int("limit", site.DefaultLimit, "Number of releases on the index and in its feed")
+	buildSiteCmd.Flags().Bool("enrich", false, "Add assets, authors, compare links and rendered notes from the GitHub API to release entries")
+	buildSiteCmd.Flags().Duration("max-age", cache.DefaultMaxAge, "How long a cached feed is used before it is revalidated upstream")
+	buildSiteCmd.Flags().Int("concurrency", cache.DefaultPerHost, "Number of feeds fetched at once per upstream host")
+	_ = buildSiteCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+)
+
+// validateCmd checks an input file without subscribing to any feeds.
+//
+// Every non-empty line must parse as a GitHub repository whose release feed
+// exists. The command exits non-zero when any line fails, which makes it
+// suitable for running in CI whenever the input file changes. Miniflux
+// configuration is not required.
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate an input file of GitHub repos without subscribing",
+	Long:  `Check that every line of an input file is a GitHub repo with a release feed`,
+	Args:  cobra.ExactArgs(0),
+	Run:   validateCmdRun,
+}
+
+// validateCmdRun passes the loaded configuration to the ghreleases2rss.Validate function.
+func validateCmdRun(cmd *cobra.Command, args []string) {
+	ghreleases2rss.Validate(cmd, args, conf)
+}
+
+func init() {
+	validateCmd.Flags().StringArrayP("file", "f", nil, "Input file, glob pattern, - for stdin, HTTPS URL or git+https://host/org/repo.git//path@ref source with GitHub repo URLs or names; repeatable (required)")
+	validateCmd.Flags().String("junit", "", "Write a JUnit XML report with one test case per input line to this path (optional)")
+	_ = validateCmd.MarkFlagRequired("file")
+}
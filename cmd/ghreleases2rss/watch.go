@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/ghreleases2rss"
+	"github.com/toozej/ghreleases2rss/internal/notify"
+	"github.com/toozej/ghreleases2rss/internal/watch"
+)
+
+// watchCmd sends new releases to webhooks, ntfy and email.
+//
+// The release feeds of the repos listed in the input sources are polled
+// every --interval through the feed cache, and the newest release seen per
+// repo is kept in the --state file. Releases published since the previous
+// poll are sent, oldest first, to every configured sink:
+//
+//   - --webhook-url receives a POST with a JSON body rendered from the Go
+//     template in --webhook-template, which is executed with the release
+//     (.Repo, .Tag, .Title, .URL, .Published, .Author, .Prerelease, .Notes
+//     and .Summary) and can encode values with the json function. Without a
+//     template the release itself is posted as JSON. WEBHOOK_TOKEN is sent
+//     as a bearer token.
+//   - --ntfy-url is an ntfy topic URL that receives the release notes as the
+//     message, with the repo and tag as title and the release URL as click
+//     action. NTFY_TOKEN is sent as a bearer token.
+//   - --smtp-addr is an SMTP server that emails the release to the
+//     --smtp-to recipients from --smtp-from, using STARTTLS when offered and
+//     SMTP_USERNAME and SMTP_PASSWORD when set.
+//
+// Failed deliveries are attempted --retries times with exponential backoff
+// starting at --retry-backoff; rejected requests are not retried. A repo is
+// only marked as seen once every sink received its releases, so deliveries
+// that still fail are repeated on the next poll. Repos seen for the first
+// time are recorded without notifying, and filter options after a repo on an
+// input line apply to its releases. Miniflux configuration is not required.
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Send new releases to webhooks, ntfy and email",
+	Long:  `Poll release feeds of the repos in input files and send releases published since the last poll to a JSON webhook, an ntfy topic and email recipients`,
+	Args:  cobra.ExactArgs(0),
+	Run:   watchCmdRun,
+}
+
+// watchCmdRun passes the loaded configuration to the ghreleases2rss.Watch function.
+func watchCmdRun(cmd *cobra.Command, args []string) {
+	ghreleases2rss.Watch(cmd, args, conf)
+}
+
+func init() {
+	watchCmd.Flags().StringArrayP("file", "f", nil, "Input file, glob pattern, - for stdin, HTTPS URL or git+https://host/org/repo.git//path@ref source with GitHub repo URLs or names; repeatable (required)")
+	watchCmd.Flags().Duration("interval", watch.DefaultInterval, "Time between polls")
+	watchCmd.Flags().String("state", "", "File the newest release seen per repo is kept in (default: watch-state.json in the cache directory)")
+	watchCmd.Flags().Bool("once", false, "Poll once and exit, e.g. when run from cron")
+	watchCmd.Flags().String("webhook-url", "", "URL to POST new releases to as JSON (optional)")
+	watchCmd.Flags().String("webhook-template", "", "Go template file rendering the webhook body from the release (optional)")
+	watchCmd.Flags().String("ntfy-url", "", "ntfy topic URL to publish new releases to, e.g. https://ntfy.sh/releases (optional)")
+	watchCmd.Flags().Int("ntfy-priority", 0, "ntfy message priority from 1 to 5, 0 for the server default")
+	watchCmd.Flags().String("smtp-addr", "", "SMTP server host:port to email new releases through (optional)")
+	watchCmd.Flags().String("smtp-from", "", "Sender address of release emails")
+	watchCmd.Flags().StringArray("smtp-to", nil, "Recipient address of release emails; repeatable")
+	watchCmd.Flags().Int("retries", notify.DefaultAttempts, "Number of attempts per delivery")
+	watchCmd.Flags().Duration("retry-backoff", notify.DefaultBackoff, "Wait before retrying a failed delivery, doubling after every attempt")
+	watchCmd.Flags().String("default-category", "Releases", "Category of repos listed outside a [Category] section")
+	watchCmd.Flags().Bool("enrich", false, "Add assets, authors, compare links and rendered notes from the GitHub API to release entries")
+	watchCmd.Flags().Duration("max-age", cache.DefaultMaxAge, "How long a cached feed is used before it is revalidated upstream")
+	watchCmd.Flags().Int("concurrency", cache.DefaultPerHost, "Number of feeds fetched at once per upstream host")
+	_ = watchCmd.MarkFlagRequired("file")
+}
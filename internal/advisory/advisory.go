@@ -0,0 +1,111 @@
+// Package advisory turns the GitHub security advisories of a repo into a
+// feed, with the severity, affected versions and patched versions of each
+// advisory above its description.
+package advisory
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/toozej/ghreleases2rss/internal/classify"
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Feed returns the feed of the advisories of repo, newest first as given.
+func Feed(repo string, advisories []github.Advisory) *feed.Feed {
+	link := "https://github.com/" + repo + "/security/advisories"
+	f := &feed.Feed{ID: link, Title: "Security advisories of " + repo, Link: link}
+	for _, a := range advisories {
+		f.Entries = append(f.Entries, Entry(a))
+	}
+	for _, entry := range f.Entries {
+		if entry.Updated.After(f.Updated) {
+			f.Updated = entry.Updated
+		}
+	}
+	return f
+}
+
+// Entry returns the feed entry of a, titled with its severity and GHSA ID,
+// such as "[HIGH] GHSA-jfh8-c2jp-5v3q: Path traversal in archive
+// extraction", with the security label, its severity and its advisory IDs
+// as categories.
+func Entry(a github.Advisory) feed.Entry {
+	updated := a.UpdatedAt
+	if updated.IsZero() {
+		updated = a.PublishedAt
+	}
+	entry := feed.Entry{
+		ID:         a.HTMLURL,
+		Title:      a.GHSAID + ": " + a.Summary,
+		Link:       a.HTMLURL,
+		Updated:    updated,
+		Content:    content(a),
+		Categories: []string{classify.LabelSecurity},
+	}
+	if entry.ID == "" {
+		entry.ID = "urn:ghsa:" + a.GHSAID
+	}
+	if a.Severity != "" {
+		entry.Title = "[" + strings.ToUpper(a.Severity) + "] " + entry.Title
+		entry.Categories = append(entry.Categories, strings.ToLower(a.Severity))
+	}
+	for _, id := range []string{a.GHSAID, a.CVEID} {
+		if id != "" {
+			entry.Categories = append(entry.Categories, id)
+		}
+	}
+	return entry
+}
+
+// content returns the HTML notes of a: its severity, IDs and affected
+// packages, followed by its description rendered from Markdown.
+func content(a github.Advisory) string {
+	var b strings.Builder
+	b.WriteString("<p>")
+	if a.Severity != "" {
+		fmt.Fprintf(&b, "<strong>Severity:</strong> %s", html.EscapeString(a.Severity))
+		if a.CVSS.Score > 0 {
+			fmt.Fprintf(&b, " (CVSS %s)", strconv.FormatFloat(a.CVSS.Score, 'f', 1, 64))
+		}
+		b.WriteString("<br>")
+	}
+	fmt.Fprintf(&b, "<strong>GHSA:</strong> %s", html.EscapeString(a.GHSAID))
+	if a.CVEID != "" {
+		fmt.Fprintf(&b, "<br><strong>CVE:</strong> %s", html.EscapeString(a.CVEID))
+	}
+	b.WriteString("</p>")
+
+	if len(a.Vulnerabilities) > 0 {
+		b.WriteString("<table><thead><tr><th>Package</th><th>Affected versions</th><th>Patched versions</th></tr></thead><tbody>")
+		for _, v := range a.Vulnerabilities {
+			name := v.Package.Name
+			if v.Package.Ecosystem != "" {
+				name += " (" + v.Package.Ecosystem + ")"
+			}
+			patched := v.PatchedVersions
+			if patched == "" {
+				patched = "none"
+			}
+			fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>", html.EscapeString(name), html.EscapeString(v.VulnerableVersionRange), html.EscapeString(patched))
+		}
+		b.WriteString("</tbody></table>")
+	}
+
+	if a.Description != "" {
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(a.Description), &buf); err == nil {
+			b.Write(buf.Bytes())
+		}
+	}
+	return b.String()
+}
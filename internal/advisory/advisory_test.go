@@ -0,0 +1,71 @@
+package advisory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+func TestFeed(t *testing.T) {
+	high := github.Advisory{
+		GHSAID:      "GHSA-jfh8-c2jp-5v3q",
+		CVEID:       "CVE-2026-1234",
+		HTMLURL:     "https://github.com/owner/repo/security/advisories/GHSA-jfh8-c2jp-5v3q",
+		Summary:     "Path traversal in archive extraction",
+		Description: "Extracting a crafted archive writes outside the target.\n\n<script>alert(1)</script>",
+		Severity:    "high",
+		PublishedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+	high.CVSS.Score = 8.1
+	high.Vulnerabilities = make([]github.Vulnerability, 2)
+	high.Vulnerabilities[0].Package.Ecosystem = "go"
+	high.Vulnerabilities[0].Package.Name = "example.com/repo"
+	high.Vulnerabilities[0].VulnerableVersionRange = ">= 1.2.0, < 1.4.2"
+	high.Vulnerabilities[0].PatchedVersions = "1.4.2"
+	high.Vulnerabilities[1].Package.Name = "example.com/repo/v2"
+	high.Vulnerabilities[1].VulnerableVersionRange = "<= 2.0.0"
+	low := github.Advisory{
+		GHSAID:      "GHSA-2345-6789-cfgh",
+		Summary:     "Verbose errors",
+		PublishedAt: time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	f := Feed("owner/repo", []github.Advisory{high, low})
+	if f.Title != "Security advisories of owner/repo" || f.Link != "https://github.com/owner/repo/security/advisories" || !f.Updated.Equal(high.UpdatedAt) {
+		t.Errorf("Feed() = %q %q %v", f.Title, f.Link, f.Updated)
+	}
+	if len(f.Entries) != 2 {
+		t.Fatalf("Feed() has %d entries, want 2", len(f.Entries))
+	}
+
+	first := f.Entries[0]
+	if first.Title != "[HIGH] GHSA-jfh8-c2jp-5v3q: Path traversal in archive extraction" || first.Link != high.HTMLURL || !first.Updated.Equal(high.UpdatedAt) {
+		t.Errorf("Entries[0] = %q %q %v", first.Title, first.Link, first.Updated)
+	}
+	if want := []string{"security", "high", "GHSA-jfh8-c2jp-5v3q", "CVE-2026-1234"}; !reflect.DeepEqual(first.Categories, want) {
+		t.Errorf("Entries[0].Categories = %v, want %v", first.Categories, want)
+	}
+	for _, want := range []string{
+		"<strong>Severity:</strong> high (CVSS 8.1)",
+		"<strong>CVE:</strong> CVE-2026-1234",
+		"<tr><td>example.com/repo (go)</td><td>&gt;= 1.2.0, &lt; 1.4.2</td><td>1.4.2</td></tr>",
+		"<tr><td>example.com/repo/v2</td><td>&lt;= 2.0.0</td><td>none</td></tr>",
+		"<p>Extracting a crafted archive writes outside the target.</p>",
+	} {
+		if !strings.Contains(first.Content, want) {
+			t.Errorf("Entries[0].Content does not contain %q:\n%s", want, first.Content)
+		}
+	}
+	if strings.Contains(first.Content, "<script>") {
+		t.Errorf("Entries[0].Content contains raw HTML from the description:\n%s", first.Content)
+	}
+
+	second := f.Entries[1]
+	if second.Title != "GHSA-2345-6789-cfgh: Verbose errors" || second.ID != "urn:ghsa:GHSA-2345-6789-cfgh" || !second.Updated.Equal(low.PublishedAt) {
+		t.Errorf("Entries[1] = %+v", second)
+	}
+}
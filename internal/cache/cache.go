@@ -0,0 +1,346 @@
+// Package cache fetches feeds through an on-disk cache, revalidating them
+// upstream with ETag and If-Modified-Since, limiting concurrent requests per
+// host and falling back to cached copies when upstream fails.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Defaults used for zero Cache fields.
+const (
+	DefaultMaxAge  = 15 * time.Minute
+	DefaultPerHost = 4
+)
+
+// maxBodySize limits how much of a feed is read.
+const maxBodySize = 8 << 20
+
+// defaultBackoff is how long a host is left alone after throttling without
+// a Retry-After header.
+const defaultBackoff = 5 * time.Minute
+
+// ErrNotFound is returned by Get when upstream reports the feed does not exist.
+var ErrNotFound = errors.New("feed not found")
+
+// Response is a fetched or cached feed.
+type Response struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+	Body         []byte    `json:"-"`
+	// Stale is set when upstream could not be reached or refused the
+	// request and the cached copy was returned instead.
+	Stale bool `json:"-"`
+}
+
+// Cache fetches feeds, or any other documents revalidated the same way such
+// as API responses, and keeps the last good copy of each in memory and,
+// when Dir is set, on disk so it survives restarts.
+type Cache struct {
+	// Dir stores cached feeds. Feeds are only cached in memory when it is empty.
+	Dir string
+	// Client fetches feeds. http.DefaultClient is used when nil.
+	Client *http.Client
+	// Header is sent with every upstream request, e.g. to authenticate.
+	Header http.Header
+	// MaxAge is how long a cached copy is served without asking upstream,
+	// DefaultMaxAge when zero.
+	MaxAge time.Duration
+	// PerHost limits the number of concurrent requests to each upstream
+	// host, DefaultPerHost when zero.
+	PerHost int
+
+	mu      sync.Mutex
+	entries map[string]*Response
+	hosts   map[string]*host
+}
+
+// host tracks the requests in flight to an upstream host and how long it
+// asked to be left alone.
+type host struct {
+	slots        chan struct{}
+	backoffUntil time.Time
+}
+
+// Get returns the feed at feedURL. A cached copy younger than MaxAge is
+// returned as is; older copies are revalidated upstream. When upstream fails,
+// throttles or the host is backing off, the cached copy is returned with
+// Stale set, and an error only when there is none.
+func (c *Cache) Get(ctx context.Context, feedURL string) (*Response, error) {
+	cached, _ := c.Cached(feedURL)
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	if cached != nil && time.Since(cached.Fetched) < maxAge {
+		return cached, nil
+	}
+
+	resp, err := c.fetch(ctx, feedURL, cached)
+	if err == nil {
+		return resp, nil
+	}
+	if cached == nil || errors.Is(err, ErrNotFound) || ctx.Err() != nil {
+		return nil, err
+	}
+	log.Warnf("Serving cached copy of %s: %v", feedURL, err)
+	stale := *cached
+	stale.Stale = true
+	return &stale, nil
+}
+
+// Cached returns the cached copy of feedURL without contacting upstream.
+func (c *Cache) Cached(feedURL string) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*Response)
+	}
+	if resp, ok := c.entries[feedURL]; ok {
+		return resp, true
+	}
+	resp, err := c.load(feedURL)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warnf("Unable to read cached copy of %s: %v", feedURL, err)
+		}
+		return nil, false
+	}
+	c.entries[feedURL] = resp
+	return resp, true
+}
+
+// fetch requests feedURL upstream, revalidating cached when it is set.
+func (c *Cache) fetch(ctx context.Context, feedURL string, cached *Response) (*Response, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	h := c.host(u.Host)
+	if until := c.backoff(h); !until.IsZero() {
+		return nil, fmt.Errorf("%s is throttling requests until %s", u.Host, until.Format(time.RFC3339))
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) // #nosec G704 -- feed URLs are built from the configured upstream
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		log.Debugf("Feed %s not modified", feedURL)
+		fresh := *cached
+		fresh.Fetched = time.Now()
+		c.store(&fresh, false)
+		return &fresh, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"):
+		until := c.throttle(h, retryAfter(resp.Header))
+		return nil, fmt.Errorf("%s throttled requests with status code %d, backing off until %s", u.Host, resp.StatusCode, until.Format(time.RFC3339))
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch feed %s, status code: %d", feedURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	fresh := &Response{
+		URL:          feedURL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fetched:      time.Now(),
+		Body:         body,
+	}
+	log.Debugf("Fetched feed %s", feedURL)
+	c.store(fresh, true)
+	return fresh, nil
+}
+
+// host returns the state of the upstream host name.
+func (c *Cache) host(name string) *host {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hosts == nil {
+		c.hosts = make(map[string]*host)
+	}
+	h, ok := c.hosts[name]
+	if !ok {
+		perHost := c.PerHost
+		if perHost <= 0 {
+			perHost = DefaultPerHost
+		}
+		h = &host{slots: make(chan struct{}, perHost)}
+		c.hosts[name] = h
+	}
+	return h
+}
+
+// backoff returns when h may be contacted again, or the zero time if it may
+// be contacted now.
+func (c *Cache) backoff(h *host) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(h.backoffUntil) {
+		return h.backoffUntil
+	}
+	return time.Time{}
+}
+
+// retryAfter returns the Retry-After header, or for the GitHub API, whose
+// primary rate limit sends the reset time instead, the seconds until
+// X-RateLimit-Reset.
+func retryAfter(header http.Header) string {
+	if value := header.Get("Retry-After"); value != "" {
+		return value
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		return strconv.FormatInt(max(reset-time.Now().Unix(), 0), 10)
+	}
+	return ""
+}
+
+// throttle makes h back off for the time given in a Retry-After header, in
+// seconds or as an HTTP date, or defaultBackoff without one.
+func (c *Cache) throttle(h *host, retryAfter string) time.Time {
+	until := time.Now().Add(defaultBackoff)
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		until = time.Now().Add(time.Duration(seconds) * time.Second)
+	} else if date, err := http.ParseTime(retryAfter); err == nil {
+		until = date
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until.After(h.backoffUntil) {
+		h.backoffUntil = until
+	}
+	return h.backoffUntil
+}
+
+// store keeps resp in memory and on disk. The body is only rewritten when
+// it changed.
+func (c *Cache) store(resp *Response, withBody bool) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]*Response)
+	}
+	c.entries[resp.URL] = resp
+	c.mu.Unlock()
+
+	if c.Dir == "" {
+		return
+	}
+	if err := c.save(resp, withBody); err != nil {
+		log.Warnf("Unable to cache feed %s: %v", resp.URL, err)
+	}
+}
+
+// paths returns where the body and metadata of feedURL are cached.
+func (c *Cache) paths(feedURL string) (string, string) {
+	sum := sha256.Sum256([]byte(feedURL))
+	key := hex.EncodeToString(sum[:])
+	dir := filepath.Join(c.Dir, "feeds")
+	return filepath.Join(dir, key+".xml"), filepath.Join(dir, key+".json")
+}
+
+func (c *Cache) load(feedURL string) (*Response, error) {
+	if c.Dir == "" {
+		return nil, os.ErrNotExist
+	}
+	bodyPath, metaPath := c.paths(feedURL)
+	meta, err := os.ReadFile(metaPath) // #nosec G304 -- path is derived from a hash inside Dir
+	if err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err := json.Unmarshal(meta, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Body, err = os.ReadFile(bodyPath); err != nil { // #nosec G304 -- path is derived from a hash inside Dir
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Cache) save(resp *Response, withBody bool) error {
+	bodyPath, metaPath := c.paths(resp.URL)
+	if err := os.MkdirAll(filepath.Dir(bodyPath), 0700); err != nil {
+		return err
+	}
+	if withBody {
+		if err := writeFile(bodyPath, resp.Body); err != nil {
+			return err
+		}
+	}
+	meta, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return writeFile(metaPath, meta)
+}
+
+// writeFile replaces path with data through a temporary file, so readers
+// never see a partly written file.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// upstream is a feed server stand-in that counts requests and can be told
+// to fail.
+type upstream struct {
+	*httptest.Server
+	requests    atomic.Int32
+	conditional atomic.Int32
+	status      atomic.Int32
+	body        atomic.Value
+}
+
+func newUpstream(t *testing.T) *upstream {
+	t.Helper()
+	u := &upstream{}
+	u.body.Store("<feed>v1</feed>")
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.requests.Add(1)
+		if r.URL.Path == "/owner/missing/releases.atom" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if status := int(u.status.Load()); status != 0 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(status)
+			return
+		}
+		body := u.body.Load().(string)
+		etag := fmt.Sprintf(`"%d"`, len(body))
+		if r.Header.Get("If-None-Match") == etag {
+			u.conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func TestGet(t *testing.T) {
+	u := newUpstream(t)
+	dir := t.TempDir()
+	c := &Cache{Dir: dir, MaxAge: time.Hour}
+	feedURL := u.URL + "/owner/repo/releases.atom"
+
+	resp, err := c.Get(context.Background(), feedURL)
+	if err != nil || string(resp.Body) != "<feed>v1</feed>" {
+		t.Fatalf("Get() = %v, %v", resp, err)
+	}
+
+	// Copies younger than MaxAge are served without asking upstream
+	if _, err := c.Get(context.Background(), feedURL); err != nil || u.requests.Load() != 1 {
+		t.Errorf("Get() of fresh copy made %d requests, err %v", u.requests.Load(), err)
+	}
+
+	// Older copies are revalidated with their ETag
+	c.MaxAge = time.Nanosecond
+	resp, err = c.Get(context.Background(), feedURL)
+	if err != nil || u.conditional.Load() != 1 || string(resp.Body) != "<feed>v1</feed>" || resp.Stale {
+		t.Errorf("Get() revalidation = %+v, %v, %d conditional requests", resp, err, u.conditional.Load())
+	}
+
+	u.body.Store("<feed>v2 changed</feed>")
+	if resp, _ := c.Get(context.Background(), feedURL); string(resp.Body) != "<feed>v2 changed</feed>" {
+		t.Errorf("Get() after change = %q", resp.Body)
+	}
+
+	// The cache survives a restart
+	restarted := &Cache{Dir: dir}
+	if resp, ok := restarted.Cached(feedURL); !ok || string(resp.Body) != "<feed>v2 changed</feed>" || resp.ETag == "" {
+		t.Errorf("Cached() after restart = %+v, %v", resp, ok)
+	}
+
+	if _, err := c.Get(context.Background(), u.URL+"/owner/missing/releases.atom"); err == nil {
+		t.Error("Get() should fail without a cached copy")
+	}
+}
+
+func TestGetStale(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantRequests int32
+	}{
+		{name: "Server error", status: http.StatusBadGateway, wantRequests: 3},
+		{name: "Throttled", status: http.StatusTooManyRequests, wantRequests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUpstream(t)
+			c := &Cache{MaxAge: time.Nanosecond}
+			feedURL := u.URL + "/owner/repo/releases.atom"
+			if _, err := c.Get(context.Background(), feedURL); err != nil {
+				t.Fatal(err)
+			}
+
+			u.status.Store(int32(tt.status))
+			for range 2 {
+				resp, err := c.Get(context.Background(), feedURL)
+				if err != nil || !resp.Stale || string(resp.Body) != "<feed>v1</feed>" {
+					t.Errorf("Get() = %+v, %v, want stale copy", resp, err)
+				}
+			}
+			// A throttled host is left alone until its Retry-After has passed
+			if got := u.requests.Load(); got != tt.wantRequests {
+				t.Errorf("upstream got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestPerHost(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, "<feed/>")
+	}))
+	defer server.Close()
+
+	c := &Cache{PerHost: 2}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Get(context.Background(), fmt.Sprintf("%s/owner/repo%d/releases.atom", server.URL, i)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := maxInFlight.Load(); got > 2 {
+		t.Errorf("%d requests ran at once, want at most 2", got)
+	}
+}
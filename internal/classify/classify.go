@@ -0,0 +1,175 @@
+// Package classify labels releases that need attention: major version bumps
+// and release notes announcing breaking changes, deprecations or security
+// fixes, including the CVE and GHSA advisory IDs they mention.
+package classify
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/filter"
+)
+
+// Labels of a release, also used as the terms of the Atom categories Apply
+// adds.
+const (
+	LabelMajor       = "major"
+	LabelBreaking    = "breaking"
+	LabelDeprecation = "deprecation"
+	LabelSecurity    = "security"
+)
+
+// Labels lists the labels in the order they are reported.
+var Labels = []string{LabelMajor, LabelBreaking, LabelDeprecation, LabelSecurity}
+
+// Markers prefix the titles of labeled entries in Apply. Deprecations only
+// get a category, as they are announced far more often than they matter, and
+// the security marker is only added for releases naming an advisory ID, as
+// the word alone appears in many notes that fix nothing.
+var Markers = map[string]string{
+	LabelMajor:    "[MAJOR] ",
+	LabelBreaking: "[BREAKING] ",
+	LabelSecurity: "[SECURITY] ",
+}
+
+// maxMentions limits how many lines of the notes are kept per release.
+const maxMentions = 5
+
+var (
+	// breaking matches notes announcing breaking changes, such as
+	// "BREAKING CHANGE:" in conventional commits or a "Breaking changes"
+	// heading.
+	breaking    = regexp.MustCompile(`BREAKING|(?i:\bbreaking changes?\b)`)
+	deprecation = regexp.MustCompile(`(?i)\bdeprecat(?:e|ed|es|ing|ion|ions)\b`)
+	security    = regexp.MustCompile(`(?i)\bsecurity\b`)
+	// advisory matches CVE IDs and GitHub security advisory IDs.
+	advisory = regexp.MustCompile(`(?i)\b(?:CVE-\d{4}-\d{4,}|GHSA(?:-[23456789cfghjmpqrvwx]{4}){3})\b`)
+	// listMarker matches the Markdown list, heading or quote marker of a
+	// line of notes.
+	listMarker = regexp.MustCompile(`^(?:[-*+]|\d+\.|#+|>)\s+`)
+)
+
+// Result is the classification of a release.
+type Result struct {
+	// Labels are the labels of the release, in the order of Labels.
+	Labels []string
+	// Advisories are the CVE and GHSA IDs mentioned in the title or notes,
+	// in the order they are mentioned, such as CVE-2026-1234 or
+	// GHSA-jfh8-c2jp-5v3q.
+	Advisories []string
+	// Mentions are the lines of the notes that mention breaking changes,
+	// deprecations, security or advisories, without list markers.
+	Mentions []string
+}
+
+// Has reports whether r has label.
+func (r Result) Has(label string) bool {
+	return slices.Contains(r.Labels, label)
+}
+
+// Classify classifies entries[i], where entries are a repo's release feed,
+// newest first. The release is a major version bump when its major version
+// is above that of the newest older stable release whose tag has the same
+// prefix up to the last slash, so releases of one component of a monorepo
+// are compared with each other. Prereleases are skipped as previous
+// releases, so both v2.0.0-rc.1 and v2.0.0 are major bumps over v1.9.0.
+func Classify(entries []feed.Entry, i int) Result {
+	entry := entries[i]
+	notes := feed.Markdown(entry.Content)
+	text := entry.Title + "\n" + notes
+
+	var r Result
+	for _, id := range advisory.FindAllString(text, -1) {
+		id = normalizeAdvisory(id)
+		if !slices.Contains(r.Advisories, id) {
+			r.Advisories = append(r.Advisories, id)
+		}
+	}
+	if version, err := feed.ParseVersion(entry.Tag()); err == nil {
+		if previous := previousVersion(entries, i); previous != nil && version.Major() > previous.Major() {
+			r.Labels = append(r.Labels, LabelMajor)
+		}
+	}
+	if breaking.MatchString(text) {
+		r.Labels = append(r.Labels, LabelBreaking)
+	}
+	if deprecation.MatchString(text) {
+		r.Labels = append(r.Labels, LabelDeprecation)
+	}
+	if security.MatchString(text) || len(r.Advisories) > 0 {
+		r.Labels = append(r.Labels, LabelSecurity)
+	}
+
+	for line := range strings.Lines(notes) {
+		line = strings.TrimSpace(line)
+		if len(r.Mentions) >= maxMentions {
+			break
+		}
+		for _, pattern := range []*regexp.Regexp{breaking, deprecation, security, advisory} {
+			if pattern.MatchString(line) {
+				r.Mentions = append(r.Mentions, listMarker.ReplaceAllString(line, ""))
+				break
+			}
+		}
+	}
+	return r
+}
+
+// Apply returns a copy of entries, a repo's release feed newest first, in
+// which every labeled entry has the Markers of its labels prefixed to its
+// title, such as "[MAJOR] [SECURITY] v2.0.0", and its labels and advisory
+// IDs added as categories, so readers can filter on them. Entries labeled
+// security without an advisory ID only get the category.
+func Apply(entries []feed.Entry) []feed.Entry {
+	classified := make([]feed.Entry, len(entries))
+	for i, entry := range entries {
+		r := Classify(entries, i)
+		entry.Categories = append([]string(nil), entry.Categories...)
+		var prefix string
+		for _, label := range r.Labels {
+			if label != LabelSecurity || len(r.Advisories) > 0 {
+				prefix += Markers[label]
+			}
+			entry.Categories = append(entry.Categories, label)
+		}
+		entry.Categories = append(entry.Categories, r.Advisories...)
+		entry.Title = prefix + entry.Title
+		classified[i] = entry
+	}
+	return classified
+}
+
+// previousVersion returns the version of the newest stable release older
+// than entries[i] with the same tag prefix, or nil.
+func previousVersion(entries []feed.Entry, i int) *semver.Version {
+	prefix := tagPrefix(entries[i].Tag())
+	for _, entry := range entries[i+1:] {
+		tag := entry.Tag()
+		if tagPrefix(tag) != prefix {
+			continue
+		}
+		version, err := feed.ParseVersion(tag)
+		if err != nil || slices.Contains(entry.Categories, feed.CategoryPrerelease) || filter.IsPrerelease(tag, version) {
+			continue
+		}
+		return version
+	}
+	return nil
+}
+
+func tagPrefix(tag string) string {
+	return tag[:strings.LastIndex(tag, "/")+1]
+}
+
+// normalizeAdvisory returns id in its canonical case: CVE IDs upper case and
+// GHSA IDs with a lower case body, as GitHub writes them.
+func normalizeAdvisory(id string) string {
+	if strings.HasPrefix(strings.ToUpper(id), "GHSA-") {
+		return "GHSA-" + strings.ToLower(id[len("GHSA-"):])
+	}
+	return strings.ToUpper(id)
+}
@@ -0,0 +1,130 @@
+package classify
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+)
+
+// entry returns a release feed entry, escaping slashes in the tag link as
+// GitHub does.
+func entry(tag, notes string, categories ...string) feed.Entry {
+	return feed.Entry{
+		Title:      tag,
+		Link:       "https://github.com/owner/repo/releases/tag/" + url.PathEscape(tag),
+		Updated:    time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		Content:    notes,
+		Categories: categories,
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []feed.Entry
+		want    Result
+	}{
+		{
+			name:    "major bump",
+			entries: []feed.Entry{entry("v2.0.0", ""), entry("v1.9.0", "")},
+			want:    Result{Labels: []string{LabelMajor}},
+		},
+		{
+			name:    "minor bump",
+			entries: []feed.Entry{entry("v1.10.0", ""), entry("v1.9.0", "")},
+		},
+		{
+			name:    "first release",
+			entries: []feed.Entry{entry("v1.0.0", "")},
+		},
+		{
+			name:    "release candidate is compared with the previous stable release",
+			entries: []feed.Entry{entry("v2.0.0", ""), entry("v2.0.0-rc.1", ""), entry("v1.9.0", "")},
+			want:    Result{Labels: []string{LabelMajor}},
+		},
+		{
+			name:    "prerelease marked by the enricher is skipped",
+			entries: []feed.Entry{entry("v2.0.0", ""), entry("v2.0.0", "", feed.CategoryPrerelease), entry("v1.9.0", "")},
+			want:    Result{Labels: []string{LabelMajor}},
+		},
+		{
+			name:    "monorepo components are compared separately",
+			entries: []feed.Entry{entry("cli/v1.0.0", ""), entry("sdk/v3.0.0", ""), entry("cli/v0.9.0", "")},
+			want:    Result{Labels: []string{LabelMajor}},
+		},
+		{
+			name:    "non-semver tags",
+			entries: []feed.Entry{entry("release-2026-03-01", ""), entry("v1.0.0", "")},
+		},
+		{
+			name:    "breaking change",
+			entries: []feed.Entry{entry("v1.3.0", "<ul><li>feat!: new config format</li><li>BREAKING CHANGE: <code>--old</code> removed</li></ul>")},
+			want:    Result{Labels: []string{LabelBreaking}, Mentions: []string{"BREAKING CHANGE: `--old` removed"}},
+		},
+		{
+			name:    "breaking changes heading",
+			entries: []feed.Entry{entry("v1.3.0", "<h2>Breaking changes</h2><p>None really</p>")},
+			want:    Result{Labels: []string{LabelBreaking}, Mentions: []string{"Breaking changes"}},
+		},
+		{
+			name:    "deprecation",
+			entries: []feed.Entry{entry("v1.3.0", "<p>The v1 API is deprecated.</p>")},
+			want:    Result{Labels: []string{LabelDeprecation}, Mentions: []string{"The v1 API is deprecated."}},
+		},
+		{
+			name:    "advisories",
+			entries: []feed.Entry{entry("v1.2.1", "<ul><li>Fix cve-2026-12345 in the parser</li><li>Fix GHSA-JFH8-C2JP-5V3Q</li><li>Also fixes CVE-2026-12345</li></ul>")},
+			want: Result{
+				Labels:     []string{LabelSecurity},
+				Advisories: []string{"CVE-2026-12345", "GHSA-jfh8-c2jp-5v3q"},
+				Mentions:   []string{"Fix cve-2026-12345 in the parser", "Fix GHSA-JFH8-C2JP-5V3Q", "Also fixes CVE-2026-12345"},
+			},
+		},
+		{
+			name:    "security in the title",
+			entries: []feed.Entry{entry("Security release v1.2.2", "")},
+			want:    Result{Labels: []string{LabelSecurity}},
+		},
+		{
+			name:    "words containing keywords",
+			entries: []feed.Entry{entry("v1.2.2", "<p>Unbreaking the build, securityd renamed, GHSA-1234 is not an ID</p>")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.entries, 0); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Classify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	entries := []feed.Entry{
+		entry("v2.0.0", "<p>BREAKING: drops Go 1.25, fixes CVE-2026-0001</p>", feed.CategoryLatest),
+		entry("v1.9.0", "<p>Deprecates the v1 API</p>"),
+		entry("v1.8.1", "<p>Improves the security docs</p>"),
+		entry("v1.8.0", "<p>Notes</p>"),
+	}
+	got := Apply(entries)
+	want := []struct {
+		title      string
+		categories []string
+	}{
+		{"[MAJOR] [BREAKING] [SECURITY] v2.0.0", []string{feed.CategoryLatest, LabelMajor, LabelBreaking, LabelSecurity, "CVE-2026-0001"}},
+		{"v1.9.0", []string{LabelDeprecation}},
+		{"v1.8.1", []string{LabelSecurity}},
+		{"v1.8.0", nil},
+	}
+	for i, w := range want {
+		if got[i].Title != w.title || !reflect.DeepEqual(got[i].Categories, w.categories) {
+			t.Errorf("Apply()[%d] = %q %v, want %q %v", i, got[i].Title, got[i].Categories, w.title, w.categories)
+		}
+	}
+	if entries[0].Title != "v2.0.0" || len(entries[0].Categories) != 1 {
+		t.Errorf("Apply() modified its input: %+v", entries[0])
+	}
+}
@@ -0,0 +1,204 @@
+// Package digest summarizes the releases of tracked repos published in a
+// time window as a Markdown or HTML report grouped by repo, highlighting the
+// releases the classify package labels, such as major version bumps and
+// security fixes.
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/classify"
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/filter"
+)
+
+// DefaultTitle is the digest title for an empty Digest.Title.
+const DefaultTitle = "Release digest"
+
+// Output formats of Write.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+var (
+	markdownTemplate = template.Must(template.New("digest.md").Funcs(template.FuncMap{
+		"date": date,
+		"md":   markdownEscaper.Replace,
+		"join": strings.Join,
+	}).ParseFS(templates, "templates/digest.md"))
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(htmltemplate.FuncMap{
+		"date":    date,
+		"rfc3339": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+	}).ParseFS(templates, "templates/digest.html"))
+)
+
+// markdownEscaper escapes the characters that would turn release titles
+// into Markdown markup.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`)
+
+func date(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// Digest is the releases published between Since and Until.
+type Digest struct {
+	// Title names the digest, DefaultTitle when empty.
+	Title string
+	Since time.Time
+	Until time.Time
+	// Repos are the repos with releases in the window, in the order they
+	// were added.
+	Repos []Repo
+}
+
+// Repo is a repo and its releases in the window, newest first.
+type Repo struct {
+	Name     string
+	Releases []Release
+}
+
+// Release is a release in a digest.
+type Release struct {
+	Repo       string
+	Title      string
+	Link       string
+	Published  time.Time
+	Prerelease bool
+	// Labels are the reasons the release stands out, such as
+	// classify.LabelMajor, and Advisories the CVE and GHSA IDs its notes
+	// mention.
+	Labels     []string
+	Advisories []string
+	// Mentions are the lines of the notes that explain the labels.
+	Mentions []string
+}
+
+// Add adds the releases of repo published in the window. entries are the
+// repo's release feed, newest first, and releases before the window are
+// used to detect major version bumps. Repos without releases in the window
+// are left out.
+func (d *Digest) Add(repo string, entries []feed.Entry) {
+	var releases []Release
+	for i, entry := range entries {
+		if entry.Updated.Before(d.Since) || (!d.Until.IsZero() && entry.Updated.After(d.Until)) {
+			continue
+		}
+		releases = append(releases, newRelease(repo, entries, i))
+	}
+	if len(releases) > 0 {
+		d.Repos = append(d.Repos, Repo{Name: repo, Releases: releases})
+	}
+}
+
+// Count returns the number of releases in the digest.
+func (d *Digest) Count() int {
+	count := 0
+	for _, repo := range d.Repos {
+		count += len(repo.Releases)
+	}
+	return count
+}
+
+// Highlighted returns the releases with labels, in repo order.
+func (d *Digest) Highlighted() []Release {
+	var highlighted []Release
+	for _, repo := range d.Repos {
+		for _, release := range repo.Releases {
+			if len(release.Labels) > 0 {
+				highlighted = append(highlighted, release)
+			}
+		}
+	}
+	return highlighted
+}
+
+// Subject returns a one-line description of the digest for email subjects,
+// such as "Release digest: Infra, 12 releases since 2026-10-11".
+func (d *Digest) Subject() string {
+	return fmt.Sprintf("%s, %d releases since %s", d.title(), d.Count(), date(d.Since))
+}
+
+func (d *Digest) title() string {
+	if d.Title == "" {
+		return DefaultTitle
+	}
+	return d.Title
+}
+
+// Write writes the digest in format, FormatMarkdown or FormatHTML.
+func (d *Digest) Write(w io.Writer, format string) error {
+	data := struct {
+		*Digest
+		Title       string
+		Highlighted []Release
+	}{d, d.title(), d.Highlighted()}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatMarkdown:
+		err = markdownTemplate.Execute(&buf, data)
+	case FormatHTML:
+		err = htmlTemplate.Execute(&buf, data)
+	default:
+		return fmt.Errorf("unsupported digest format %q, expected %s or %s", format, FormatMarkdown, FormatHTML)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// newRelease returns the release of repo published as entries[i].
+func newRelease(repo string, entries []feed.Entry, i int) Release {
+	entry := entries[i]
+	version, _ := feed.ParseVersion(entry.Tag())
+	result := classify.Classify(entries, i)
+	return Release{
+		Repo:       repo,
+		Title:      entry.Title,
+		Link:       entry.Link,
+		Published:  entry.Updated,
+		Prerelease: slices.Contains(entry.Categories, feed.CategoryPrerelease) || filter.IsPrerelease(entry.Tag(), version),
+		Labels:     result.Labels,
+		Advisories: result.Advisories,
+		Mentions:   result.Mentions,
+	}
+}
+
+// ParseSince returns the start of a digest window given as a number of days
+// or weeks before now, such as 7d or 2w, a Go duration such as 36h, or a
+// date or time such as 2026-10-01 or 2026-10-01T09:00:00Z.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			if n, err := strconv.Atoi(number); err == nil && n >= 0 {
+				return now.Add(-time.Duration(n) * unit), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q, expected a number of days or weeks such as 7d, a duration such as 36h or a date such as 2026-10-01", value)
+}
@@ -0,0 +1,163 @@
+package digest
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/classify"
+	"github.com/toozej/ghreleases2rss/internal/feed"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 10, 0, 0, 0, time.UTC)
+}
+
+// entry returns a release feed entry, escaping slashes in the tag link as
+// GitHub does.
+func entry(repo, tag string, d int, notes string) feed.Entry {
+	return feed.Entry{
+		ID:      repo + "/" + tag,
+		Title:   tag,
+		Link:    "https://github.com/" + repo + "/releases/tag/" + url.PathEscape(tag),
+		Updated: day(d),
+		Content: notes,
+	}
+}
+
+func TestDigestAdd(t *testing.T) {
+	d := &Digest{Since: day(11), Until: day(18)}
+	d.Add("owner/app", []feed.Entry{
+		entry("owner/app", "v2.0.0", 16, "<h2>BREAKING CHANGES</h2><ul><li>Removed the <code>--legacy</code> flag</li></ul>"),
+		entry("owner/app", "v2.0.0-rc.1", 13, "<p>Release candidate</p>"),
+		entry("owner/app", "v1.9.1", 12, "<p>Fixes GHSA-JFH8-C2JP-5V3Q in the parser</p>"),
+		entry("owner/app", "v1.9.0", 2, ""),
+	})
+	d.Add("owner/mono", []feed.Entry{
+		entry("owner/mono", "sdk/v3.1.0", 15, ""),
+		entry("owner/mono", "cli/v1.0.0", 14, "<p>Breaking changes to output</p>"),
+		entry("owner/mono", "sdk/v2.9.0", 1, ""),
+		entry("owner/mono", "cli/v0.9.0", 1, ""),
+	})
+	d.Add("owner/quiet", []feed.Entry{entry("owner/quiet", "v1.0.0", 3, "")})
+	d.Add("owner/future", []feed.Entry{entry("owner/future", "v1.0.0", 19, "")})
+
+	type got struct {
+		Title  string
+		Labels []string
+	}
+	want := map[string][]got{
+		"owner/app": {
+			{"v2.0.0", []string{classify.LabelMajor, classify.LabelBreaking}},
+			{"v2.0.0-rc.1", []string{classify.LabelMajor}},
+			{"v1.9.1", []string{classify.LabelSecurity}},
+		},
+		"owner/mono": {
+			{"sdk/v3.1.0", []string{classify.LabelMajor}},
+			{"cli/v1.0.0", []string{classify.LabelMajor, classify.LabelBreaking}},
+		},
+	}
+	if len(d.Repos) != len(want) {
+		t.Fatalf("Add() kept %d repos, want %d", len(d.Repos), len(want))
+	}
+	for _, repo := range d.Repos {
+		var releases []got
+		for _, r := range repo.Releases {
+			releases = append(releases, got{r.Title, r.Labels})
+		}
+		if !reflect.DeepEqual(releases, want[repo.Name]) {
+			t.Errorf("Add(%s) = %v, want %v", repo.Name, releases, want[repo.Name])
+		}
+	}
+	if d.Count() != 5 || len(d.Highlighted()) != 5 {
+		t.Errorf("Count() = %d, Highlighted() = %d", d.Count(), len(d.Highlighted()))
+	}
+	if mentions := d.Repos[0].Releases[0].Mentions; !reflect.DeepEqual(mentions, []string{"BREAKING CHANGES"}) {
+		t.Errorf("Mentions = %q", mentions)
+	}
+	if advisories := d.Repos[0].Releases[2].Advisories; !reflect.DeepEqual(advisories, []string{"GHSA-jfh8-c2jp-5v3q"}) {
+		t.Errorf("Advisories = %q", advisories)
+	}
+	if !d.Repos[0].Releases[1].Prerelease {
+		t.Error("v2.0.0-rc.1 should be a prerelease")
+	}
+}
+
+func TestDigestWrite(t *testing.T) {
+	d := &Digest{Title: "Release digest: Infra", Since: day(11), Until: day(18)}
+	d.Add("owner/app", []feed.Entry{
+		entry("owner/app", "v2.0.0", 16, "<p>BREAKING: removed <em>legacy</em> mode</p>"),
+		entry("owner/app", "v1.9.1", 12, ""),
+	})
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{
+			format: FormatMarkdown,
+			want: []string{
+				"# Release digest: Infra\n\n2 releases in 1 repos from 2026-10-11 to 2026-10-18.\n",
+				"## Highlights\n\n- owner/app [v2.0.0](https://github.com/owner/app/releases/tag/v2.0.0): major, breaking\n",
+				"## owner/app\n\n- [v2.0.0](https://github.com/owner/app/releases/tag/v2.0.0) · 2026-10-16 · **major** · **breaking**\n  - BREAKING: removed _legacy_ mode\n- [v1.9.1](https://github.com/owner/app/releases/tag/v1.9.1) · 2026-10-12\n",
+			},
+		},
+		{
+			format: FormatHTML,
+			want: []string{
+				"<title>Release digest: Infra</title>",
+				`<li>owner/app <a href="https://github.com/owner/app/releases/tag/v2.0.0">v2.0.0</a>: <span class="highlight">major</span> <span class="highlight">breaking</span></li>`,
+				`<ul class="mentions"><li>BREAKING: removed _legacy_ mode</li></ul>`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b strings.Builder
+			if err := d.Write(&b, tt.format); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("Write() does not contain %q:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+	if err := d.Write(&strings.Builder{}, "pdf"); err == nil {
+		t.Error("Write() should reject unknown formats")
+	}
+	if got := d.Subject(); got != "Release digest: Infra, 2 releases since 2026-10-11" {
+		t.Errorf("Subject() = %q", got)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := day(18)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "7d", want: day(11)},
+		{value: "1w", want: day(11)},
+		{value: "36h", want: now.Add(-36 * time.Hour)},
+		{value: "2026-10-01", want: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2026-10-01T09:00:00Z", want: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+		{value: "-7d", wantErr: true},
+		{value: "last week", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSince() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
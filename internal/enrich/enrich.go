@@ -0,0 +1,136 @@
+// Package enrich adds GitHub API release data to release feed entries:
+// asset enclosures, prerelease and latest markers, a compare link to the
+// previous release and release notes rendered from their Markdown source.
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+// PrereleaseMarker prefixes the titles of prerelease entries.
+const PrereleaseMarker = "[prerelease] "
+
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Enricher enriches the release feed entries of a repo with data from the
+// GitHub API.
+type Enricher struct {
+	API *github.API
+}
+
+// Enrich fetches the releases of repo and applies them to entries.
+func (e *Enricher) Enrich(ctx context.Context, repo string, entries []feed.Entry) ([]feed.Entry, error) {
+	releases, err := e.API.Releases(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := e.API.LatestRelease(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return Apply(entries, releases, latest), nil
+}
+
+// Apply returns a copy of entries in which every entry with a matching
+// release in releases has its notes rendered from Markdown, its assets as
+// enclosures, its author's login, a compare link to the previous release and
+// the prerelease and latest categories, with prerelease titles prefixed by
+// PrereleaseMarker. The previous release is the next older one with the same
+// tag prefix. latest is the tag GitHub marks as the latest release.
+// Entries without a matching release are left unchanged.
+func Apply(entries []feed.Entry, releases []github.Release, latest string) []feed.Entry {
+	sorted := append([]github.Release(nil), releases...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].PublishedAt.After(sorted[j].PublishedAt) })
+	index := make(map[string]int, len(sorted))
+	for i, release := range sorted {
+		index[release.TagName] = i
+	}
+
+	enriched := make([]feed.Entry, len(entries))
+	for i, entry := range entries {
+		entry.Links = append([]feed.Link(nil), entry.Links...)
+		entry.Categories = append([]string(nil), entry.Categories...)
+		if j, ok := index[entry.Tag()]; ok {
+			entry = applyRelease(entry, sorted[j], previousRelease(sorted, j), sorted[j].TagName == latest)
+		}
+		enriched[i] = entry
+	}
+	return enriched
+}
+
+func applyRelease(entry feed.Entry, release github.Release, previous *github.Release, latest bool) feed.Entry {
+	if release.Body != "" {
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(release.Body), &buf); err == nil {
+			entry.Content = buf.String()
+		}
+	}
+	if release.Author.Login != "" {
+		entry.Author = release.Author.Login
+	}
+	for _, asset := range release.Assets {
+		entry.Links = append(entry.Links, feed.Link{
+			Rel:    "enclosure",
+			Href:   asset.BrowserDownloadURL,
+			Type:   asset.ContentType,
+			Title:  asset.Name,
+			Length: asset.Size,
+		})
+	}
+	if compare := compareURL(release, previous); compare != "" {
+		title := "Compare with " + previous.TagName
+		entry.Links = append(entry.Links, feed.Link{Rel: "related", Href: compare, Type: "text/html", Title: title})
+		entry.Content += `<p><a href="` + html.EscapeString(compare) + `">` + html.EscapeString(title) + `</a></p>`
+	}
+	if release.Prerelease {
+		entry.Categories = append(entry.Categories, feed.CategoryPrerelease)
+		if !strings.HasPrefix(entry.Title, PrereleaseMarker) {
+			entry.Title = PrereleaseMarker + entry.Title
+		}
+	}
+	if latest {
+		entry.Categories = append(entry.Categories, feed.CategoryLatest)
+	}
+	return entry
+}
+
+// previousRelease returns the newest release older than sorted[i] whose tag
+// has the same prefix up to the last slash, so releases of one component of
+// a monorepo, such as cli/v1.2.0, are compared with each other, or nil.
+func previousRelease(sorted []github.Release, i int) *github.Release {
+	prefix := tagPrefix(sorted[i].TagName)
+	for j := i + 1; j < len(sorted); j++ {
+		if tagPrefix(sorted[j].TagName) == prefix {
+			return &sorted[j]
+		}
+	}
+	return nil
+}
+
+func tagPrefix(tag string) string {
+	return tag[:strings.LastIndex(tag, "/")+1]
+}
+
+// compareURL returns the URL comparing previous with release on the web
+// host of release, or "" when there is no previous release.
+func compareURL(release github.Release, previous *github.Release) string {
+	if previous == nil {
+		return ""
+	}
+	repoURL, _, ok := strings.Cut(release.HTMLURL, "/releases/")
+	if !ok {
+		return ""
+	}
+	return repoURL + "/compare/" + url.PathEscape(previous.TagName) + "..." + url.PathEscape(release.TagName)
+}
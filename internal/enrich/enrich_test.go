@@ -0,0 +1,88 @@
+package enrich
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+// entry returns a release feed entry for tag as GitHub publishes it.
+func entry(tag string) feed.Entry {
+	return feed.Entry{Title: tag, Link: "https://github.com/owner/repo/releases/tag/" + tag, Content: "<p>plain</p>", Author: "feed"}
+}
+
+func testReleases() []github.Release {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	rc := github.Release{TagName: "v1.1.0-rc.1", Body: "## Fixes\n\n- **crash** on start <script>alert(1)</script>", HTMLURL: "https://github.com/owner/repo/releases/tag/v1.1.0-rc.1", Prerelease: true, PublishedAt: day(3)}
+	rc.Author.Login = "octocat"
+	rc.Assets = []github.Asset{{Name: "tool.tar.gz", ContentType: "application/gzip", Size: 1024, BrowserDownloadURL: "https://github.com/owner/repo/releases/download/v1.1.0-rc.1/tool.tar.gz"}}
+	return []github.Release{
+		{TagName: "v1.0.0", HTMLURL: "https://github.com/owner/repo/releases/tag/v1.0.0", PublishedAt: day(1)},
+		rc,
+		{TagName: "v1.0.1", HTMLURL: "https://github.com/owner/repo/releases/tag/v1.0.1", PublishedAt: day(2)},
+	}
+}
+
+func TestApply(t *testing.T) {
+	entries := []feed.Entry{entry("v1.1.0-rc.1"), entry("v1.0.1"), entry("v1.0.0"), entry("v0.9.0")}
+	got := Apply(entries, testReleases(), "v1.0.1")
+
+	rc := got[0]
+	if rc.Title != "[prerelease] v1.1.0-rc.1" || rc.Author != "octocat" || !slices.Equal(rc.Categories, []string{feed.CategoryPrerelease}) {
+		t.Errorf("Apply() prerelease = %+v", rc)
+	}
+	if !strings.Contains(rc.Content, "<h2>Fixes</h2>") || !strings.Contains(rc.Content, "<strong>crash</strong>") || strings.Contains(rc.Content, "<script>") {
+		t.Errorf("Apply() notes = %q, want rendered Markdown without raw HTML", rc.Content)
+	}
+	wantLinks := []feed.Link{
+		{Rel: "enclosure", Href: "https://github.com/owner/repo/releases/download/v1.1.0-rc.1/tool.tar.gz", Type: "application/gzip", Title: "tool.tar.gz", Length: 1024},
+		{Rel: "related", Href: "https://github.com/owner/repo/compare/v1.0.1...v1.1.0-rc.1", Type: "text/html", Title: "Compare with v1.0.1"},
+	}
+	if !slices.Equal(rc.Links, wantLinks) {
+		t.Errorf("Apply() links = %+v, want %+v", rc.Links, wantLinks)
+	}
+
+	if latest := got[1]; latest.Title != "v1.0.1" || latest.Content == "<p>plain</p>" || !slices.Equal(latest.Categories, []string{feed.CategoryLatest}) {
+		t.Errorf("Apply() latest = %+v", latest)
+	}
+	if first := got[2]; len(first.Links) != 0 || first.Content != "<p>plain</p>" || first.Author != "feed" {
+		t.Errorf("Apply() first release = %+v, want no compare link and the feed's notes", first)
+	}
+	if unmatched := got[3]; unmatched.Title != "v0.9.0" || len(unmatched.Categories) != 0 {
+		t.Errorf("Apply() entry without release = %+v", unmatched)
+	}
+	if entries[0].Title != "v1.1.0-rc.1" || len(entries[0].Links) != 0 {
+		t.Errorf("Apply() modified its input: %+v", entries[0])
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases":
+			_, _ = w.Write([]byte(`[{"tag_name": "v1.0.0", "prerelease": true, "html_url": "https://github.com/owner/repo/releases/tag/v1.0.0"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	e := &Enricher{API: github.NewAPI(mockServer.URL, "", nil)}
+	got, err := e.Enrich(context.Background(), "owner/repo", []feed.Entry{entry("v1.0.0")})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if got[0].Title != "[prerelease] v1.0.0" {
+		t.Errorf("Enrich() title = %q", got[0].Title)
+	}
+	if _, err := e.Enrich(context.Background(), "owner/missing", nil); err == nil {
+		t.Error("Enrich() should fail when the releases cannot be fetched")
+	}
+}
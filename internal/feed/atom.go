@@ -0,0 +1,101 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// AtomContentType is the media type of Atom documents written by WriteAtom.
+const AtomContentType = "application/atom+xml; charset=utf-8"
+
+type atomOutput struct {
+	XMLName xml.Name          `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string            `xml:"id"`
+	Title   string            `xml:"title"`
+	Updated string            `xml:"updated"`
+	Links   []atomOutputLink  `xml:"link"`
+	Entries []atomOutputEntry `xml:"entry"`
+}
+
+type atomOutputLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Title  string `xml:"title,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomOutputEntry struct {
+	ID         string           `xml:"id"`
+	Title      string           `xml:"title"`
+	Updated    string           `xml:"updated"`
+	Links      []atomOutputLink `xml:"link,omitempty"`
+	Content    *atomContent     `xml:"content,omitempty"`
+	Author     *atomAuthor      `xml:"author,omitempty"`
+	Categories []atomCategory   `xml:"category,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// WriteAtom writes f as an Atom document. self is the URL the document is
+// served from and is left out when empty.
+func WriteAtom(w io.Writer, f *Feed, self string) error {
+	doc := atomOutput{ID: f.ID, Title: f.Title, Updated: formatTime(f.Updated)}
+	if f.Link != "" {
+		doc.Links = append(doc.Links, atomOutputLink{Rel: "alternate", Type: "text/html", Href: f.Link})
+	}
+	if self != "" {
+		doc.Links = append(doc.Links, atomOutputLink{Rel: "self", Type: "application/atom+xml", Href: self})
+	}
+	for _, e := range f.Entries {
+		entry := atomOutputEntry{ID: e.ID, Title: e.Title, Updated: formatTime(e.Updated)}
+		if e.Link != "" {
+			entry.Links = []atomOutputLink{{Rel: "alternate", Type: "text/html", Href: e.Link}}
+		}
+		for _, link := range e.Links {
+			entry.Links = append(entry.Links, atomOutputLink{Rel: link.Rel, Type: link.Type, Href: link.Href, Title: link.Title, Length: link.Length})
+		}
+		for _, term := range e.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: term})
+		}
+		if e.Content != "" {
+			entry.Content = &atomContent{Type: "html", Body: e.Content}
+		}
+		if e.Author != "" {
+			entry.Author = &atomAuthor{Name: e.Author}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// formatTime formats t for Atom, using the Unix epoch for unknown times as
+// the updated element is required.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,179 @@
+// Package feed fetches GitHub release Atom feeds and parses them into entries.
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// maxFeedSize limits how much of a release feed is read.
+const maxFeedSize = 8 << 20
+
+// Entry categories set on releases enriched with GitHub API data.
+const (
+	CategoryPrerelease = "prerelease"
+	CategoryLatest     = "latest"
+)
+
+// Feed is a parsed release feed.
+type Feed struct {
+	ID      string
+	Title   string
+	Link    string
+	Updated time.Time
+	Entries []Entry
+}
+
+// Entry is a single release in a feed.
+type Entry struct {
+	ID      string
+	Title   string
+	Link    string
+	Updated time.Time
+	// Content is the release notes as HTML.
+	Content string
+	Author  string
+	// Links are links other than the alternate one, such as enclosures.
+	Links []Link
+	// Categories are the terms of the entry's categories.
+	Categories []string
+}
+
+// Link is an Atom link of an entry other than its alternate link.
+type Link struct {
+	Rel    string
+	Href   string
+	Type   string
+	Title  string
+	Length int64
+}
+
+// Tag returns the release tag, taken from the last segment of the entry's
+// /releases/tag/ link and falling back to the title.
+func (e Entry) Tag() string {
+	if u, err := url.Parse(e.Link); err == nil && strings.Contains(u.Path, "/releases/tag/") {
+		if tag, err := url.PathUnescape(path.Base(u.EscapedPath())); err == nil {
+			return tag
+		}
+	}
+	return strings.TrimSpace(e.Title)
+}
+
+// Repo returns the owner/repo the entry was released in, taken from the
+// path before /releases/tag/ in its link, or "" when the link has none.
+func (e Entry) Repo() string {
+	u, err := url.Parse(e.Link)
+	if err != nil {
+		return ""
+	}
+	repoPath, _, ok := strings.Cut(u.Path, "/releases/tag/")
+	if !ok {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(repoPath, "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	return strings.Join(segments[len(segments)-2:], "/")
+}
+
+type atomFeed struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Links   []atomLink `xml:"link"`
+	Updated string     `xml:"updated"`
+	Entries []struct {
+		ID      string     `xml:"id"`
+		Title   string     `xml:"title"`
+		Links   []atomLink `xml:"link"`
+		Updated string     `xml:"updated"`
+		Content string     `xml:"content"`
+		Author  struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr"`
+	Title  string `xml:"title,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+// alternate returns the alternate link, which Atom treats as the default rel.
+func alternate(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// Parse parses an Atom feed document.
+func Parse(data []byte) (*Feed, error) {
+	var doc atomFeed
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid Atom feed: %w", err)
+	}
+
+	feed := &Feed{ID: doc.ID, Title: doc.Title, Link: alternate(doc.Links), Updated: parseTime(doc.Updated)}
+	for _, e := range doc.Entries {
+		entry := Entry{
+			ID:      e.ID,
+			Title:   strings.TrimSpace(e.Title),
+			Link:    alternate(e.Links),
+			Updated: parseTime(e.Updated),
+			Content: e.Content,
+			Author:  e.Author.Name,
+		}
+		for _, link := range e.Links {
+			if link.Rel != "" && link.Rel != "alternate" {
+				entry.Links = append(entry.Links, Link{Rel: link.Rel, Href: link.Href, Type: link.Type, Title: link.Title, Length: link.Length})
+			}
+		}
+		for _, category := range e.Categories {
+			entry.Categories = append(entry.Categories, category.Term)
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed, nil
+}
+
+func parseTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	return t
+}
+
+// Fetch downloads and parses the Atom feed at feedURL. http.DefaultClient is
+// used when client is nil.
+func Fetch(client *http.Client, feedURL string) (*Feed, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(feedURL) // #nosec G107 -- feed URLs are constructed by GetReleaseFeedURL
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch feed %s, status code: %d", feedURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
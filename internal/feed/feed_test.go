@@ -0,0 +1,371 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en-US">
+  <id>tag:github.com,2008:https://github.com/owner/repo/releases</id>
+  <link type="text/html" rel="alternate" href="https://github.com/owner/repo/releases"/>
+  <link type="application/atom+xml" rel="self" href="https://github.com/owner/repo/releases.atom"/>
+  <title>Release notes from repo</title>
+  <updated>2026-03-01T10:00:00Z</updated>
+  <entry>
+    <id>tag:github.com,2008:Repository/1/v1.5.0</id>
+    <updated>2026-03-01T10:00:00Z</updated>
+    <link rel="alternate" type="text/html" href="https://github.com/owner/repo/releases/tag/v1.5.0"/>
+    <title>Spring release</title>
+    <content type="html">&lt;h2&gt;Features&lt;/h2&gt;&lt;ul&gt;&lt;li&gt;New &lt;code&gt;--flag&lt;/code&gt;&lt;/li&gt;&lt;/ul&gt;</content>
+    <author><name>octocat</name></author>
+  </entry>
+  <entry>
+    <id>tag:github.com,2008:Repository/1/cli%2Fv1.4.0</id>
+    <updated>2026-01-15T10:00:00Z</updated>
+    <link rel="alternate" type="text/html" href="https://github.com/owner/repo/releases/tag/cli%2Fv1.4.0"/>
+    <title>cli/v1.4.0</title>
+    <content type="html">No changes.</content>
+  </entry>
+</feed>`
+
+func TestParse(t *testing.T) {
+	feed, err := Parse([]byte(testFeed))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if feed.Title != "Release notes from repo" || feed.Link != "https://github.com/owner/repo/releases" {
+		t.Errorf("Parse() feed = %q %q", feed.Title, feed.Link)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("Parse() returned %d entries, want 2", len(feed.Entries))
+	}
+
+	first := feed.Entries[0]
+	if first.Tag() != "v1.5.0" || first.Title != "Spring release" || first.Author != "octocat" {
+		t.Errorf("Entries[0] = %+v", first)
+	}
+	if !first.Updated.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Entries[0].Updated = %v", first.Updated)
+	}
+	if got := feed.Entries[1].Tag(); got != "cli/v1.4.0" {
+		t.Errorf("Entries[1].Tag() = %v, want cli/v1.4.0", got)
+	}
+	if got := feed.Entries[1].Repo(); got != "owner/repo" {
+		t.Errorf("Entries[1].Repo() = %v, want owner/repo", got)
+	}
+
+	if _, err := Parse([]byte("not xml")); err == nil {
+		t.Error("Parse() should fail for invalid XML")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/owner/repo/releases.atom" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, testFeed)
+	}))
+	defer mockServer.Close()
+
+	feed, err := Fetch(nil, mockServer.URL+"/owner/repo/releases.atom")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(feed.Entries) != 2 {
+		t.Errorf("Fetch() returned %d entries, want 2", len(feed.Entries))
+	}
+
+	if _, err := Fetch(nil, mockServer.URL+"/owner/missing/releases.atom"); err == nil {
+		t.Error("Fetch() should fail for missing feeds")
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	parsed, err := Parse([]byte(testFeed))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	parsed.Entries[0].Links = []Link{
+		{Rel: "enclosure", Href: "https://github.com/owner/repo/releases/download/v1.5.0/tool.tar.gz", Type: "application/gzip", Title: "tool.tar.gz", Length: 1024},
+		{Rel: "related", Href: "https://github.com/owner/repo/compare/v1.4.0...v1.5.0", Type: "text/html", Title: "Compare with v1.4.0"},
+	}
+	parsed.Entries[0].Categories = []string{CategoryPrerelease, CategoryLatest}
+
+	var buf bytes.Buffer
+	if err := WriteAtom(&buf, parsed, "https://example.com/feeds/all.atom"); err != nil {
+		t.Fatalf("WriteAtom() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `<link rel="self" type="application/atom+xml" href="https://example.com/feeds/all.atom"></link>`) {
+		t.Errorf("WriteAtom() is missing the self link:\n%s", buf.String())
+	}
+
+	written, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse() of written feed error = %v", err)
+	}
+	if written.ID != parsed.ID || written.Title != parsed.Title || written.Link != parsed.Link || !written.Updated.Equal(parsed.Updated) {
+		t.Errorf("written feed = %+v, want %+v", written, parsed)
+	}
+	if len(written.Entries) != len(parsed.Entries) {
+		t.Fatalf("written feed has %d entries, want %d", len(written.Entries), len(parsed.Entries))
+	}
+	for i := range parsed.Entries {
+		if got, want := written.Entries[i], parsed.Entries[i]; got.ID != want.ID || got.Title != want.Title || got.Link != want.Link ||
+			got.Content != want.Content || got.Author != want.Author || !got.Updated.Equal(want.Updated) ||
+			!slices.Equal(got.Links, want.Links) || !slices.Equal(got.Categories, want.Categories) {
+			t.Errorf("written Entries[%d] = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{tag: "v1.2.3", want: "1.2.3"},
+		{tag: "1.2", want: "1.2.0"},
+		{tag: "service/s3/v1.50.0", want: "1.50.0"},
+		{tag: "release-2.0.0-rc.1", want: "2.0.0-rc.1"},
+		{tag: "release-2026-01-15", wantErr: true},
+		{tag: "nightly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := ParseVersion(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "Heading and list",
+			html: "<h2>What's Changed</h2>\n<ul>\n<li>Fix <strong>crash</strong> by <a href=\"https://github.com/a\">@a</a> in <a href=\"https://github.com/o/r/pull/1\">#1</a></li>\n<li>Add <code>--json</code></li>\n</ul>",
+			want: "## What's Changed\n\n- Fix **crash** by [@a](https://github.com/a) in [#1](https://github.com/o/r/pull/1)\n- Add `--json`",
+		},
+		{
+			name: "Paragraphs and code block",
+			html: "<p>First   line<br>second</p><pre><code>go install ./...\n</code></pre><p><em>Full</em> changelog</p>",
+			want: "First line\nsecond\n\n```\ngo install ./...\n```\n\n_Full_ changelog",
+		},
+		{
+			name: "Ordered list and quote",
+			html: "<ol><li>one</li><li>two</li></ol><blockquote><p>Breaking</p></blockquote>",
+			want: "1. one\n2. two\n\n> Breaking",
+		},
+		{
+			name: "Plain text",
+			html: "No changes.",
+			want: "No changes.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markdown(tt.html); got != tt.want {
+				t.Errorf("Markdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// enrichedTestFeed returns the parsed test feed with an enclosure and
+// categories on its first entry.
+func enrichedTestFeed(t *testing.T) *Feed {
+	t.Helper()
+	parsed, err := Parse([]byte(testFeed))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	parsed.Entries[0].Links = []Link{
+		{Rel: "enclosure", Href: "https://github.com/owner/repo/releases/download/v1.5.0/tool.tar.gz", Type: "application/gzip", Title: "tool.tar.gz", Length: 1024},
+		{Rel: "enclosure", Href: "https://github.com/owner/repo/releases/download/v1.5.0/tool.zip", Title: "tool.zip", Length: 2048},
+	}
+	parsed.Entries[0].Categories = []string{CategoryLatest}
+	return parsed
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, enrichedTestFeed(t), "https://example.com/feeds/all.json"); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var doc jsonFeed
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Version != "https://jsonfeed.org/version/1.1" || doc.FeedURL != "https://example.com/feeds/all.json" || doc.HomePageURL != "https://github.com/owner/repo/releases" {
+		t.Errorf("WriteJSON() feed = %+v", doc)
+	}
+	if len(doc.Items) != 2 {
+		t.Fatalf("WriteJSON() wrote %d items, want 2", len(doc.Items))
+	}
+	first := doc.Items[0]
+	if first.ID != "tag:github.com,2008:Repository/1/v1.5.0" || first.DatePublished != "2026-03-01T10:00:00Z" ||
+		!strings.HasPrefix(first.ContentHTML, "<h2>Features</h2>") || first.Authors[0].Name != "octocat" || !slices.Equal(first.Tags, []string{CategoryLatest}) {
+		t.Errorf("WriteJSON() Items[0] = %+v", first)
+	}
+	wantAttachments := []jsonFeedAttachment{
+		{URL: "https://github.com/owner/repo/releases/download/v1.5.0/tool.tar.gz", MIMEType: "application/gzip", Title: "tool.tar.gz", SizeInBytes: 1024},
+		{URL: "https://github.com/owner/repo/releases/download/v1.5.0/tool.zip", MIMEType: "application/octet-stream", Title: "tool.zip", SizeInBytes: 2048},
+	}
+	if !slices.Equal(first.Attachments, wantAttachments) {
+		t.Errorf("WriteJSON() attachments = %+v, want %+v", first.Attachments, wantAttachments)
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRSS(&buf, enrichedTestFeed(t), "https://example.com/feeds/all.rss"); err != nil {
+		t.Fatalf("WriteRSS() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `<atom:link rel="self" type="application/rss+xml" href="https://example.com/feeds/all.rss"></atom:link>`) {
+		t.Errorf("WriteRSS() is missing the self link:\n%s", buf.String())
+	}
+
+	var doc struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title       string `xml:"title"`
+				Link        string `xml:"link"`
+				Description string `xml:"description"`
+				Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				GUID        string `xml:"guid"`
+				PubDate     string `xml:"pubDate"`
+				Enclosures  []struct {
+					URL string `xml:"url,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteRSS() wrote invalid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Channel.Title != "Release notes from repo" || len(doc.Channel.Items) != 2 {
+		t.Fatalf("WriteRSS() channel = %+v", doc.Channel)
+	}
+	first := doc.Channel.Items[0]
+	if first.Title != "Spring release" || first.Creator != "octocat" || first.GUID != "tag:github.com,2008:Repository/1/v1.5.0" ||
+		first.PubDate != "Sun, 01 Mar 2026 10:00:00 +0000" || !strings.HasPrefix(first.Description, "<h2>Features</h2>") {
+		t.Errorf("WriteRSS() Items[0] = %+v", first)
+	}
+	if len(first.Enclosures) != 1 || !strings.HasSuffix(first.Enclosures[0].URL, "/tool.tar.gz") {
+		t.Errorf("WriteRSS() enclosures = %+v, want only the first", first.Enclosures)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Format
+	}{
+		{accept: "", want: FormatAtom},
+		{accept: "*/*", want: FormatAtom},
+		{accept: "text/html, application/xhtml+xml", want: FormatAtom},
+		{accept: "application/feed+json", want: FormatJSON},
+		{accept: "application/json", want: FormatJSON},
+		{accept: "application/rss+xml, application/atom+xml", want: FormatRSS},
+		{accept: "application/rss+xml;q=0.8, application/atom+xml;q=0.9", want: FormatAtom},
+		{accept: "application/rss+xml;q=invalid, application/feed+json;q=0.1", want: FormatJSON},
+		{accept: "text/calendar", want: FormatICS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := Negotiate(tt.accept); got != tt.want {
+				t.Errorf("Negotiate(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCutExtension(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantBase   string
+		wantFormat Format
+		wantOK     bool
+	}{
+		{name: "dev-tools.atom", wantBase: "dev-tools", wantFormat: FormatAtom, wantOK: true},
+		{name: "releases.json", wantBase: "releases", wantFormat: FormatJSON, wantOK: true},
+		{name: "releases.rss", wantBase: "releases", wantFormat: FormatRSS, wantOK: true},
+		{name: "dev-tools.ics", wantBase: "dev-tools", wantFormat: FormatICS, wantOK: true},
+		{name: "releases.html", wantBase: "releases.html"},
+		{name: "releases", wantBase: "releases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, format, ok := CutExtension(tt.name)
+			if base != tt.wantBase || format != tt.wantFormat || ok != tt.wantOK {
+				t.Errorf("CutExtension(%q) = %q, %q, %v, want %q, %q, %v", tt.name, base, format, ok, tt.wantBase, tt.wantFormat, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	f := enrichedTestFeed(t)
+	f.Entries[1].Content = "<p>" + strings.Repeat("Fixes; many, many things. ", 40) + "</p>"
+	f.Entries = append(f.Entries, Entry{ID: "undated", Title: "Undated"})
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, f, "https://example.com/feeds/all.ics"); err != nil {
+		t.Fatalf("WriteICS() error = %v", err)
+	}
+	doc := buf.String()
+	if !strings.HasPrefix(doc, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(doc, "END:VCALENDAR\r\n") {
+		t.Errorf("WriteICS() is not a calendar:\n%s", doc)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(doc, "\r\n"), "\r\n") {
+		if len(line) > 75 || strings.Contains(line, "\n") {
+			t.Errorf("WriteICS() line %q is not folded", line)
+		}
+	}
+	if got := strings.Count(doc, "BEGIN:VEVENT"); got != 2 {
+		t.Errorf("WriteICS() wrote %d events, want 2 without the undated entry", got)
+	}
+
+	// Unfold the lines to check property values
+	unfolded := strings.ReplaceAll(doc, "\r\n ", "")
+	for _, want := range []string{
+		"SUMMARY:owner/repo v1.5.0\r\n",
+		"SUMMARY:owner/repo cli/v1.4.0\r\n",
+		"DTSTART;VALUE=DATE:20260301\r\nDTEND;VALUE=DATE:20260302\r\n",
+		"URL;VALUE=URI:https://github.com/owner/repo/releases/tag/v1.5.0\r\n",
+		"DESCRIPTION:https://github.com/owner/repo/releases/tag/v1.5.0\\n\\n## Features\\n\\n- New `--flag`\r\n",
+		"CATEGORIES:latest\r\n",
+		"Fixes\\; many\\, many things.",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("WriteICS() is missing %q:\n%s", want, unfolded)
+		}
+	}
+	if !strings.Contains(unfolded, "…\r\n") || strings.Count(unfolded, "Fixes") >= 40 {
+		t.Errorf("WriteICS() should shorten long notes:\n%s", unfolded)
+	}
+}
@@ -0,0 +1,108 @@
+package feed
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Format is a feed document format that a Feed can be written in.
+type Format string
+
+// Supported formats, named by their file extension without the dot.
+const (
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+	FormatRSS  Format = "rss"
+	FormatICS  Format = "ics"
+)
+
+// Formats lists the supported formats, Atom first as the default.
+var Formats = []Format{FormatAtom, FormatJSON, FormatRSS, FormatICS}
+
+// Extension returns the file extension of documents in format f.
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// ContentType returns the media type of documents in format f.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return JSONFeedContentType
+	case FormatRSS:
+		return RSSContentType
+	case FormatICS:
+		return ICSContentType
+	default:
+		return AtomContentType
+	}
+}
+
+// CutExtension splits name into its base and the format named by its
+// extension. ok is false when the extension is not a supported format.
+func CutExtension(name string) (base string, format Format, ok bool) {
+	ext := path.Ext(name)
+	for _, f := range Formats {
+		if ext == f.Extension() {
+			return strings.TrimSuffix(name, ext), f, true
+		}
+	}
+	return name, "", false
+}
+
+// acceptTypes maps the media types in Accept headers to formats. Plain JSON
+// is accepted for clients that do not know the JSON Feed media type.
+var acceptTypes = map[string]Format{
+	"application/atom+xml":  FormatAtom,
+	"application/feed+json": FormatJSON,
+	"application/json":      FormatJSON,
+	"application/rss+xml":   FormatRSS,
+	"text/calendar":         FormatICS,
+}
+
+// Negotiate returns the format preferred by an Accept header, by quality and
+// then order, or FormatAtom when it accepts none of them specifically.
+func Negotiate(accept string) Format {
+	best, bestQ := FormatAtom, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		format, ok := acceptTypes[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	return best
+}
+
+// Write writes f as a document in format. self is the URL the document is
+// served from and is left out when empty.
+func Write(w io.Writer, f *Feed, format Format, self string) error {
+	switch format {
+	case FormatAtom:
+		return WriteAtom(w, f, self)
+	case FormatJSON:
+		return WriteJSON(w, f, self)
+	case FormatRSS:
+		return WriteRSS(w, f, self)
+	case FormatICS:
+		return WriteICS(w, f, self)
+	default:
+		return fmt.Errorf("unsupported feed format %q", format)
+	}
+}
@@ -0,0 +1,122 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ICSContentType is the media type of iCalendar documents written by
+// WriteICS.
+const ICSContentType = "text/calendar; charset=utf-8"
+
+// excerptLength caps the release notes in event descriptions, in characters.
+const excerptLength = 500
+
+// WriteICS writes f as an iCalendar document with an all-day event per entry
+// on the UTC date it was released, titled with its repo and tag, such as
+// "owner/repo v1.5.0", and described by its link and an excerpt of its notes.
+// Entries without a date are left out. self is the URL the document is
+// served from and is left out when empty.
+func WriteICS(w io.Writer, f *Feed, self string) error {
+	c := &icsWriter{w: w}
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", "-//ghreleases2rss//Releases//EN")
+	c.line("CALSCALE", "GREGORIAN")
+	c.line("METHOD", "PUBLISH")
+	c.text("X-WR-CALNAME", f.Title)
+	if self != "" {
+		c.line("SOURCE;VALUE=URI", self)
+	}
+	for _, e := range f.Entries {
+		if e.Updated.IsZero() {
+			continue
+		}
+		day := e.Updated.UTC()
+		id := e.ID
+		if id == "" {
+			id = e.Link
+		}
+		sum := sha256.Sum256([]byte(id))
+
+		c.line("BEGIN", "VEVENT")
+		c.line("UID", hex.EncodeToString(sum[:16])+"@ghreleases2rss")
+		c.line("DTSTAMP", day.Format("20060102T150405Z"))
+		c.line("DTSTART;VALUE=DATE", day.Format("20060102"))
+		c.line("DTEND;VALUE=DATE", day.AddDate(0, 0, 1).Format("20060102"))
+		c.text("SUMMARY", eventTitle(e))
+		if e.Link != "" {
+			c.line("URL;VALUE=URI", e.Link)
+		}
+		if description := strings.TrimSpace(e.Link + "\n\n" + excerpt(Markdown(e.Content))); description != "" {
+			c.text("DESCRIPTION", description)
+		}
+		for _, category := range e.Categories {
+			c.text("CATEGORIES", category)
+		}
+		c.line("END", "VEVENT")
+	}
+	c.line("END", "VCALENDAR")
+	return c.err
+}
+
+// eventTitle returns "owner/repo tag" for entries linking to a GitHub
+// release, or the entry title otherwise.
+func eventTitle(e Entry) string {
+	repo, tag := e.Repo(), e.Tag()
+	if repo == "" || tag == "" {
+		return e.Title
+	}
+	return repo + " " + tag
+}
+
+// excerpt shortens text to at most excerptLength characters, cutting at a
+// word boundary and marking the cut with an ellipsis.
+func excerpt(text string) string {
+	if utf8.RuneCountInString(text) <= excerptLength {
+		return text
+	}
+	runes := []rune(text)[:excerptLength]
+	cut := string(runes)
+	if i := strings.LastIndexAny(cut, " \n"); i > excerptLength/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
+
+// icsWriter writes iCalendar content lines, keeping the first error.
+type icsWriter struct {
+	w   io.Writer
+	err error
+}
+
+// text writes a property whose value is escaped as iCalendar TEXT.
+func (c *icsWriter) text(name, value string) {
+	c.line(name, strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(value))
+}
+
+// line writes a property, folding it into lines of at most 75 octets as RFC
+// 5545 requires, without splitting UTF-8 sequences.
+func (c *icsWriter) line(name, value string) {
+	if c.err != nil {
+		return
+	}
+	rest := name + ":" + value
+	var b strings.Builder
+	limit := 75
+	for len(rest) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(rest[cut]) {
+			cut--
+		}
+		b.WriteString(rest[:cut] + "\r\n ")
+		rest = rest[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	b.WriteString(rest + "\r\n")
+	_, c.err = io.WriteString(c.w, b.String())
+}
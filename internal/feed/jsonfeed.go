@@ -0,0 +1,80 @@
+package feed
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// JSONFeedContentType is the media type of JSON Feed documents written by
+// WriteJSON.
+const JSONFeedContentType = "application/feed+json; charset=utf-8"
+
+// jsonFeedVersion identifies the JSON Feed version written by WriteJSON.
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	DatePublished string               `json:"date_published,omitempty"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL         string `json:"url"`
+	MIMEType    string `json:"mime_type"`
+	Title       string `json:"title,omitempty"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+// WriteJSON writes f as a JSON Feed 1.1 document. Enclosure links become
+// attachments and categories become tags. self is the URL the document is
+// served from and is left out when empty.
+func WriteJSON(w io.Writer, f *Feed, self string) error {
+	doc := jsonFeed{Version: jsonFeedVersion, Title: f.Title, HomePageURL: f.Link, FeedURL: self, Items: []jsonFeedItem{}}
+	for _, e := range f.Entries {
+		item := jsonFeedItem{ID: e.ID, URL: e.Link, Title: e.Title, ContentHTML: e.Content, Tags: e.Categories}
+		if !e.Updated.IsZero() {
+			item.DatePublished = e.Updated.UTC().Format(time.RFC3339)
+		}
+		if e.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: e.Author}}
+		}
+		for _, link := range e.Links {
+			if link.Rel == "enclosure" {
+				item.Attachments = append(item.Attachments, jsonFeedAttachment{URL: link.Href, MIMEType: enclosureType(link), Title: link.Title, SizeInBytes: link.Length})
+			}
+		}
+		doc.Items = append(doc.Items, item)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// enclosureType returns the media type of an enclosure link, which JSON Feed
+// and RSS require.
+func enclosureType(link Link) string {
+	if link.Type == "" {
+		return "application/octet-stream"
+	}
+	return link.Type
+}
@@ -0,0 +1,161 @@
+package feed
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	blankLines     = regexp.MustCompile(`\n{3,}`)
+	trailingSpaces = regexp.MustCompile(`(?m) +$`)
+)
+
+// Markdown converts the HTML release notes of a feed entry back into
+// Markdown. It covers the elements GitHub renders release notes with:
+// headings, paragraphs, lists, links, emphasis, code and block quotes.
+// Unknown elements are reduced to their text.
+func Markdown(content string) string {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+	var b strings.Builder
+	renderChildren(&b, doc, "")
+	markdown := trailingSpaces.ReplaceAllString(b.String(), "")
+	return strings.TrimSpace(blankLines.ReplaceAllString(markdown, "\n\n"))
+}
+
+func renderChildren(b *strings.Builder, n *html.Node, indent string) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		render(b, c, indent)
+	}
+}
+
+func render(b *strings.Builder, n *html.Node, indent string) {
+	switch n.Type {
+	case html.TextNode:
+		text := collapseSpace(n.Data)
+		if current := b.String(); current == "" || strings.HasSuffix(current, "\n") || strings.HasSuffix(current, " ") {
+			text = strings.TrimLeft(text, " ")
+		}
+		b.WriteString(text)
+		return
+	case html.ElementNode:
+	default:
+		renderChildren(b, n, indent)
+		return
+	}
+
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		b.WriteString("\n\n" + strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		renderChildren(b, n, indent)
+		b.WriteString("\n\n")
+	case "p", "div":
+		b.WriteString("\n\n")
+		renderChildren(b, n, indent)
+		b.WriteString("\n\n")
+	case "br":
+		b.WriteString("\n" + indent)
+	case "ul", "ol":
+		b.WriteString("\n")
+		i := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.Data != "li" {
+				continue
+			}
+			i++
+			marker := "- "
+			if n.Data == "ol" {
+				marker = strconv.Itoa(i) + ". "
+			}
+			b.WriteString("\n" + indent + marker)
+			var item strings.Builder
+			renderChildren(&item, c, indent+"  ")
+			b.WriteString(strings.TrimSpace(item.String()))
+		}
+		b.WriteString("\n\n")
+	case "a":
+		var text strings.Builder
+		renderChildren(&text, n, indent)
+		href := attr(n, "href")
+		if href == "" || href == strings.TrimSpace(text.String()) {
+			b.WriteString(text.String())
+		} else {
+			b.WriteString("[" + strings.TrimSpace(text.String()) + "](" + href + ")")
+		}
+	case "strong", "b":
+		wrap(b, n, indent, "**")
+	case "em", "i":
+		wrap(b, n, indent, "_")
+	case "code":
+		b.WriteString("`" + textContent(n) + "`")
+	case "pre":
+		b.WriteString("\n\n```\n" + strings.TrimRight(textContent(n), "\n") + "\n```\n\n")
+	case "blockquote":
+		var quote strings.Builder
+		renderChildren(&quote, n, indent)
+		b.WriteString("\n\n")
+		for _, line := range strings.Split(strings.TrimSpace(quote.String()), "\n") {
+			b.WriteString("> " + line + "\n")
+		}
+		b.WriteString("\n")
+	case "hr":
+		b.WriteString("\n\n---\n\n")
+	case "img":
+		b.WriteString("![" + attr(n, "alt") + "](" + attr(n, "src") + ")")
+	case "script", "style":
+	default:
+		renderChildren(b, n, indent)
+	}
+}
+
+func wrap(b *strings.Builder, n *html.Node, indent, marker string) {
+	var inner strings.Builder
+	renderChildren(&inner, n, indent)
+	if text := strings.TrimSpace(inner.String()); text != "" {
+		b.WriteString(marker + text + marker)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+// collapseSpace collapses runs of whitespace in text to single spaces, as
+// HTML rendering does, keeping one space at either end if there was one.
+func collapseSpace(text string) string {
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if collapsed == "" {
+		if text != "" {
+			return " "
+		}
+		return ""
+	}
+	if strings.TrimLeft(text, " \t\r\n") != text {
+		collapsed = " " + collapsed
+	}
+	if strings.TrimRight(text, " \t\r\n") != text {
+		collapsed += " "
+	}
+	return collapsed
+}
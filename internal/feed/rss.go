@@ -0,0 +1,110 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// RSSContentType is the media type of RSS documents written by WriteRSS.
+const RSSContentType = "application/rss+xml; charset=utf-8"
+
+type rssOutput struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	LastBuildDate string       `xml:"lastBuildDate,omitempty"`
+	Self          *rssAtomLink `xml:"atom:link,omitempty"`
+	Items         []rssItem    `xml:"item"`
+}
+
+// rssAtomLink is the atom:link element RSS feeds use to give their own URL.
+type rssAtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	Description string        `xml:"description,omitempty"`
+	Creator     string        `xml:"dc:creator,omitempty"`
+	Categories  []string      `xml:"category,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes f as an RSS 2.0 document. As RSS allows one enclosure per
+// item, only the first enclosure link of each entry is kept, and authors are
+// written as dc:creator since RSS authors must be email addresses. self is
+// the URL the document is served from and is left out when empty.
+func WriteRSS(w io.Writer, f *Feed, self string) error {
+	channel := rssChannel{Title: f.Title, Link: f.Link, Description: f.Title}
+	if channel.Link == "" {
+		channel.Link = self
+	}
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	if self != "" {
+		channel.Self = &rssAtomLink{Rel: "self", Type: "application/rss+xml", Href: self}
+	}
+	for _, e := range f.Entries {
+		item := rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			Description: e.Content,
+			Creator:     e.Author,
+			Categories:  e.Categories,
+			GUID:        rssGUID{Value: e.ID},
+		}
+		if !e.Updated.IsZero() {
+			item.PubDate = e.Updated.UTC().Format(time.RFC1123Z)
+		}
+		for _, link := range e.Links {
+			if link.Rel == "enclosure" {
+				item.Enclosure = &rssEnclosure{URL: link.Href, Length: link.Length, Type: enclosureType(link)}
+				break
+			}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	doc := rssOutput{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,28 @@
+package feed
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ParseVersion parses a release tag as semver. Monorepo tags such as
+// service/s3/v1.2.0 are parsed from their last path segment, and a
+// non-numeric prefix such as "release-" is skipped. Only dotted versions are
+// accepted after a prefix, so date tags like release-2026-01-15 are rejected.
+func ParseVersion(tag string) (*semver.Version, error) {
+	if version, err := semver.NewVersion(tag); err == nil {
+		return version, nil
+	}
+	if i := strings.LastIndex(tag, "/"); i >= 0 {
+		tag = tag[i+1:]
+		if version, err := semver.NewVersion(tag); err == nil {
+			return version, nil
+		}
+	}
+	if i := strings.IndexAny(tag, "0123456789"); i > 0 && strings.Contains(tag[i:], ".") {
+		return semver.NewVersion(tag[i:])
+	}
+	return nil, fmt.Errorf("%q is not a semantic version", tag)
+}
@@ -0,0 +1,300 @@
+// Package filter selects the entries of a release feed by prerelease status,
+// tag prefix, regular expression, semver constraint and release level.
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+)
+
+// Option names, used both as URL query parameters and as key=value options
+// after the repo on an input line.
+const (
+	KeyPrerelease = "prerelease"
+	KeyPrefix     = "prefix"
+	KeyInclude    = "include"
+	KeyExclude    = "exclude"
+	KeyConstraint = "constraint"
+	KeyLevel      = "level"
+)
+
+// Release levels for Filter.Level, from the fewest releases kept to all.
+const (
+	LevelMajor = "major"
+	LevelMinor = "minor"
+	LevelPatch = "patch"
+)
+
+// prereleaseWord matches the markers used by tags that are not semantic
+// versions to flag a prerelease, such as 2026.01-beta or nightly-20260115.
+var prereleaseWord = regexp.MustCompile(`(?i)(?:^|[^a-z])(?:alpha|beta|rc|pre|preview|nightly|snapshot|dev)(?:[^a-z]|$)`)
+
+// Filter selects release feed entries by their tag. The zero Filter keeps
+// every entry.
+type Filter struct {
+	// NoPrereleases drops prereleases.
+	NoPrereleases bool
+	// Prefixes keeps only tags starting with one of them. The matched prefix
+	// is removed before the tag is compared against Constraint.
+	Prefixes []string
+	// Include keeps only tags matching this regular expression.
+	Include string
+	// Exclude drops tags matching this regular expression.
+	Exclude string
+	// Constraint keeps only tags whose version satisfies this semver
+	// constraint, such as ">=2.0 <3".
+	Constraint string
+	// Level keeps only major releases, such as v2.0.0, with LevelMajor, or
+	// major and minor releases, such as v2.1.0, with LevelMinor. Tags that
+	// are not semantic versions are dropped. LevelPatch or "" keeps all.
+	Level string
+
+	include    *regexp.Regexp
+	exclude    *regexp.Regexp
+	constraint *semver.Constraints
+}
+
+// Parse builds a Filter from query parameters or input line options. Unknown
+// keys, invalid regular expressions and invalid constraints are errors.
+func Parse(values url.Values) (*Filter, error) {
+	f := &Filter{}
+	for key, list := range values {
+		switch key {
+		case KeyPrerelease:
+			keep, err := strconv.ParseBool(list[len(list)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value %q: %w", key, list[len(list)-1], err)
+			}
+			f.NoPrereleases = !keep
+		case KeyPrefix:
+			for _, prefix := range list {
+				if prefix != "" {
+					f.Prefixes = append(f.Prefixes, prefix)
+				}
+			}
+		case KeyInclude:
+			f.Include = list[len(list)-1]
+		case KeyExclude:
+			f.Exclude = list[len(list)-1]
+		case KeyConstraint:
+			f.Constraint = list[len(list)-1]
+		case KeyLevel:
+			f.Level = list[len(list)-1]
+		default:
+			return nil, fmt.Errorf("unknown filter option %q", key)
+		}
+	}
+	if err := f.compile(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Filter) compile() error {
+	var err error
+	if f.Include != "" {
+		if f.include, err = regexp.Compile(f.Include); err != nil {
+			return fmt.Errorf("invalid %s pattern: %w", KeyInclude, err)
+		}
+	}
+	if f.Exclude != "" {
+		if f.exclude, err = regexp.Compile(f.Exclude); err != nil {
+			return fmt.Errorf("invalid %s pattern: %w", KeyExclude, err)
+		}
+	}
+	if f.Constraint != "" {
+		if f.constraint, err = semver.NewConstraint(f.Constraint); err != nil {
+			return fmt.Errorf("invalid %s: %w", KeyConstraint, err)
+		}
+	}
+	switch f.Level {
+	case "", LevelMajor, LevelMinor, LevelPatch:
+	default:
+		return fmt.Errorf("invalid %s %q, expected %s, %s or %s", KeyLevel, f.Level, LevelMajor, LevelMinor, LevelPatch)
+	}
+	return nil
+}
+
+// Values encodes f as query parameters that Parse accepts. Options left at
+// their defaults are omitted, so the zero Filter encodes to no parameters.
+func (f *Filter) Values() url.Values {
+	values := url.Values{}
+	if f == nil {
+		return values
+	}
+	if f.NoPrereleases {
+		values.Set(KeyPrerelease, "false")
+	}
+	for _, prefix := range f.Prefixes {
+		values.Add(KeyPrefix, prefix)
+	}
+	if f.Include != "" {
+		values.Set(KeyInclude, f.Include)
+	}
+	if f.Exclude != "" {
+		values.Set(KeyExclude, f.Exclude)
+	}
+	if f.Constraint != "" {
+		values.Set(KeyConstraint, f.Constraint)
+	}
+	if f.Level != "" && f.Level != LevelPatch {
+		values.Set(KeyLevel, f.Level)
+	}
+	return values
+}
+
+// String formats f as input line options that Cut accepts, quoting values
+// that contain spaces.
+func (f *Filter) String() string {
+	values := f.Values()
+	var options []string
+	for _, key := range []string{KeyPrerelease, KeyPrefix, KeyInclude, KeyExclude, KeyConstraint, KeyLevel} {
+		for _, value := range values[key] {
+			if strings.ContainsAny(value, " \t") {
+				value = `"` + value + `"`
+			}
+			options = append(options, key+"="+value)
+		}
+	}
+	return strings.Join(options, " ")
+}
+
+// Match reports whether the entry passes every option of f.
+func (f *Filter) Match(entry feed.Entry) bool {
+	if f == nil {
+		return true
+	}
+	tag := entry.Tag()
+
+	version := tag
+	if len(f.Prefixes) > 0 {
+		matched := false
+		for _, prefix := range f.Prefixes {
+			if rest, ok := strings.CutPrefix(tag, prefix); ok {
+				version, matched = rest, true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.include != nil && !f.include.MatchString(tag) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.MatchString(tag) {
+		return false
+	}
+
+	parsed, err := feed.ParseVersion(version)
+	if f.NoPrereleases && (IsPrerelease(tag, parsed) || slices.Contains(entry.Categories, feed.CategoryPrerelease)) {
+		return false
+	}
+	if f.constraint != nil && (err != nil || !f.constraint.Check(parsed)) {
+		return false
+	}
+	switch f.Level {
+	case LevelMajor:
+		return err == nil && parsed.Minor() == 0 && parsed.Patch() == 0
+	case LevelMinor:
+		return err == nil && parsed.Patch() == 0
+	}
+	return true
+}
+
+// Apply returns the entries that match f, keeping their order.
+func (f *Filter) Apply(entries []feed.Entry) []feed.Entry {
+	if f == nil {
+		return entries
+	}
+	var kept []feed.Entry
+	for _, entry := range entries {
+		if f.Match(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// IsPrerelease reports whether tag is a prerelease, from the prerelease part
+// of its parsed version or, for tags that are not semantic versions, from
+// markers such as beta or rc in the tag. version may be nil.
+func IsPrerelease(tag string, version *semver.Version) bool {
+	if version != nil {
+		return version.Prerelease() != ""
+	}
+	return prereleaseWord.MatchString(tag)
+}
+
+// Cut splits an input line into the repo and the key=value filter options
+// after it, such as
+//
+//	aws/aws-sdk-go-v2 prefix=service/s3/ prerelease=false constraint=">=1.50 <2"
+//
+// Values containing spaces are double quoted. The Filter is nil when the line
+// has no options.
+func Cut(text string) (string, *Filter, error) {
+	fields, err := splitFields(text)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(fields) == 0 {
+		return "", nil, nil
+	}
+	if len(fields) == 1 {
+		return fields[0], nil, nil
+	}
+
+	values := url.Values{}
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return "", nil, fmt.Errorf("invalid option %q, expected key=value", field)
+		}
+		values.Add(key, value)
+	}
+	f, err := Parse(values)
+	if err != nil {
+		return "", nil, err
+	}
+	return fields[0], f, nil
+}
+
+// splitFields splits text at whitespace outside double quotes and removes
+// the quotes.
+func splitFields(text string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	inField, quoted := false, false
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inField = true
+		case !quoted && (r == ' ' || r == '\t'):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", text)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+)
+
+// release returns a feed entry for tag with GitHub's escaped tag link.
+func release(tag string) feed.Entry {
+	return feed.Entry{Title: tag, Link: "https://github.com/owner/repo/releases/tag/" + url.PathEscape(tag)}
+}
+
+func TestFilterApply(t *testing.T) {
+	tags := []string{"cli/v2.1.0", "sdk/v0.3.0", "cli/v2.0.0-rc.1", "cli/v1.2.0", "nightly-20260115", "2026.01", "v3.0.0", "v3.0.1", "v4.0.0"}
+	var entries []feed.Entry
+	for _, tag := range tags {
+		entries = append(entries, release(tag))
+	}
+	// Marked as a prerelease on GitHub rather than by its tag
+	entries[len(entries)-1].Categories = []string{feed.CategoryPrerelease}
+
+	tests := []struct {
+		name   string
+		values url.Values
+		want   []string
+	}{
+		{name: "No options", values: url.Values{}, want: tags},
+		{name: "No prereleases", values: url.Values{"prerelease": {"false"}}, want: []string{"cli/v2.1.0", "sdk/v0.3.0", "cli/v1.2.0", "2026.01", "v3.0.0", "v3.0.1"}},
+		{name: "Prerelease true", values: url.Values{"prerelease": {"true"}}, want: tags},
+		{name: "Prefixes", values: url.Values{"prefix": {"cli/", "sdk/"}}, want: []string{"cli/v2.1.0", "sdk/v0.3.0", "cli/v2.0.0-rc.1", "cli/v1.2.0"}},
+		{name: "Include", values: url.Values{"include": {`^v\d`}}, want: []string{"v3.0.0", "v3.0.1", "v4.0.0"}},
+		{name: "Exclude", values: url.Values{"exclude": {"^(cli|sdk)/"}}, want: []string{"nightly-20260115", "2026.01", "v3.0.0", "v3.0.1", "v4.0.0"}},
+		{name: "Constraint after prefix", values: url.Values{"prefix": {"cli/"}, "constraint": {">=2.0 <3"}}, want: []string{"cli/v2.1.0"}},
+		{name: "Constraint drops non-semver tags", values: url.Values{"constraint": {">=1"}}, want: []string{"cli/v2.1.0", "cli/v1.2.0", "2026.01", "v3.0.0", "v3.0.1", "v4.0.0"}},
+		{name: "Major releases", values: url.Values{"level": {"major"}}, want: []string{"cli/v2.0.0-rc.1", "v3.0.0", "v4.0.0"}},
+		{name: "Minor releases", values: url.Values{"level": {"minor"}}, want: []string{"cli/v2.1.0", "sdk/v0.3.0", "cli/v2.0.0-rc.1", "cli/v1.2.0", "2026.01", "v3.0.0", "v4.0.0"}},
+		{name: "Stable major releases", values: url.Values{"level": {"major"}, "prerelease": {"false"}}, want: []string{"v3.0.0"}},
+		{name: "Patch releases", values: url.Values{"level": {"patch"}}, want: tags},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(tt.values)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var got []string
+			for _, entry := range f.Apply(entries) {
+				got = append(got, entry.Tag())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, values := range []url.Values{
+		{"flavour": {"vanilla"}},
+		{"prerelease": {"sometimes"}},
+		{"include": {"("}},
+		{"constraint": {"banana"}},
+		{"level": {"huge"}},
+	} {
+		if _, err := Parse(values); err == nil {
+			t.Errorf("Parse(%v) should fail", values)
+		}
+	}
+}
+
+func TestCut(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantRepo    string
+		wantOptions string
+		wantErr     bool
+	}{
+		{name: "Repo only", text: "owner/repo", wantRepo: "owner/repo"},
+		{name: "Options", text: "aws/aws-sdk-go-v2 prefix=service/s3/ prerelease=false", wantRepo: "aws/aws-sdk-go-v2", wantOptions: "prerelease=false prefix=service/s3/"},
+		{name: "Quoted value", text: `owner/repo@v1.2.0  constraint=">=2.0 <3"`, wantRepo: "owner/repo@v1.2.0", wantOptions: `constraint=">=2.0 <3"`},
+		{name: "Missing value", text: "owner/repo prerelease", wantErr: true},
+		{name: "Unterminated quote", text: `owner/repo include="^v`, wantErr: true},
+		{name: "Unknown option", text: "owner/repo flavour=vanilla", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, f, err := Cut(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Cut() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if repo != tt.wantRepo || f.String() != tt.wantOptions {
+				t.Errorf("Cut() = %q, %q, want %q, %q", repo, f.String(), tt.wantRepo, tt.wantOptions)
+			}
+
+			// The options survive a round trip through query parameters
+			if f != nil {
+				parsed, err := Parse(f.Values())
+				if err != nil || parsed.String() != f.String() {
+					t.Errorf("Parse(Values()) = %q, %v, want %q", parsed.String(), err, f.String())
+				}
+			}
+		})
+	}
+}
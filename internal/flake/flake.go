@@ -0,0 +1,114 @@
+// Package flake reads the github inputs locked in a Nix flake.lock file.
+package flake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// Input is a flake input hosted on GitHub.
+type Input struct {
+	// Name is the input's node name in the lock file.
+	Name string
+	// Repo is the owner/repo the input is fetched from.
+	Repo string
+	// Direct reports whether the root flake declares the input itself.
+	Direct bool
+	Line   int
+}
+
+// LockFileName is the name of the lock file written by nix flake lock.
+const LockFileName = "flake.lock"
+
+// Find returns the flake.lock files below dir.
+func Find(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".git" || d.Name() == "result") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == LockFileName {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+type lockFile struct {
+	Nodes map[string]struct {
+		Inputs   map[string]json.RawMessage `json:"inputs"`
+		Locked   source                     `json:"locked"`
+		Original source                     `json:"original"`
+	} `json:"nodes"`
+	Root string `json:"root"`
+}
+
+type source struct {
+	Type  string `json:"type"`
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+}
+
+// Parse returns the inputs of type github in a flake.lock file, sorted by
+// name. Inputs of other types such as git, path or tarball are left out.
+func Parse(data []byte) ([]Input, error) {
+	var lock lockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	if lock.Root == "" {
+		lock.Root = "root"
+	}
+	root, ok := lock.Nodes[lock.Root]
+	if !ok {
+		return nil, fmt.Errorf("flake.lock has no root node %q", lock.Root)
+	}
+
+	// root inputs map input names to node names, or to a "follows" path
+	direct := make(map[string]bool)
+	for _, target := range root.Inputs {
+		var node string
+		if json.Unmarshal(target, &node) == nil {
+			direct[node] = true
+		}
+	}
+
+	var inputs []Input
+	for name, node := range lock.Nodes {
+		src := node.Locked
+		if src.Type == "" {
+			src = node.Original
+		}
+		if src.Type != "github" || src.Owner == "" || src.Repo == "" {
+			continue
+		}
+		inputs = append(inputs, Input{
+			Name:   name,
+			Repo:   src.Owner + "/" + src.Repo,
+			Direct: direct[name],
+			Line:   lineOf(data, name),
+		})
+	}
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].Name < inputs[j].Name })
+	return inputs, nil
+}
+
+// lineOf returns the line of the node object for name in a flake.lock file.
+func lineOf(data []byte, name string) int {
+	needle := []byte(`"` + name + `": {`)
+	if i := bytes.Index(data, needle); i >= 0 {
+		return 1 + bytes.Count(data[:i], []byte("\n"))
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package flake
+
+import (
+	"testing"
+)
+
+const testFlakeLock = `{
+  "nodes": {
+    "flake-utils": {
+      "inputs": {
+        "systems": "systems"
+      },
+      "locked": {
+        "lastModified": 1710146030,
+        "owner": "numtide",
+        "repo": "flake-utils",
+        "rev": "b1d9ab70662946ef0850d488da1c9019f3a9752a",
+        "type": "github"
+      },
+      "original": {
+        "owner": "numtide",
+        "repo": "flake-utils",
+        "type": "github"
+      }
+    },
+    "nixpkgs": {
+      "locked": {
+        "owner": "NixOS",
+        "repo": "nixpkgs",
+        "rev": "5672bc9dbf9d88246ddab5ac454e82318d094bb8",
+        "type": "github"
+      },
+      "original": {
+        "owner": "NixOS",
+        "ref": "nixos-unstable",
+        "repo": "nixpkgs",
+        "type": "github"
+      }
+    },
+    "private": {
+      "locked": {
+        "type": "git",
+        "url": "https://git.example.com/private"
+      },
+      "original": {
+        "type": "git",
+        "url": "https://git.example.com/private"
+      }
+    },
+    "root": {
+      "inputs": {
+        "flake-utils": "flake-utils",
+        "nixpkgs": "nixpkgs",
+        "private": "private"
+      }
+    },
+    "systems": {
+      "locked": {
+        "owner": "nix-systems",
+        "repo": "default",
+        "type": "github"
+      },
+      "original": {
+        "owner": "nix-systems",
+        "repo": "default",
+        "type": "github"
+      }
+    }
+  },
+  "root": "root",
+  "version": 7
+}
+`
+
+func TestParse(t *testing.T) {
+	got, err := Parse([]byte(testFlakeLock))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Input{
+		{Name: "flake-utils", Repo: "numtide/flake-utils", Direct: true, Line: 3},
+		{Name: "nixpkgs", Repo: "NixOS/nixpkgs", Direct: true, Line: 20},
+		{Name: "systems", Repo: "nix-systems/default", Line: 51},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Parse() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Parse()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := Parse([]byte(`{"nodes": {}, "root": "root"}`)); err == nil {
+		t.Error("Parse() should fail without a root node")
+	}
+}
@@ -0,0 +1,140 @@
+package ghreleases2rss
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spf13/cobra"
+	"github.com/toozej/ghreleases2rss/internal/digest"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/notify"
+	"github.com/toozej/ghreleases2rss/internal/server"
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// Digest fetches the release feeds of the repos in the --file sources once
+// and writes a digest of the releases published since --since, grouped by
+// repo and limited to the [Section] named by --category, as Markdown or HTML
+// to stdout or the --output file. With --smtp-addr the digest is emailed
+// instead, unless --output is given as well.
+func Digest(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get digest window and category from flags
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	category, _ := cmd.Flags().GetString("category")
+
+	// Get output options from flags
+	output, _ := cmd.Flags().GetString("output")
+	format, _ := cmd.Flags().GetString("format")
+	title, _ := cmd.Flags().GetString("title")
+
+	// Get SMTP options from flags
+	smtpAddr, _ := cmd.Flags().GetString("smtp-addr")
+	smtpFrom, _ := cmd.Flags().GetString("smtp-from")
+	smtpTo, _ := cmd.Flags().GetStringArray("smtp-to")
+
+	now := time.Now()
+	since, err := digest.ParseSince(sinceFlag, now)
+	if err != nil {
+		log.Fatalf("Error parsing --since: %v", err)
+	}
+	if format == "" {
+		format = digest.FormatMarkdown
+		if ext := strings.ToLower(filepath.Ext(output)); ext == ".html" || ext == ".htm" {
+			format = digest.FormatHTML
+		}
+	}
+	if smtpAddr != "" && (smtpFrom == "" || len(smtpTo) == 0) {
+		log.Fatal("--smtp-addr requires --smtp-from and at least one --smtp-to")
+	}
+
+	s := newServer(cmd, conf)
+	if err := s.Refresh(cmd.Context()); err != nil {
+		log.Fatalf("Error fetching release feeds: %v", err)
+	}
+	repos, err := digestRepos(s.Categories(), category)
+	if err != nil {
+		log.Fatalf("Error selecting category: %v", err)
+	}
+
+	d := &digest.Digest{Title: title, Since: since, Until: now}
+	if d.Title == "" {
+		d.Title = digest.DefaultTitle
+		if category != "" {
+			d.Title += ": " + category
+		}
+	}
+	for _, repo := range repos {
+		entries, err := s.Releases(repo)
+		if err != nil {
+			log.Fatalf("Error reading releases of %s: %v", repo.Name, err)
+		}
+		d.Add(repo.Name, entries)
+	}
+
+	var body bytes.Buffer
+	if err := d.Write(&body, format); err != nil {
+		log.Fatalf("Error writing digest: %v", err)
+	}
+
+	if smtpAddr != "" {
+		if d.Count() == 0 {
+			log.Infof("No releases since %s, not sending a digest", since.Format(time.DateOnly))
+		} else {
+			contentType := "text/plain"
+			if format == digest.FormatHTML {
+				contentType = "text/html"
+			}
+			sink := &notify.SMTP{Addr: smtpAddr, From: smtpFrom, To: smtpTo, Username: conf.SMTPUsername, Password: conf.SMTPPassword}
+			if err := sink.Mail(cmd.Context(), d.Subject(), contentType, body.String()); err != nil {
+				log.Fatalf("Error sending digest: %v", err)
+			}
+			log.Infof("Sent digest of %d releases to %s", d.Count(), strings.Join(smtpTo, ", "))
+		}
+		if output == "" {
+			return
+		}
+	}
+
+	if output == "" || output == input.Stdin {
+		if _, err := cmd.OutOrStdout().Write(body.Bytes()); err != nil {
+			log.Fatalf("Error writing digest: %v", err)
+		}
+		return
+	}
+	if err := os.WriteFile(output, body.Bytes(), 0600); err != nil {
+		log.Fatalf("Error writing digest: %v", err)
+	}
+}
+
+// digestRepos returns the distinct repos of categories, or of the category
+// whose name or slug is category when it is set.
+func digestRepos(categories []server.Category, category string) ([]server.Repo, error) {
+	var repos []server.Repo
+	found := category == ""
+	for _, c := range categories {
+		if category != "" && c.Slug != server.Slug(category) {
+			continue
+		}
+		found = true
+		for _, repo := range c.Repos {
+			if !slices.ContainsFunc(repos, func(r server.Repo) bool { return r.Name == repo.Name }) {
+				repos = append(repos, repo)
+			}
+		}
+	}
+	if !found {
+		var names []string
+		for _, c := range categories {
+			names = append(names, c.Name)
+		}
+		return nil, fmt.Errorf("unknown category %q, expected one of %s", category, strings.Join(names, ", "))
+	}
+	return repos, nil
+}
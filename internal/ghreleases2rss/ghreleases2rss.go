@@ -1,28 +1,66 @@
 package ghreleases2rss
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
+	"github.com/toozej/ghreleases2rss/internal/filter"
 	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/junit"
 	"github.com/toozej/ghreleases2rss/internal/miniflux"
+	"github.com/toozej/ghreleases2rss/internal/server"
 	"github.com/toozej/ghreleases2rss/pkg/config"
 )
 
+// Failure types recorded in JUnit reports for input lines that could not be processed.
+const (
+	failureParse     = "ParseError"
+	failureMissing   = "MissingRepo"
+	failureFetch     = "FetchError"
+	failureSubscribe = "SubscribeError"
+)
+
+// lineFailure is an error for a single input line, tagged with its failure type.
+type lineFailure struct {
+	kind string
+	err  error
+}
+
+func (f *lineFailure) Error() string {
+	return f.err.Error()
+}
+
+// Run reads the input sources given with --file and subscribes to the release
+// feed of every repository they list.
 func Run(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get input sources from flag
+	sources, _ := cmd.Flags().GetStringArray("file")
+
+	// Read every input source before changing anything in Miniflux
+	lines, err := input.Read(sources, inputOptions(cmd, conf))
+	if err != nil {
+		log.Fatalf("Error reading input: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+}
+
+// Subscribe subscribes to the release feed of every input line in Miniflux,
+// optionally clearing the target category first. Every source command feeds
+// its repositories through here so they share deduplication and reporting.
+func Subscribe(cmd *cobra.Command, conf config.Config, lines []input.Line) {
 	// Get Miniflux API URL endpoint and API Key from config
 	minifluxAPIKey := conf.MinifluxAPIKey
 	minifluxURL := conf.MinifluxURL
 
-	// Get input file from flag
-	filePath, _ := cmd.Flags().GetString("file")
-
 	// Get category from flag
 	category, _ := cmd.Flags().GetString("category")
 
@@ -32,6 +70,12 @@ func Run(cmd *cobra.Command, args []string, conf config.Config) {
 	// Get debug from flag
 	debug, _ := cmd.Flags().GetBool("debug")
 
+	// Get JUnit report path from flag
+	junitPath, _ := cmd.Flags().GetString("junit")
+
+	// Get feed proxy URL from flag
+	proxyURL, _ := cmd.Flags().GetString("proxy-url")
+
 	// Validate the category if provided
 	var categoryID int
 	if category != "" {
@@ -58,74 +102,165 @@ func Run(cmd *cobra.Command, args []string, conf config.Config) {
 		}
 	}
 
-	// Open the input file securely (prevent directory traversal)
-	file, err := openFileSecurely(filePath)
-	if err != nil {
-		log.Fatalf("Error opening file: %v", err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		repo := strings.TrimSpace(scanner.Text())
-		if repo == "" {
-			continue
+	report := junit.NewReport("ghreleases2rss sync")
+	processLines(lines, proxyURL, report, func(releaseFeed string) error {
+		// Subscribe to the feed in Miniflux with optional category
+		if debug {
+			log.Debug("Pretending to subscribe to feed: ", releaseFeed)
+			return nil
 		}
-
-		// Validate and parse the GitHub repository
-		releaseFeed, err := github.GetReleaseFeedURL(repo)
+		err := miniflux.SubscribeToFeed(minifluxURL, minifluxAPIKey, categoryID, releaseFeed)
 		if err != nil {
-			log.Printf("Error processing repo '%s': %v", repo, err)
-			continue
+			log.Printf("Failed to subscribe to feed %s: %v", releaseFeed, err)
+			return &lineFailure{kind: failureSubscribe, err: err}
 		}
+		return nil
+	})
 
-		// Subscribe to the feed in Miniflux with optional category
-		if debug {
-			log.Debug("Pretending to subscribe to feed: ", releaseFeed)
-		} else {
-			err = miniflux.SubscribeToFeed(minifluxURL, minifluxAPIKey, categoryID, releaseFeed)
-			if err != nil {
-				log.Printf("Failed to subscribe to feed %s: %v", releaseFeed, err)
+	writeReport(report, junitPath)
+}
+
+// Validate checks every input line without subscribing to anything.
+// Each line must parse as a GitHub repository whose release feed exists.
+func Validate(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get input sources from flag
+	sources, _ := cmd.Flags().GetStringArray("file")
+
+	// Get JUnit report path from flag
+	junitPath, _ := cmd.Flags().GetString("junit")
+
+	lines, err := input.Read(sources, inputOptions(cmd, conf))
+	if err != nil {
+		log.Fatalf("Error reading input: %v", err)
+	}
+
+	report := junit.NewReport("ghreleases2rss validate")
+	failures := processLines(lines, "", report, func(releaseFeed string) error {
+		if err := github.CheckReleaseFeed(releaseFeed); err != nil {
+			log.Printf("Error checking release feed %s: %v", releaseFeed, err)
+			if errors.Is(err, github.ErrRepoNotFound) {
+				return &lineFailure{kind: failureMissing, err: err}
 			}
+			return &lineFailure{kind: failureFetch, err: err}
 		}
-	}
+		return nil
+	})
+
+	writeReport(report, junitPath)
 
-	if err := scanner.Err(); err != nil {
-		log.Error("Error reading file: ", err)
+	if failures > 0 {
+		log.Fatalf("Validation failed for %d input lines", failures)
 	}
+	log.Info("All input lines are valid")
 }
 
-// openFileSecurely opens a file with path traversal protection
-func openFileSecurely(filePath string) (*os.File, error) {
-	// Get current working directory for secure file operations
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("error getting current working directory: %w", err)
-	}
+// requestTimeout bounds outgoing HTTP requests so an unresponsive host
+// cannot hang a command.
+const requestTimeout = 30 * time.Second
 
-	// Resolve absolute path for the file
-	absFilePath, err := filepath.Abs(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("error resolving file path: %w", err)
+// newHTTPClient returns an HTTP client whose requests time out after
+// requestTimeout.
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// inputOptions returns the options for reading input sources from the configuration.
+func inputOptions(cmd *cobra.Command, conf config.Config) input.Options {
+	return input.Options{
+		Stdin:       cmd.InOrStdin(),
+		Token:       conf.InputToken,
+		CacheDir:    cacheDir(conf),
+		AllowedDirs: conf.AllowedDirs,
+		HTTPClient:  newHTTPClient(),
 	}
+}
 
-	// Get absolute path for current directory
-	absCwd, err := filepath.Abs(cwd)
-	if err != nil {
-		return nil, fmt.Errorf("error resolving current directory: %w", err)
+// cacheDir returns the configured cache directory, defaulting to a
+// ghreleases2rss directory in the user cache directory.
+func cacheDir(conf config.Config) string {
+	if conf.CacheDir != "" {
+		return conf.CacheDir
+	}
+	if userCacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(userCacheDir, "ghreleases2rss")
 	}
+	return ""
+}
+
+// processLines resolves the release feed of each input line and calls handle
+// once per distinct feed, so the same repo listed in several sources is only
+// processed once. When proxyURL is set, feeds point at the filtering proxy
+// served there with the line's filter options encoded in the URL; otherwise
+// filter options are ignored. The outcome of every line, including skipped
+// duplicates, is recorded in report under its source. It returns the number
+// of failed lines.
+func processLines(lines []input.Line, proxyURL string, report *junit.Report, handle func(releaseFeed string) error) int {
+	failures := 0
+	seen := make(map[string]input.Line)
+	for _, line := range lines {
+		start := time.Now()
+
+		// Validate and parse the GitHub repository and its filter options
+		releaseFeed, err := lineFeedURL(line.Text, proxyURL)
+		if err != nil {
+			log.Printf("Error processing repo '%s' (%s:%d): %v", line.Text, line.Source, line.Number, err)
+			failures++
+			report.Fail(line.Source, line.Number, line.Text, failureParse, err, time.Since(start))
+			continue
+		}
+
+		if first, ok := seen[releaseFeed]; ok {
+			reason := fmt.Sprintf("duplicate of %s:%d", first.Source, first.Number)
+			log.Debugf("Skipping repo '%s' (%s:%d): %s", line.Text, line.Source, line.Number, reason)
+			report.Skip(line.Source, line.Number, line.Text, reason)
+			continue
+		}
+		seen[releaseFeed] = line
+
+		err = handle(releaseFeed)
+		if err == nil {
+			report.Pass(line.Source, line.Number, line.Text, time.Since(start))
+			continue
+		}
 
-	// Check if the file is within allowed directories (current directory or subdirectories)
-	relPath, err := filepath.Rel(absCwd, absFilePath)
-	if err != nil || strings.HasPrefix(relPath, "..") {
-		return nil, fmt.Errorf("file path traversal detected or file outside allowed directory")
+		failures++
+		kind := failureParse
+		var lf *lineFailure
+		if errors.As(err, &lf) {
+			kind = lf.kind
+		}
+		report.Fail(line.Source, line.Number, line.Text, kind, err, time.Since(start))
 	}
 
-	// Open the file - gosec G304 is acceptable here as we have directory traversal protection above
-	file, err := os.Open(absFilePath) // #nosec G304
+	return failures
+}
+
+// lineFeedURL returns the release feed URL of an input line, on the proxy at
+// proxyURL when it is set.
+func lineFeedURL(text, proxyURL string) (string, error) {
+	text, f, err := filter.Cut(text)
 	if err != nil {
-		return nil, fmt.Errorf("error opening file: %w", err)
+		return "", err
+	}
+	releaseFeed, err := github.GetReleaseFeedURL(text)
+	if err != nil || proxyURL == "" {
+		if f != nil && err == nil {
+			log.Warnf("Ignoring filter options '%s' of %s without --proxy-url", f, text)
+		}
+		return releaseFeed, err
 	}
+	repo, _ := github.ParseRepo(text)
+	return server.ProxyURL(proxyURL, repo, f), nil
+}
 
-	return file, nil
+// writeReport writes report as JUnit XML when a report path was requested.
+func writeReport(report *junit.Report, path string) {
+	if path == "" {
+		return
+	}
+	if err := report.WriteFile(path); err != nil {
+		log.Errorf("Error writing JUnit report: %v", err)
+		return
+	}
+	log.Info("Wrote JUnit report to ", path)
 }
@@ -0,0 +1,132 @@
+package ghreleases2rss
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spf13/cobra"
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/filter"
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/outdated"
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// Outdated prints how far each pinned owner/repo@version input line is
+// behind the repo's releases and writes an upgrade brief per outdated repo
+// to the --brief-dir directory.
+func Outdated(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get input sources from flag
+	sources, _ := cmd.Flags().GetStringArray("file")
+
+	// Get upgrade brief directory from flag
+	briefDir, _ := cmd.Flags().GetString("brief-dir")
+
+	lines, err := input.Read(sources, inputOptions(cmd, conf))
+	if err != nil {
+		log.Fatalf("Error reading input: %v", err)
+	}
+
+	table := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "REPO\tCURRENT\tLATEST\tBEHIND")
+
+	client := newHTTPClient()
+	seen := make(map[string]input.Line)
+	unpinned := 0
+	for _, line := range lines {
+		text, f, err := filter.Cut(line.Text)
+		if err != nil {
+			log.Errorf("Error processing repo '%s' (%s:%d): %v", line.Text, line.Source, line.Number, err)
+			continue
+		}
+		spec, version := github.SplitPin(text)
+		if version == "" {
+			unpinned++
+			continue
+		}
+		releaseFeed, err := github.GetReleaseFeedURL(spec)
+		if err != nil {
+			log.Errorf("Error processing repo '%s' (%s:%d): %v", line.Text, line.Source, line.Number, err)
+			continue
+		}
+		repo, _ := github.ParseRepo(spec)
+		if first, ok := seen[repo]; ok {
+			log.Warnf("Ignoring repeated pin '%s' (%s:%d), already pinned at %s:%d", line.Text, line.Source, line.Number, first.Source, first.Number)
+			continue
+		}
+		seen[repo] = line
+
+		releases, err := feed.Fetch(client, releaseFeed)
+		if err != nil {
+			log.Errorf("Error fetching releases of %s: %v", repo, err)
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", repo, version, "?", "?")
+			continue
+		}
+		result, err := outdated.Compare(version, f.Apply(releases.Entries))
+		if err != nil {
+			log.Warnf("Unable to compare %s: %v", repo, err)
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", repo, version, "-", "-")
+			continue
+		}
+		if result.ByDate {
+			log.Debugf("Compared %s by release date as %s is not a semantic version", repo, version)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", repo, result.Current, result.Latest, result.BehindString())
+
+		if briefDir != "" && result.Behind > 0 {
+			if err := writeBrief(briefDir, repo, result); err != nil {
+				log.Errorf("Error writing upgrade brief for %s: %v", repo, err)
+			}
+		}
+	}
+
+	if err := table.Flush(); err != nil {
+		log.Errorf("Error writing table: %v", err)
+	}
+	if unpinned > 0 {
+		log.Infof("Skipped %d input lines without a pinned @version", unpinned)
+	}
+}
+
+// briefSegment matches owner and repo names that are safe to use as file
+// names.
+var briefSegment = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// briefPath returns the path of the upgrade brief of repo, dir/owner/repo.md.
+// Names that could point outside dir are rejected.
+func briefPath(dir, repo string) (string, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	for _, segment := range []string{owner, name} {
+		if !ok || !briefSegment.MatchString(segment) || strings.Trim(segment, ".") == "" {
+			return "", fmt.Errorf("unsupported repo name %q", repo)
+		}
+	}
+	path := filepath.Join(dir, owner, name+".md")
+	if rel, err := filepath.Rel(dir, path); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("upgrade brief of %s resolves outside %s", repo, dir)
+	}
+	return path, nil
+}
+
+// writeBrief writes the upgrade brief of repo to dir/owner/repo.md.
+func writeBrief(dir, repo string, result outdated.Result) error {
+	path, err := briefPath(dir, repo)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(outdated.Brief(repo, result)), 0600); err != nil {
+		return err
+	}
+	log.Debugf("Wrote upgrade brief %s", path)
+	return nil
+}
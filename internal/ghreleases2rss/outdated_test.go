@@ -0,0 +1,40 @@
+package ghreleases2rss
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+func TestBriefPath(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{line: "owner/repo@v1.0.0", want: filepath.Join(dir, "owner", "repo.md")},
+		{line: "https://github.com/owner/repo.go@v1.0.0", want: filepath.Join(dir, "owner", "repo.go.md")},
+		{line: "https://github.com/a/../../../tmp/pwn@v1.0.0", wantErr: true},
+		{line: "owner/..@v1.0.0", wantErr: true},
+		{line: "../repo@v1.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			spec, _ := github.SplitPin(tt.line)
+			repo, err := github.ParseRepo(spec)
+			if err != nil {
+				repo = spec
+			}
+			got, err := briefPath(dir, repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("briefPath(%q) error = %v, wantErr %v", repo, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("briefPath(%q) = %s, want %s", repo, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package ghreleases2rss
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spf13/cobra"
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/gomod"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/sbom"
+	"github.com/toozej/ghreleases2rss/internal/scan"
+	"github.com/toozej/ghreleases2rss/internal/tools"
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// Scan walks each directory given in args, reads every manifest it detects
+// with the matching source and subscribes to the combined set of repos. With
+// --print it writes that set as an input file instead of subscribing.
+func Scan(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get walk options from flags
+	include, _ := cmd.Flags().GetStringArray("include")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	noGitignore, _ := cmd.Flags().GetBool("no-gitignore")
+
+	// Get print mode and its output path from flags
+	printOnly, _ := cmd.Flags().GetBool("print")
+	output, _ := cmd.Flags().GetString("output")
+
+	opts := scan.Options{
+		Include:     include,
+		Exclude:     exclude,
+		NoGitignore: noGitignore,
+		ReadFile: func(path string) ([]byte, error) {
+			return input.ReadFile(path, conf.AllowedDirs)
+		},
+	}
+
+	lines, err := scanLines(cmd, conf, args, opts)
+	if err != nil {
+		log.Fatalf("Error scanning: %v", err)
+	}
+
+	if !printOnly {
+		Subscribe(cmd, conf, lines)
+		return
+	}
+
+	out := cmd.OutOrStdout()
+	if output != "" && output != input.Stdin {
+		file, err := os.Create(output) // #nosec G304 -- output path is given by the user
+		if err != nil {
+			log.Fatalf("Error creating %s: %v", output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := writeInputFile(out, lines, "ghreleases2rss scan "+strings.Join(args, " ")); err != nil {
+		log.Fatalf("Error writing input file: %v", err)
+	}
+}
+
+// scanLines detects the manifests below roots and returns the input lines of
+// all of them, grouped by kind in source order.
+func scanLines(cmd *cobra.Command, conf config.Config, roots []string, opts scan.Options) ([]input.Line, error) {
+	byKind := make(map[scan.Kind][]string)
+	for _, root := range roots {
+		files, err := scan.Walk(root, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			log.Debugf("Detected %s as %s", file.Path, file.Kind)
+			byKind[file.Kind] = append(byKind[file.Kind], file.Path)
+		}
+	}
+
+	var lines []input.Line
+	add := func(kindLines []input.Line, err error) error {
+		lines = append(lines, kindLines...)
+		return err
+	}
+	if paths := byKind[scan.KindInput]; len(paths) > 0 {
+		if err := add(input.Read(paths, inputOptions(cmd, conf))); err != nil {
+			return nil, err
+		}
+	}
+	if paths := byKind[scan.KindGoMod]; len(paths) > 0 {
+		if err := add(goModLines(paths, conf.AllowedDirs, gomod.NewResolver(), false)); err != nil {
+			return nil, err
+		}
+	}
+	if paths := byKind[scan.KindWorkflows]; len(paths) > 0 {
+		if err := add(workflowLines(paths, conf.AllowedDirs)); err != nil {
+			return nil, err
+		}
+	}
+	if paths := byKind[scan.KindImages]; len(paths) > 0 {
+		resolver := imageResolver(conf)
+		if err := add(imageLines(paths, conf.AllowedDirs, resolver)); err != nil {
+			return nil, err
+		}
+		if err := resolver.Save(); err != nil {
+			log.Warnf("Unable to save image lookup cache: %v", err)
+		}
+	}
+	if paths := byKind[scan.KindPackages]; len(paths) > 0 {
+		pkgLines, unresolved, err := packageLines(paths, conf.AllowedDirs, packageResolver(conf))
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range unresolved {
+			log.Warnf("Unable to map %s package %s (%s:%d) to a GitHub repo: %v", u.pkg.Ecosystem, u.pkg.Name, u.source, u.pkg.Line, u.err)
+		}
+		lines = append(lines, pkgLines...)
+	}
+	if paths := byKind[scan.KindSBOM]; len(paths) > 0 {
+		resolver := &sbomResolver{modules: gomod.NewResolver(), packages: packageResolver(conf)}
+		if err := add(sbomLines(paths, conf.AllowedDirs, sbom.Filter{}, resolver)); err != nil {
+			return nil, err
+		}
+	}
+	if paths := byKind[scan.KindTerraform]; len(paths) > 0 {
+		if err := add(terraformLines(paths, conf.AllowedDirs, terraformResolver(conf))); err != nil {
+			return nil, err
+		}
+	}
+	if paths := byKind[scan.KindFlake]; len(paths) > 0 {
+		if err := add(flakeLines(paths, conf.AllowedDirs, false)); err != nil {
+			return nil, err
+		}
+	}
+	if paths := byKind[scan.KindTools]; len(paths) > 0 {
+		registry, err := tools.NewRegistry()
+		if err != nil {
+			return nil, err
+		}
+		pinnedLines, unmapped, err := toolLines(paths, conf.AllowedDirs, registry)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range unmapped {
+			log.Warnf("No GitHub repo known for %s tool %s (%s:%d)", u.tool.Manager, u.tool.Name, u.source, u.tool.Line)
+		}
+		lines = append(lines, pinnedLines...)
+	}
+
+	log.Infof("Found %d repo references in %d manifests", len(lines), countFiles(byKind))
+	return lines, nil
+}
+
+func countFiles(byKind map[scan.Kind][]string) int {
+	count := 0
+	for _, paths := range byKind {
+		count += len(paths)
+	}
+	return count
+}
+
+// writeInputFile writes the distinct repos of lines as an input file, sorted
+// by repo, each preceded by a comment listing the sources it came from.
+// Lines that do not name a GitHub repo are logged and left out.
+func writeInputFile(w io.Writer, lines []input.Line, generatedBy string) error {
+	sources := make(map[string][]string)
+	var repos []string
+	for _, line := range lines {
+		repo, err := github.ParseRepo(line.Text)
+		if err != nil {
+			log.Warnf("Leaving out '%s' (%s:%d): %v", line.Text, line.Source, line.Number, err)
+			continue
+		}
+		if _, ok := sources[repo]; !ok {
+			repos = append(repos, repo)
+		}
+		sources[repo] = append(sources[repo], fmt.Sprintf("%s:%d", line.Source, line.Number))
+	}
+	sort.Slice(repos, func(i, j int) bool { return strings.ToLower(repos[i]) < strings.ToLower(repos[j]) })
+
+	if _, err := fmt.Fprintf(w, "# Generated by %s\n", generatedBy); err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if _, err := fmt.Fprintf(w, "\n# %s\n%s\n", strings.Join(sources[repo], ", "), repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package ghreleases2rss
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/scan"
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+func TestScanLines(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".gitignore"), "dist/\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\nrequire github.com/spf13/cobra v1.10.2\n")
+	writeFile(t, filepath.Join(dir, ".github", "workflows", "ci.yml"), "jobs:\n  build:\n    steps:\n      - uses: actions/checkout@v4\n")
+	writeFile(t, filepath.Join(dir, "repos.txt"), "# watched repos\nowner/listed # release notes only\nhttps://github.com/owner/linked\n\n[Tools]\nowner/pinned@v1.2.0 prerelease=false\n")
+	writeFile(t, filepath.Join(dir, ".tool-versions"), "act 0.2.60\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a list of repos\n")
+	writeFile(t, filepath.Join(dir, "vendor", "go.mod"), "module example.com/vendored\n\nrequire github.com/owner/vendored v1.0.0\n")
+	writeFile(t, filepath.Join(dir, "dist", "go.mod"), "module example.com/dist\n\nrequire github.com/owner/generated v1.0.0\n")
+
+	conf := config.Config{AllowedDirs: []string{dir}, CacheDir: t.TempDir()}
+	tests := []struct {
+		name string
+		opts scan.Options
+		want []string
+	}{
+		{
+			// Input lists come first and keep their pins and filter options,
+			// then every other kind follows in source order
+			name: "Every detected manifest",
+			opts: scan.Options{Exclude: []string{"vendor/"}},
+			want: []string{"owner/listed", "https://github.com/owner/linked", "owner/pinned@v1.2.0 prerelease=false", "spf13/cobra", "actions/checkout", "nektos/act"},
+		},
+		{
+			name: "Include globs",
+			opts: scan.Options{Include: []string{"go.mod", "*.txt"}, Exclude: []string{"vendor/"}},
+			want: []string{"owner/listed", "https://github.com/owner/linked", "owner/pinned@v1.2.0 prerelease=false", "spf13/cobra"},
+		},
+		{
+			name: "Gitignored paths",
+			opts: scan.Options{Include: []string{"go.mod"}, NoGitignore: true},
+			want: []string{"owner/generated", "spf13/cobra", "owner/vendored"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.ReadFile = func(path string) ([]byte, error) {
+				return input.ReadFile(path, conf.AllowedDirs)
+			}
+			got, err := scanLines(&cobra.Command{}, conf, []string{dir}, opts)
+			if err != nil {
+				t.Fatalf("scanLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(texts(got), tt.want) {
+				t.Errorf("scanLines() = %v, want %v", texts(got), tt.want)
+			}
+			// Input lists keep their section headers
+			for _, line := range got {
+				wantSection := ""
+				if line.Text == "owner/pinned@v1.2.0 prerelease=false" {
+					wantSection = "Tools"
+				}
+				if line.Section != wantSection {
+					t.Errorf("scanLines() section of %s = %q, want %q", line.Text, line.Section, wantSection)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteInputFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []input.Line
+		want  string
+	}{
+		{
+			name: "Repos are deduped and sorted with their sources",
+			lines: []input.Line{
+				{Source: "go.mod", Number: 3, Text: "spf13/cobra"},
+				{Source: "ci.yml", Number: 5, Text: "actions/checkout"},
+				{Source: "ci.yml", Number: 9, Text: "actions/checkout"},
+			},
+			want: "# Generated by test\n\n# ci.yml:5, ci.yml:9\nactions/checkout\n\n# go.mod:3\nspf13/cobra\n",
+		},
+		{
+			name: "Pins and filter options are dropped",
+			lines: []input.Line{
+				{Source: "repos.txt", Number: 1, Text: "owner/pinned@v1.2.0 prerelease=false"},
+				{Source: "go.mod", Number: 4, Text: "owner/pinned"},
+			},
+			want: "# Generated by test\n\n# repos.txt:1, go.mod:4\nowner/pinned\n",
+		},
+		{
+			name: "Lines without a repo are left out",
+			lines: []input.Line{
+				{Source: "repos.txt", Number: 1, Text: "not-a-repo"},
+				{Source: "repos.txt", Number: 2, Text: "https://github.com/Owner/Repo"},
+			},
+			want: "# Generated by test\n\n# repos.txt:2\nOwner/Repo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeInputFile(&buf, tt.lines, "test"); err != nil {
+				t.Fatalf("writeInputFile() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeInputFile() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
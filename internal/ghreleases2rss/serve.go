@@ -0,0 +1,136 @@
+package ghreleases2rss
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spf13/cobra"
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/enrich"
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/imagetags"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/server"
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// Serve fetches the release feeds of the repos in the --file sources in the
+// background and serves them merged per category over HTTP, along with the
+// feed proxy, advisories and image tag feeds, until it is interrupted.
+func Serve(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get listen address, refresh interval and polling window from flags
+	listen, _ := cmd.Flags().GetString("listen")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	spread, _ := cmd.Flags().GetDuration("spread")
+
+	// Get feed limit, classification and proxy scope from flags
+	limit, _ := cmd.Flags().GetInt("limit")
+	classifyReleases, _ := cmd.Flags().GetBool("classify")
+	proxyAny, _ := cmd.Flags().GetBool("proxy-any")
+
+	// Get image tag options from flags
+	registries, _ := cmd.Flags().GetStringArray("image-registry")
+	images, _ := cmd.Flags().GetStringArray("image")
+	maxAge, _ := cmd.Flags().GetDuration("max-age")
+
+	s := newServer(cmd, conf)
+	s.Interval = interval
+	s.Spread = spread
+	s.Limit = limit
+	s.Classify = classifyReleases
+	s.ProxyAny = proxyAny
+	s.ImageTags = &imagetags.Tracker{
+		Client:     newOCIClient(),
+		Dir:        cacheDir(conf),
+		Registries: registries,
+		Images:     images,
+		MaxAge:     maxAge,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go s.Run(ctx)
+
+	httpServer := &http.Server{Addr: listen, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Errorf("Error shutting down server: %v", err)
+		}
+	}()
+
+	log.Infof("Serving release feeds on %s, refreshing every %s", listen, interval)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Error serving feeds: %v", err)
+	}
+}
+
+// newServer returns a server for the repos in the --file sources that fetches
+// feeds and GitHub API responses through the cache directory. It exits when
+// the input cannot be read.
+func newServer(cmd *cobra.Command, conf config.Config) *server.Server {
+	// Get input sources from flag
+	sources, _ := cmd.Flags().GetStringArray("file")
+
+	// Get cache options from flags
+	maxAge, _ := cmd.Flags().GetDuration("max-age")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+	// Get feed options from flags
+	defaultCategory, _ := cmd.Flags().GetString("default-category")
+	enrichReleases, _ := cmd.Flags().GetBool("enrich")
+
+	opts := inputOptions(cmd, conf)
+	var stdinLines []input.Line
+	load := func() ([]server.Category, error) {
+		if len(sources) == 0 {
+			return nil, nil
+		}
+		// Stdin can only be read once, so its lines are kept for every refresh
+		if stdinLines != nil {
+			return server.Categories(stdinLines, defaultCategory), nil
+		}
+		lines, err := input.Read(sources, opts)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(sources, input.Stdin) {
+			log.Info("Input includes stdin, so it will not be re-read on refresh")
+			stdinLines = lines
+		}
+		return server.Categories(lines, defaultCategory), nil
+	}
+
+	// Fail early on unreadable input rather than serving nothing
+	if _, err := load(); err != nil {
+		log.Fatalf("Error reading input: %v", err)
+	}
+
+	newCache := func() *cache.Cache {
+		return &cache.Cache{
+			Dir:     cacheDir(conf),
+			Client:  &http.Client{Timeout: 30 * time.Second},
+			MaxAge:  maxAge,
+			PerHost: concurrency,
+		}
+	}
+	// The API client gets its own cache, as the token is sent with every request
+	api := github.NewAPI(conf.GitHubAPIURL, conf.GitHubToken, newCache())
+	s := &server.Server{Load: load, Cache: newCache(), API: api}
+	if enrichReleases {
+		if conf.GitHubToken == "" {
+			log.Warn("GITHUB_TOKEN is not set, so enriching releases is limited to 60 API requests per hour")
+		}
+		s.Enricher = &enrich.Enricher{API: api}
+	}
+	return s
+}
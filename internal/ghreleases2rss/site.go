@@ -0,0 +1,33 @@
+package ghreleases2rss
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spf13/cobra"
+	"github.com/toozej/ghreleases2rss/internal/site"
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// BuildSite fetches the release feeds of the repos in the --file sources
+// once and writes a static release dashboard, with merged feeds and an OPML
+// subscription list, to the --output directory.
+func BuildSite(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get output directory from flag
+	output, _ := cmd.Flags().GetString("output")
+
+	// Get site options from flags
+	title, _ := cmd.Flags().GetString("title")
+	baseURL, _ := cmd.Flags().GetString("base-url")
+	proxyURL, _ := cmd.Flags().GetString("proxy-url")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	s := newServer(cmd, conf)
+	if err := s.Refresh(cmd.Context()); err != nil {
+		log.Fatalf("Error fetching release feeds: %v", err)
+	}
+
+	opts := site.Options{Title: title, Limit: limit, BaseURL: baseURL, ProxyURL: proxyURL}
+	if err := site.Build(output, s, opts); err != nil {
+		log.Fatalf("Error building site: %v", err)
+	}
+}
@@ -0,0 +1,495 @@
+package ghreleases2rss
+
+import (
+	"fmt"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spf13/cobra"
+	"github.com/toozej/ghreleases2rss/internal/flake"
+	"github.com/toozej/ghreleases2rss/internal/gomod"
+	"github.com/toozej/ghreleases2rss/internal/images"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/oci"
+	"github.com/toozej/ghreleases2rss/internal/packages"
+	"github.com/toozej/ghreleases2rss/internal/sbom"
+	"github.com/toozej/ghreleases2rss/internal/terraform"
+	"github.com/toozej/ghreleases2rss/internal/tools"
+	"github.com/toozej/ghreleases2rss/internal/workflows"
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// FromGoMod subscribes to the release feeds of the GitHub repositories that
+// host the modules required by each go.mod file given in args.
+func FromGoMod(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get skip-indirect from flag
+	skipIndirect, _ := cmd.Flags().GetBool("skip-indirect")
+
+	lines, err := goModLines(args, conf.AllowedDirs, gomod.NewResolver(), skipIndirect)
+	if err != nil {
+		log.Fatalf("Error reading go.mod: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+}
+
+// goModLines returns one input line per GitHub repository required by the
+// go.mod files in paths. Modules that cannot be mapped to GitHub are logged.
+func goModLines(paths []string, allowedDirs []string, resolver *gomod.Resolver, skipIndirect bool) ([]input.Line, error) {
+	var lines []input.Line
+	for _, path := range paths {
+		data, err := input.ReadFile(path, allowedDirs)
+		if err != nil {
+			return nil, err
+		}
+		modules, err := gomod.Parse(path, data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, module := range modules {
+			if skipIndirect && module.Indirect {
+				log.Debugf("Skipping indirect dependency %s", module.Path)
+				continue
+			}
+			repo, err := resolver.Resolve(module.Path)
+			if err != nil {
+				log.Warnf("Unable to map module %s (%s:%d) to a GitHub repo: %v", module.Path, path, module.Line, err)
+				continue
+			}
+			lines = append(lines, input.Line{Source: path, Number: module.Line, Text: repo})
+		}
+	}
+	return lines, nil
+}
+
+// FromWorkflows subscribes to the release feeds of the third-party GitHub
+// Actions and reusable workflows used below each directory given in args.
+func FromWorkflows(cmd *cobra.Command, args []string, conf config.Config) {
+	lines, err := workflowLines(args, conf.AllowedDirs)
+	if err != nil {
+		log.Fatalf("Error reading workflows: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+}
+
+// workflowLines returns one input line per `uses:` reference in the workflow
+// and composite action files found below dirs.
+func workflowLines(dirs []string, allowedDirs []string) ([]input.Line, error) {
+	var lines []input.Line
+	for _, dir := range dirs {
+		files, err := workflows.Find(dir)
+		if err != nil {
+			return nil, err
+		}
+		log.Debugf("Found %d workflow and action files in %s", len(files), dir)
+
+		for _, path := range files {
+			data, err := input.ReadFile(path, allowedDirs)
+			if err != nil {
+				return nil, err
+			}
+			actions, err := workflows.Parse(data)
+			if err != nil {
+				log.Warnf("Skipping unparseable workflow %s: %v", path, err)
+				continue
+			}
+			for _, action := range actions {
+				lines = append(lines, input.Line{Source: path, Number: action.Line, Text: action.Repo})
+			}
+		}
+	}
+	return lines, nil
+}
+
+// FromImages subscribes to the release feeds of the upstream repositories of
+// the container images used in Dockerfiles, Compose files and Kubernetes
+// manifests below each path given in args.
+func FromImages(cmd *cobra.Command, args []string, conf config.Config) {
+	resolver := imageResolver(conf)
+	lines, err := imageLines(args, conf.AllowedDirs, resolver)
+	if err != nil {
+		log.Fatalf("Error reading image references: %v", err)
+	}
+	if err := resolver.Save(); err != nil {
+		log.Warnf("Unable to save image lookup cache: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+}
+
+// newOCIClient returns a registry client whose requests time out after
+// requestTimeout.
+func newOCIClient() *oci.Client {
+	return &oci.Client{HTTPClient: newHTTPClient()}
+}
+
+// imageResolver returns an image resolver that caches registry lookups in the cache directory.
+func imageResolver(conf config.Config) *images.Resolver {
+	resolver := &images.Resolver{Registry: newOCIClient()}
+	if dir := cacheDir(conf); dir != "" {
+		resolver.CachePath = filepath.Join(dir, "images.json")
+	}
+	return resolver
+}
+
+// imageLines returns one input line per image reference found in the files
+// below paths. Images that cannot be mapped to GitHub are logged.
+func imageLines(paths []string, allowedDirs []string, resolver *images.Resolver) ([]input.Line, error) {
+	var lines []input.Line
+	for _, root := range paths {
+		files, err := images.Find(root)
+		if err != nil {
+			return nil, err
+		}
+		log.Debugf("Found %d Dockerfiles and manifests in %s", len(files), root)
+
+		for _, path := range files {
+			data, err := input.ReadFile(path, allowedDirs)
+			if err != nil {
+				return nil, err
+			}
+			refs, err := images.Parse(path, data)
+			if err != nil {
+				log.Debugf("Skipping unparseable file %s: %v", path, err)
+				continue
+			}
+			for _, ref := range refs {
+				repo, err := resolver.Resolve(ref.Ref)
+				if err != nil {
+					log.Warnf("Unable to map image %s (%s:%d) to a GitHub repo: %v", ref.Ref, path, ref.Line, err)
+					continue
+				}
+				lines = append(lines, input.Line{Source: path, Number: ref.Line, Text: repo})
+			}
+		}
+	}
+	return lines, nil
+}
+
+// unresolvedPackage is a dependency that could not be mapped to a GitHub repository.
+type unresolvedPackage struct {
+	pkg    packages.Package
+	source string
+	err    error
+}
+
+// FromPackages subscribes to the release feeds of the GitHub repositories of
+// the npm, PyPI and crates.io dependencies declared in the manifests below
+// each path given in args. Dependencies that cannot be mapped are listed
+// separately once subscribing is done.
+func FromPackages(cmd *cobra.Command, args []string, conf config.Config) {
+	lines, unresolved, err := packageLines(args, conf.AllowedDirs, packageResolver(conf))
+	if err != nil {
+		log.Fatalf("Error reading package manifests: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+
+	if len(unresolved) > 0 {
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Unresolved packages (%d):\n", len(unresolved))
+		for _, u := range unresolved {
+			fmt.Fprintf(out, "  %s %s (%s:%d): %v\n", u.pkg.Ecosystem, u.pkg.Name, u.source, u.pkg.Line, u.err)
+		}
+	}
+}
+
+// packageResolver returns a package resolver for the configured registries.
+func packageResolver(conf config.Config) *packages.Resolver {
+	return &packages.Resolver{
+		NPMURL:    conf.NPMRegistryURL,
+		PyPIURL:   conf.PyPIURL,
+		CratesURL: conf.CratesURL,
+		Client:    newHTTPClient(),
+	}
+}
+
+// packageLines returns one input line per dependency that maps to a GitHub
+// repository, and the dependencies that do not, for the manifests below paths.
+func packageLines(paths []string, allowedDirs []string, resolver *packages.Resolver) ([]input.Line, []unresolvedPackage, error) {
+	var lines []input.Line
+	var unresolved []unresolvedPackage
+	for _, root := range paths {
+		files, err := packages.Find(root)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Debugf("Found %d package manifests in %s", len(files), root)
+
+		for _, path := range files {
+			data, err := input.ReadFile(path, allowedDirs)
+			if err != nil {
+				return nil, nil, err
+			}
+			pkgs, err := packages.Parse(path, data)
+			if err != nil {
+				log.Warnf("Skipping unparseable manifest %s: %v", path, err)
+				continue
+			}
+			for _, pkg := range pkgs {
+				repo, err := resolver.Resolve(pkg)
+				if err != nil {
+					log.Debugf("Unable to map %s package %s to a GitHub repo: %v", pkg.Ecosystem, pkg.Name, err)
+					unresolved = append(unresolved, unresolvedPackage{pkg: pkg, source: path, err: err})
+					continue
+				}
+				lines = append(lines, input.Line{Source: path, Number: pkg.Line, Text: repo})
+			}
+		}
+	}
+	return lines, unresolved, nil
+}
+
+// FromSBOM subscribes to the release feeds of the GitHub repositories of the
+// components in each CycloneDX or SPDX JSON SBOM given in args, optionally
+// limited to the component types and scopes given with --type and --scope.
+func FromSBOM(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get component filters from flags
+	types, _ := cmd.Flags().GetStringSlice("type")
+	scopes, _ := cmd.Flags().GetStringSlice("scope")
+
+	resolver := &sbomResolver{modules: gomod.NewResolver(), packages: packageResolver(conf)}
+	lines, err := sbomLines(args, conf.AllowedDirs, sbom.Filter{Types: types, Scopes: scopes}, resolver)
+	if err != nil {
+		log.Fatalf("Error reading SBOM: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+}
+
+// sbomResolver maps SBOM components to GitHub repositories, falling back to
+// the Go module and package registry resolvers for purls that do not name
+// a GitHub repository themselves.
+type sbomResolver struct {
+	modules  *gomod.Resolver
+	packages *packages.Resolver
+}
+
+func (r *sbomResolver) Resolve(component sbom.Component) (string, error) {
+	if repo, ok := component.Repo(); ok {
+		return repo, nil
+	}
+
+	purl, err := sbom.ParsePURL(component.PURL)
+	if err != nil {
+		return "", fmt.Errorf("no GitHub reference or usable purl: %w", err)
+	}
+	switch purl.Type {
+	case "golang":
+		return r.modules.Resolve(purl.Path())
+	case "npm":
+		return r.packages.Resolve(packages.Package{Ecosystem: packages.NPM, Name: purl.Path()})
+	case "pypi":
+		return r.packages.Resolve(packages.Package{Ecosystem: packages.PyPI, Name: purl.Name})
+	case "cargo":
+		return r.packages.Resolve(packages.Package{Ecosystem: packages.Crates, Name: purl.Name})
+	}
+	return "", fmt.Errorf("no GitHub reference for %s purl", purl.Type)
+}
+
+// sbomLines returns one input line per component of the SBOMs in paths that
+// passes filter and maps to a GitHub repository. Other components are logged.
+func sbomLines(paths []string, allowedDirs []string, filter sbom.Filter, resolver *sbomResolver) ([]input.Line, error) {
+	var lines []input.Line
+	for _, path := range paths {
+		data, err := input.ReadFile(path, allowedDirs)
+		if err != nil {
+			return nil, err
+		}
+		components, err := sbom.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, component := range components {
+			if !filter.Match(component) {
+				log.Debugf("Skipping %s component %s with scope %q", component.Type, component.Name, component.Scope)
+				continue
+			}
+			repo, err := resolver.Resolve(component)
+			if err != nil {
+				log.Warnf("Unable to map component %s (%s:%d) to a GitHub repo: %v", component.Name, path, component.Line, err)
+				continue
+			}
+			lines = append(lines, input.Line{Source: path, Number: component.Line, Text: repo})
+		}
+	}
+	return lines, nil
+}
+
+// FromTerraform subscribes to the release feeds of the GitHub repositories of
+// the providers pinned in the .terraform.lock.hcl files below each path given
+// in args, looked up in the provider registry.
+func FromTerraform(cmd *cobra.Command, args []string, conf config.Config) {
+	lines, err := terraformLines(args, conf.AllowedDirs, terraformResolver(conf))
+	if err != nil {
+		log.Fatalf("Error reading Terraform lock files: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+}
+
+// terraformResolver returns a provider resolver for the configured registry.
+func terraformResolver(conf config.Config) *terraform.Resolver {
+	return &terraform.Resolver{Endpoint: conf.TerraformRegistryURL, Client: newHTTPClient()}
+}
+
+// terraformLines returns one input line per provider in the lock files below
+// paths. Providers that cannot be mapped to GitHub are logged.
+func terraformLines(paths []string, allowedDirs []string, resolver *terraform.Resolver) ([]input.Line, error) {
+	var lines []input.Line
+	for _, root := range paths {
+		files, err := terraform.Find(root)
+		if err != nil {
+			return nil, err
+		}
+		log.Debugf("Found %d Terraform lock files in %s", len(files), root)
+
+		for _, path := range files {
+			data, err := input.ReadFile(path, allowedDirs)
+			if err != nil {
+				return nil, err
+			}
+			providers, err := terraform.Parse(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			for _, provider := range providers {
+				repo, err := resolver.Resolve(provider)
+				if err != nil {
+					log.Warnf("Unable to map provider %s (%s:%d) to a GitHub repo: %v", provider.Address(), path, provider.Line, err)
+					continue
+				}
+				lines = append(lines, input.Line{Source: path, Number: provider.Line, Text: repo})
+			}
+		}
+	}
+	return lines, nil
+}
+
+// FromFlake subscribes to the release feeds of the github inputs locked in
+// the flake.lock files below each path given in args.
+func FromFlake(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get direct-only from flag
+	directOnly, _ := cmd.Flags().GetBool("direct-only")
+
+	lines, err := flakeLines(args, conf.AllowedDirs, directOnly)
+	if err != nil {
+		log.Fatalf("Error reading flake.lock: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+}
+
+// flakeLines returns one input line per github input in the flake.lock files
+// below paths, optionally only for inputs the root flake declares itself.
+func flakeLines(paths []string, allowedDirs []string, directOnly bool) ([]input.Line, error) {
+	var lines []input.Line
+	for _, root := range paths {
+		files, err := flake.Find(root)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range files {
+			data, err := input.ReadFile(path, allowedDirs)
+			if err != nil {
+				return nil, err
+			}
+			inputs, err := flake.Parse(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			for _, in := range inputs {
+				if directOnly && !in.Direct {
+					log.Debugf("Skipping transitive flake input %s", in.Name)
+					continue
+				}
+				lines = append(lines, input.Line{Source: path, Number: in.Line, Text: in.Repo})
+			}
+		}
+	}
+	return lines, nil
+}
+
+// unmappedTool is a tool that has no GitHub repository in the tool registry.
+type unmappedTool struct {
+	tool   tools.Tool
+	source string
+}
+
+// FromTools subscribes to the release feeds of the CLIs pinned in the
+// .tool-versions, mise.toml, aqua.yaml and Brewfile manifests below each path
+// given in args. Tools are mapped through the built-in tool registry, extended
+// by the files given with --registry, and unmapped tools are listed afterwards.
+func FromTools(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get registry overrides from flag
+	registryPaths, _ := cmd.Flags().GetStringArray("registry")
+
+	var overrides [][]byte
+	for _, path := range registryPaths {
+		data, err := input.ReadFile(path, conf.AllowedDirs)
+		if err != nil {
+			log.Fatalf("Error reading tool registry: %v", err)
+		}
+		overrides = append(overrides, data)
+	}
+	registry, err := tools.NewRegistry(overrides...)
+	if err != nil {
+		log.Fatalf("Error loading tool registry: %v", err)
+	}
+
+	lines, unmapped, err := toolLines(args, conf.AllowedDirs, registry)
+	if err != nil {
+		log.Fatalf("Error reading tool manifests: %v", err)
+	}
+
+	Subscribe(cmd, conf, lines)
+
+	if len(unmapped) > 0 {
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Unmapped tools (%d), add them to a --registry file:\n", len(unmapped))
+		for _, u := range unmapped {
+			fmt.Fprintf(out, "  %s %s (%s:%d)\n", u.tool.Manager, u.tool.Name, u.source, u.tool.Line)
+		}
+	}
+}
+
+// toolLines returns one input line per tool in the manifests below paths that
+// the registry maps to GitHub, and the tools it does not map.
+func toolLines(paths []string, allowedDirs []string, registry *tools.Registry) ([]input.Line, []unmappedTool, error) {
+	var lines []input.Line
+	var unmapped []unmappedTool
+	for _, root := range paths {
+		files, err := tools.Find(root)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Debugf("Found %d tool manifests in %s", len(files), root)
+
+		for _, path := range files {
+			data, err := input.ReadFile(path, allowedDirs)
+			if err != nil {
+				return nil, nil, err
+			}
+			pinned, err := tools.Parse(path, data)
+			if err != nil {
+				log.Warnf("Skipping unparseable tool manifest %s: %v", path, err)
+				continue
+			}
+			for _, tool := range pinned {
+				repo, ok := registry.Resolve(tool)
+				if !ok {
+					log.Debugf("No GitHub repo known for %s tool %s", tool.Manager, tool.Name)
+					unmapped = append(unmapped, unmappedTool{tool: tool, source: path})
+					continue
+				}
+				lines = append(lines, input.Line{Source: path, Number: tool.Line, Text: repo})
+			}
+		}
+	}
+	return lines, unmapped, nil
+}
@@ -0,0 +1,651 @@
+package ghreleases2rss
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/gomod"
+	"github.com/toozej/ghreleases2rss/internal/images"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/packages"
+	"github.com/toozej/ghreleases2rss/internal/sbom"
+	"github.com/toozej/ghreleases2rss/internal/terraform"
+	"github.com/toozej/ghreleases2rss/internal/tools"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// texts returns the text of each line.
+func texts(lines []input.Line) []string {
+	var got []string
+	for _, line := range lines {
+		got = append(got, line.Text)
+	}
+	return got
+}
+
+func TestGoModLines(t *testing.T) {
+	vanity := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/golang.org/x/net":
+			fmt.Fprint(w, `<meta name="go-import" content="golang.org/x/net git https://go.googlesource.com/net">
+<meta name="go-source" content="golang.org/x/net https://github.com/golang/net/ https://github.com/golang/net/tree/master{/dir}">`)
+		case "/go.example.com/tool":
+			fmt.Fprint(w, `<meta name="go-import" content="go.example.com/tool git https://github.com/example/tool.git">`)
+		case "/example.com/gitlab":
+			fmt.Fprint(w, `<meta name="go-import" content="example.com/gitlab git https://gitlab.com/example/gitlab">`)
+		case "/example.com/nometa":
+			fmt.Fprint(w, `<html><head><title>No meta tags</title></head></html>`)
+		case "/example.com/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vanity.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "go.mod")
+	writeFile(t, path, `module example.com/app
+
+go 1.26
+
+require (
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/net v0.30.0
+	go.example.com/tool v1.2.0
+	github.com/owner/mono/v2 v2.1.0
+	github.com/owner/mono/sdk v0.3.0
+	example.com/private v1.0.0
+	example.com/gitlab v1.0.0
+	example.com/nometa v1.0.0
+	example.com/broken v1.0.0
+	github.com/sirupsen/logrus v1.9.3 // indirect
+)
+`)
+
+	tests := []struct {
+		name         string
+		skipIndirect bool
+		want         []string
+	}{
+		// Versions pinned in go.mod are not carried over, so the lines
+		// subscribe to the repos rather than pin them. Major version and
+		// subdirectory modules map to their repo, and vanity paths that are
+		// missing, broken, without meta tags or hosted elsewhere are left out
+		{name: "All modules", want: []string{"spf13/cobra", "golang/net", "example/tool", "owner/mono", "owner/mono", "sirupsen/logrus"}},
+		{name: "Skip indirect", skipIndirect: true, want: []string{"spf13/cobra", "golang/net", "example/tool", "owner/mono", "owner/mono"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &gomod.Resolver{BaseURL: vanity.URL}
+			got, err := goModLines([]string{path}, []string{dir}, resolver, tt.skipIndirect)
+			if err != nil {
+				t.Fatalf("goModLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(texts(got), tt.want) {
+				t.Errorf("goModLines() = %v, want %v", texts(got), tt.want)
+			}
+			if got[0].Source != path || got[0].Number != 6 {
+				t.Errorf("goModLines() first line from %s:%d, want %s:6", got[0].Source, got[0].Number, path)
+			}
+		})
+	}
+
+	if _, err := goModLines([]string{path}, []string{t.TempDir()}, gomod.NewResolver(), false); err == nil {
+		t.Error("goModLines() should refuse go.mod files outside the allowed directories")
+	}
+}
+
+func TestWorkflowLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "Steps and reusable workflows",
+			files: map[string]string{".github/workflows/ci.yml": `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: github/codeql-action/init@3b1e2c7f0d9a8e6b5c4d3e2f1a0b9c8d7e6f5a4b
+      - uses: actions/checkout@v4
+  release:
+    uses: owner/workflows/.github/workflows/release.yml@main
+`},
+			// Refs and paths are dropped and repeated uses are kept, so every
+			// reference can be traced back to its file; subscribing dedupes them
+			want: []string{"actions/checkout", "github/codeql-action", "actions/checkout", "owner/workflows"},
+		},
+		{
+			name: "Local, docker and dynamic references",
+			files: map[string]string{".github/workflows/ci.yml": `jobs:
+  build:
+    steps:
+      - uses: ./.github/actions/local
+      - uses: ../shared/action
+      - uses: docker://alpine:3.20
+      - uses: ${{ matrix.action }}@v1
+      - uses: actions/cache
+      - uses: actions/setup-node@v4
+`},
+			want: []string{"actions/setup-node"},
+		},
+		{
+			name: "Composite actions",
+			files: map[string]string{
+				"actions/setup/action.yml": `runs:
+  using: composite
+  steps:
+    - uses: actions/setup-go@v5
+`,
+				"node_modules/dep/action.yml": "runs:\n  steps:\n    - uses: owner/vendored@v1\n",
+			},
+			want: []string{"actions/setup-go"},
+		},
+		{
+			name: "Other YAML files and unparseable workflows",
+			files: map[string]string{
+				"docs/example.yml":          "steps:\n  - uses: owner/example@v1\n",
+				".github/workflows/bad.yml": "jobs: [\n",
+				".github/dependabot.yml":    "updates:\n  - uses: owner/dependabot@v1\n",
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			got, err := workflowLines([]string{dir}, []string{dir})
+			if err != nil {
+				t.Fatalf("workflowLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(texts(got), tt.want) {
+				t.Errorf("workflowLines() = %v, want %v", texts(got), tt.want)
+			}
+		})
+	}
+
+	// Lines point at the uses: key they came from
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".github", "workflows", "ci.yml")
+	writeFile(t, path, "jobs:\n  build:\n    steps:\n      - uses: actions/checkout@v4\n")
+	got, err := workflowLines([]string{dir}, []string{dir})
+	if err != nil {
+		t.Fatalf("workflowLines() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Source != path || got[0].Number != 4 {
+		t.Errorf("workflowLines() = %+v, want actions/checkout from %s:4", got, path)
+	}
+}
+
+func TestImageLines(t *testing.T) {
+	// Registry lookups come from the cache, so no registry is contacted
+	cachePath := filepath.Join(t.TempDir(), "images.json")
+	cache, err := json.Marshal(map[string]any{
+		"docker.io/library/golang":   map[string]any{"repo": "golang/go", "checked": time.Now()},
+		"docker.io/grafana/grafana":  map[string]any{"repo": "grafana/grafana", "checked": time.Now()},
+		"docker.io/library/postgres": map[string]any{"repo": "", "checked": time.Now()},
+		"quay.io/prometheus/node":    map[string]any{"repo": "prometheus/node_exporter", "checked": time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, cachePath, string(cache))
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "Dockerfile stages",
+			files: map[string]string{"Dockerfile": `ARG BASE=alpine:3.20
+FROM golang:1.26 AS build
+FROM --platform=$BUILDPLATFORM build AS test
+FROM ${BASE}
+FROM scratch
+FROM ghcr.io/owner/runtime:1.0
+`},
+			want: []string{"golang/go", "ghcr.io/owner/runtime"},
+		},
+		{
+			// Names without a registry are on Docker Hub, in library/ when
+			// they have no namespace either
+			name: "Images without a registry",
+			files: map[string]string{"compose.yaml": `services:
+  app:
+    image: golang@sha256:0000000000000000000000000000000000000000000000000000000000000000
+  dashboards:
+    image: grafana/grafana:11.0.0
+  db:
+    image: postgres:17
+`},
+			want: []string{"golang/go", "grafana/grafana"},
+		},
+		{
+			name: "Helm values and templates",
+			files: map[string]string{"values.yaml": `exporter:
+  image:
+    registry: quay.io
+    repository: prometheus/node
+    tag: v1.8.0
+sidecar:
+  image: "{{ .Values.sidecar.repository }}:{{ .Values.sidecar.tag }}"
+`},
+			want: []string{"prometheus/node_exporter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			got, err := imageLines([]string{dir}, []string{dir}, &images.Resolver{CachePath: cachePath})
+			if err != nil {
+				t.Fatalf("imageLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(texts(got), tt.want) {
+				t.Errorf("imageLines() = %v, want %v", texts(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageLines(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/react/latest":
+			fmt.Fprint(w, `{"repository": {"type": "git", "url": "git+https://github.com/facebook/react.git"}}`)
+		case "/preact/latest":
+			fmt.Fprint(w, `{"repository": {"url": "https://github.com/preactjs/preact"}}`)
+		case "/@scope/ui/latest":
+			fmt.Fprint(w, `{"repository": "github:scope/ui"}`)
+		case "/elsewhere/latest":
+			fmt.Fprint(w, `{"repository": "https://gitlab.com/owner/elsewhere", "homepage": "https://elsewhere.example.com"}`)
+		case "/pypi/requests/json":
+			fmt.Fprint(w, `{"info": {"home_page": "https://requests.readthedocs.io", "project_urls": {"Documentation": "https://requests.readthedocs.io", "Source": "https://github.com/psf/requests"}}}`)
+		case "/api/v1/crates/serde":
+			fmt.Fprint(w, `{"crate": {"repository": "https://github.com/serde-rs/serde"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "package.json"), `{
+  "dependencies": {
+    "react": "^18.3.0",
+    "compat": "npm:preact@^10.0.0",
+    "@scope/ui": "2.0.0",
+    "left-pad": "github:owner/left-pad#v1.3.0",
+    "elsewhere": "1.0.0",
+    "missing": "1.0.0"
+  },
+  "devDependencies": {"local": "file:../local", "shared": "workspace:*"}
+}`)
+	writeFile(t, filepath.Join(dir, "requirements.txt"), `-r base.txt
+requests[socks]>=2.31 ; python_version > "3.8"
+tool @ git+https://github.com/owner/tool@v1.0
+https://example.com/archive.tar.gz
+`)
+	writeFile(t, filepath.Join(dir, "crates", "Cargo.toml"), `[dependencies]
+serde = "1.0"
+local = { path = "../local" }
+`)
+	writeFile(t, filepath.Join(dir, "node_modules", "dep", "package.json"), `{"dependencies": {"vendored": "1.0.0"}}`)
+
+	resolver := &packages.Resolver{NPMURL: registry.URL, PyPIURL: registry.URL, CratesURL: registry.URL}
+	got, unresolved, err := packageLines([]string{dir}, []string{dir}, resolver)
+	if err != nil {
+		t.Fatalf("packageLines() error = %v", err)
+	}
+	// Aliases resolve to the package they alias, GitHub specs and VCS URLs
+	// are mapped without a lookup, and local, workspace and vendored
+	// dependencies are neither resolved nor reported
+	want := []string{
+		"serde-rs/serde",
+		"facebook/react", "preactjs/preact", "scope/ui", "owner/left-pad",
+		"psf/requests", "owner/tool",
+	}
+	if !reflect.DeepEqual(texts(got), want) {
+		t.Errorf("packageLines() = %v, want %v", texts(got), want)
+	}
+	var names []string
+	for _, u := range unresolved {
+		names = append(names, u.pkg.Name)
+	}
+	if want := []string{"elsewhere", "missing"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("packageLines() unresolved = %v, want %v", names, want)
+	}
+}
+
+func TestSBOMLines(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/golang.org/x/net":
+			fmt.Fprint(w, `<meta name="go-import" content="golang.org/x/net git https://github.com/golang/net">`)
+		case "/react/latest":
+			fmt.Fprint(w, `{"repository": "github:facebook/react"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+	newResolver := func() *sbomResolver {
+		return &sbomResolver{
+			modules:  &gomod.Resolver{BaseURL: registry.URL},
+			packages: &packages.Resolver{NPMURL: registry.URL},
+		}
+	}
+
+	dir := t.TempDir()
+	cycloneDX := filepath.Join(dir, "sbom.cdx.json")
+	writeFile(t, cycloneDX, `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"type": "library", "name": "cobra", "purl": "pkg:golang/github.com/spf13/cobra@v1.10.2"},
+    {"type": "application", "name": "tool", "scope": "excluded", "purl": "pkg:github/owner/tool@v2.0.0"},
+    {"type": "library", "name": "zlib", "purl": "pkg:generic/zlib@1.3"}
+  ]
+}`)
+
+	tests := []struct {
+		name   string
+		filter sbom.Filter
+		want   []string
+	}{
+		{name: "All components", want: []string{"spf13/cobra", "owner/tool"}},
+		{name: "Libraries", filter: sbom.Filter{Types: []string{"library"}}, want: []string{"spf13/cobra"}},
+		{name: "Excluded scope", filter: sbom.Filter{Scopes: []string{"excluded"}}, want: []string{"owner/tool"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sbomLines([]string{cycloneDX}, []string{dir}, tt.filter, newResolver())
+			if err != nil {
+				t.Fatalf("sbomLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(texts(got), tt.want) {
+				t.Errorf("sbomLines() = %v, want %v", texts(got), tt.want)
+			}
+		})
+	}
+
+	// The package an SPDX document describes is the SBOM's own subject and
+	// left out; the others are mapped through their purl, its vcs_url
+	// qualifier, their download location or a registry lookup
+	spdx := filepath.Join(dir, "sbom.spdx.json")
+	writeFile(t, spdx, `{
+  "spdxVersion": "SPDX-2.3",
+  "documentDescribes": ["SPDXRef-app"],
+  "packages": [
+    {"SPDXID": "SPDXRef-app", "name": "app", "downloadLocation": "https://github.com/example/app"},
+    {"SPDXID": "SPDXRef-net", "name": "net", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:golang/golang.org/x/net@v0.30.0"}]},
+    {"SPDXID": "SPDXRef-react", "name": "react", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:npm/react@18.3.0"}]},
+    {"SPDXID": "SPDXRef-lib", "name": "lib", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:generic/lib@1.0?vcs_url=git%2Bhttps://github.com/owner/lib.git"}]},
+    {"SPDXID": "SPDXRef-curl", "name": "curl", "downloadLocation": "https://github.com/curl/curl/archive/curl-8_9_0.tar.gz"},
+    {"SPDXID": "SPDXRef-zlib", "name": "zlib", "downloadLocation": "NOASSERTION"}
+  ]
+}`)
+	got, err := sbomLines([]string{spdx}, []string{dir}, sbom.Filter{}, newResolver())
+	if err != nil {
+		t.Fatalf("sbomLines() error = %v", err)
+	}
+	if want := []string{"golang/net", "facebook/react", "owner/lib", "curl/curl"}; !reflect.DeepEqual(texts(got), want) {
+		t.Errorf("sbomLines() of SPDX = %v, want %v", texts(got), want)
+	}
+
+	notSBOM := filepath.Join(dir, "package.json")
+	writeFile(t, notSBOM, `{"name": "app"}`)
+	if _, err := sbomLines([]string{notSBOM}, []string{dir}, sbom.Filter{}, newResolver()); err == nil {
+		t.Error("sbomLines() should reject JSON files that are not SBOMs")
+	}
+}
+
+func TestTerraformLines(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/providers/hashicorp/aws":
+			fmt.Fprint(w, `{"source": "https://github.com/hashicorp/terraform-provider-aws"}`)
+		case "/v1/providers/integrations/github":
+			fmt.Fprint(w, `{"source": "https://github.com/integrations/terraform-provider-github"}`)
+		case "/v1/providers/gitlabhq/gitlab":
+			fmt.Fprint(w, `{"source": "https://gitlab.com/gitlab-org/terraform-provider-gitlab"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+
+	dir := t.TempDir()
+	infra := filepath.Join(dir, "infra", ".terraform.lock.hcl")
+	writeFile(t, infra, `# This file is maintained automatically by "terraform init".
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+
+provider "registry.terraform.io/example/internal" {
+  version = "1.0.0"
+}
+
+provider "registry.terraform.io/gitlabhq/gitlab" {
+  version = "17.0.0"
+}
+`)
+	writeFile(t, filepath.Join(dir, "modules", "repo", ".terraform.lock.hcl"), `provider "registry.opentofu.org/integrations/github" {
+  version = "6.2.0"
+}
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version = "5.40.0"
+}
+`)
+	// Lock files of downloaded modules in .terraform are not the project's
+	writeFile(t, filepath.Join(dir, "infra", ".terraform", "modules", "vpc", ".terraform.lock.hcl"), `provider "registry.terraform.io/owner/vendored" {
+  version = "1.0.0"
+}
+`)
+
+	got, err := terraformLines([]string{dir}, []string{dir}, &terraform.Resolver{Endpoint: registry.URL})
+	if err != nil {
+		t.Fatalf("terraformLines() error = %v", err)
+	}
+	// Providers that are unknown or not hosted on GitHub are left out, and
+	// a provider pinned in several lock files is listed once per file
+	want := []string{"hashicorp/terraform-provider-aws", "integrations/terraform-provider-github", "hashicorp/terraform-provider-aws"}
+	if !reflect.DeepEqual(texts(got), want) {
+		t.Errorf("terraformLines() = %v, want %v", texts(got), want)
+	}
+	if got[0].Source != infra || got[0].Number != 3 {
+		t.Errorf("terraformLines() first line from %s:%d, want %s:3", got[0].Source, got[0].Number, infra)
+	}
+
+	invalid := t.TempDir()
+	writeFile(t, filepath.Join(invalid, ".terraform.lock.hcl"), `provider "not/a/valid/address" {
+}
+`)
+	if _, err := terraformLines([]string{invalid}, []string{invalid}, &terraform.Resolver{Endpoint: registry.URL}); err == nil {
+		t.Error("terraformLines() should reject lock files with invalid provider addresses")
+	}
+}
+
+func TestFlakeLines(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "flake.lock"), `{
+  "nodes": {
+    "flake-utils": {
+      "inputs": {"systems": "systems"},
+      "locked": {"owner": "numtide", "repo": "flake-utils", "type": "github"},
+      "original": {"owner": "numtide", "repo": "flake-utils", "type": "github"}
+    },
+    "home-manager": {
+      "inputs": {"nixpkgs": ["nixpkgs"]},
+      "locked": {"owner": "nix-community", "repo": "home-manager", "type": "github"},
+      "original": {"owner": "nix-community", "repo": "home-manager", "type": "github"}
+    },
+    "local": {
+      "locked": {"path": "./local", "type": "path"},
+      "original": {"path": "./local", "type": "path"}
+    },
+    "nixpkgs": {
+      "locked": {"owner": "NixOS", "repo": "nixpkgs", "type": "github"},
+      "original": {"id": "nixpkgs", "type": "indirect"}
+    },
+    "private": {
+      "locked": {"url": "https://git.example.com/private.git", "type": "git"},
+      "original": {"url": "https://git.example.com/private.git", "type": "git"}
+    },
+    "root": {"inputs": {"flake-utils": "flake-utils", "home-manager": "home-manager", "local": "local", "nixpkgs": "nixpkgs", "private": "private"}},
+    "systems": {
+      "locked": {"owner": "nix-systems", "repo": "default", "type": "github"},
+      "original": {"owner": "nix-systems", "repo": "default", "type": "github"}
+    }
+  },
+  "root": "root",
+  "version": 7
+}`)
+
+	tests := []struct {
+		name       string
+		directOnly bool
+		want       []string
+	}{
+		// Inputs locked to GitHub are listed by node name, including
+		// registry inputs such as nixpkgs, while path and git inputs are not
+		{name: "All inputs", want: []string{"numtide/flake-utils", "nix-community/home-manager", "NixOS/nixpkgs", "nix-systems/default"}},
+		{name: "Direct inputs", directOnly: true, want: []string{"numtide/flake-utils", "nix-community/home-manager", "NixOS/nixpkgs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := flakeLines([]string{dir}, []string{dir}, tt.directOnly)
+			if err != nil {
+				t.Fatalf("flakeLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(texts(got), tt.want) {
+				t.Errorf("flakeLines() = %v, want %v", texts(got), tt.want)
+			}
+		})
+	}
+
+	noRoot := t.TempDir()
+	writeFile(t, filepath.Join(noRoot, "flake.lock"), `{"nodes": {}, "root": "root", "version": 7}`)
+	if _, err := flakeLines([]string{noRoot}, []string{noRoot}, false); err == nil {
+		t.Error("flakeLines() should reject lock files without a root node")
+	}
+}
+
+func TestToolLines(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".tool-versions"), "# pinned tools\nact 0.2.60\nunknown-tool 1.0.0\n")
+	writeFile(t, filepath.Join(dir, "mise.toml"), `[env]
+GOFLAGS = "-mod=mod"
+
+[tools]
+"github:owner/cli" = "1.2.0"
+"ubi:owner/ubi-tool[exe=tool]" = "latest"
+"go:github.com/owner/gotool" = "0.1.0"
+jq = ["1.7.1", "1.6"]
+`)
+	writeFile(t, filepath.Join(dir, "ci", "aqua.yaml"), `registries:
+  - type: standard
+    ref: v4.0.0
+packages:
+  - name: cli/cli@v2.40.0
+  - import: aqua/*.yaml
+  - name: kubernetes/kubectl
+    version: v1.29.0
+`)
+	writeFile(t, filepath.Join(dir, "Brewfile"), `tap "hashicorp/tap"
+brew "hashicorp/tap/terraform"
+cask "unknown-app"
+`)
+	// Broken manifests are skipped and vendored ones are not read
+	writeFile(t, filepath.Join(dir, "broken", "mise.toml"), "[tools\n")
+	writeFile(t, filepath.Join(dir, "node_modules", "dep", ".tool-versions"), "node 20.0.0\n")
+
+	tests := []struct {
+		name         string
+		overrides    []string
+		want         []string
+		wantUnmapped []string
+	}{
+		{
+			name:         "Built-in registry",
+			want:         []string{"nektos/act", "hashicorp/terraform", "cli/cli", "kubernetes/kubernetes", "owner/cli", "owner/gotool", "jqlang/jq", "owner/ubi-tool"},
+			wantUnmapped: []string{"unknown-tool", "unknown-app"},
+		},
+		{
+			// Overrides add tools, replace manager-qualified entries and map
+			// tools to "" to ignore them
+			name:         "Registry overrides",
+			overrides:    []string{"tools:\n  unknown-tool: owner/unknown-tool\n  brew:unknown-app: owner/app\n", "tools:\n  act: \"\"\n  aqua:kubernetes/kubectl: kubernetes/kubectl\n"},
+			want:         []string{"owner/unknown-tool", "hashicorp/terraform", "owner/app", "cli/cli", "kubernetes/kubectl", "owner/cli", "owner/gotool", "jqlang/jq", "owner/ubi-tool"},
+			wantUnmapped: []string{"act"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var overrides [][]byte
+			for _, override := range tt.overrides {
+				overrides = append(overrides, []byte(override))
+			}
+			registry, err := tools.NewRegistry(overrides...)
+			if err != nil {
+				t.Fatalf("NewRegistry() error = %v", err)
+			}
+			got, unmapped, err := toolLines([]string{dir}, []string{dir}, registry)
+			if err != nil {
+				t.Fatalf("toolLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(texts(got), tt.want) {
+				t.Errorf("toolLines() = %v, want %v", texts(got), tt.want)
+			}
+			var names []string
+			for _, u := range unmapped {
+				names = append(names, u.tool.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantUnmapped) {
+				t.Errorf("toolLines() unmapped = %v, want %v", names, tt.wantUnmapped)
+			}
+		})
+	}
+
+	if _, err := tools.NewRegistry([]byte("tools:\n  bad: not-a-repo\n")); err == nil {
+		t.Error("NewRegistry() should reject entries that are not owner/repo")
+	}
+}
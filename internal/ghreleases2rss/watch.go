@@ -0,0 +1,110 @@
+package ghreleases2rss
+
+import (
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spf13/cobra"
+	"github.com/toozej/ghreleases2rss/internal/notify"
+	"github.com/toozej/ghreleases2rss/internal/watch"
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// Watch polls the release feeds of the repos in the --file sources every
+// --interval and sends releases published since the last poll to the
+// configured webhook, ntfy topic and email recipients, until it is
+// interrupted. The newest release seen per repo is kept in the --state file,
+// by default in the cache directory.
+func Watch(cmd *cobra.Command, args []string, conf config.Config) {
+	// Get polling options from flags
+	interval, _ := cmd.Flags().GetDuration("interval")
+	statePath, _ := cmd.Flags().GetString("state")
+	once, _ := cmd.Flags().GetBool("once")
+
+	// Get retry options from flags
+	attempts, _ := cmd.Flags().GetInt("retries")
+	backoff, _ := cmd.Flags().GetDuration("retry-backoff")
+
+	sinks := watchSinks(cmd, conf)
+	if len(sinks) == 0 {
+		log.Fatal("No notification sinks configured, set --webhook-url, --ntfy-url or --smtp-addr")
+	}
+	if statePath == "" {
+		statePath = filepath.Join(cacheDir(conf), "watch-state.json")
+	}
+
+	w := &watch.Watcher{
+		Server:    newServer(cmd, conf),
+		StatePath: statePath,
+		Sinks:     sinks,
+		Retry:     notify.Retry{Attempts: attempts, Backoff: backoff},
+		Interval:  interval,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if once {
+		if err := w.Check(ctx); err != nil {
+			log.Fatalf("Error checking for new releases: %v", err)
+		}
+		return
+	}
+	log.Infof("Watching release feeds every %s, state in %s", interval, statePath)
+	w.Run(ctx)
+}
+
+// watchSinks returns the notification sinks configured by flags and the
+// sink credentials in conf.
+func watchSinks(cmd *cobra.Command, conf config.Config) []notify.Sink {
+	// Get webhook options from flags
+	webhookURL, _ := cmd.Flags().GetString("webhook-url")
+	webhookTemplate, _ := cmd.Flags().GetString("webhook-template")
+
+	// Get ntfy options from flags
+	ntfyURL, _ := cmd.Flags().GetString("ntfy-url")
+	ntfyPriority, _ := cmd.Flags().GetInt("ntfy-priority")
+
+	// Get SMTP options from flags
+	smtpAddr, _ := cmd.Flags().GetString("smtp-addr")
+	smtpFrom, _ := cmd.Flags().GetString("smtp-from")
+	smtpTo, _ := cmd.Flags().GetStringArray("smtp-to")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	var sinks []notify.Sink
+	if webhookURL != "" {
+		webhook := &notify.Webhook{URL: webhookURL, Client: client}
+		if webhookTemplate != "" {
+			path, err := config.ResolveAllowedPath(webhookTemplate, conf.AllowedDirs)
+			if err != nil {
+				log.Fatalf("Error reading webhook template: %v", err)
+			}
+			text, err := os.ReadFile(path) // #nosec G304 -- path is checked against the allowed directories
+			if err != nil {
+				log.Fatalf("Error reading webhook template: %v", err)
+			}
+			if webhook.Template, err = notify.ParseTemplate(string(text)); err != nil {
+				log.Fatalf("Error parsing webhook template %s: %v", webhookTemplate, err)
+			}
+		}
+		if conf.WebhookToken != "" {
+			webhook.Header = http.Header{"Authorization": {"Bearer " + conf.WebhookToken}}
+		}
+		sinks = append(sinks, webhook)
+	}
+	if ntfyURL != "" {
+		sinks = append(sinks, &notify.Ntfy{URL: ntfyURL, Token: conf.NtfyToken, Priority: ntfyPriority, Client: client})
+	}
+	if smtpAddr != "" {
+		if smtpFrom == "" || len(smtpTo) == 0 {
+			log.Fatal("--smtp-addr requires --smtp-from and at least one --smtp-to")
+		}
+		sinks = append(sinks, &notify.SMTP{Addr: smtpAddr, From: smtpFrom, To: smtpTo, Username: conf.SMTPUsername, Password: conf.SMTPPassword})
+	}
+	return sinks
+}
@@ -0,0 +1,145 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/cache"
+)
+
+// DefaultAPIURL is the GitHub REST API endpoint.
+const DefaultAPIURL = "https://api.github.com"
+
+// Release is a release as returned by the GitHub REST API.
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name               string `json:"name"`
+	ContentType        string `json:"content_type"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Advisory is a published repository security advisory as returned by the
+// GitHub REST API.
+type Advisory struct {
+	GHSAID      string    `json:"ghsa_id"`
+	CVEID       string    `json:"cve_id"`
+	HTMLURL     string    `json:"html_url"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Severity    string    `json:"severity"`
+	PublishedAt time.Time `json:"published_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CVSS        struct {
+		Score        float64 `json:"score"`
+		VectorString string  `json:"vector_string"`
+	} `json:"cvss"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Vulnerability is a package affected by an advisory.
+type Vulnerability struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	VulnerableVersionRange string `json:"vulnerable_version_range"`
+	PatchedVersions        string `json:"patched_versions"`
+}
+
+// API reads release and advisory data from the GitHub REST API through a cache, so
+// unchanged responses are revalidated with conditional requests, which do not
+// count against the rate limit.
+type API struct {
+	URL   string
+	cache *cache.Cache
+}
+
+// NewAPI returns an API client for the REST API at apiURL, DefaultAPIURL when
+// empty, that authenticates with token when it is set. Responses are kept in
+// c, which should not be shared with requests to other hosts as it is given
+// the token; a memory-only cache is used when c is nil.
+func NewAPI(apiURL, token string, c *cache.Cache) *API {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	if c == nil {
+		c = &cache.Cache{}
+	}
+	if c.Header == nil {
+		c.Header = http.Header{}
+	}
+	c.Header.Set("Accept", "application/vnd.github+json")
+	c.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if token != "" {
+		c.Header.Set("Authorization", "Bearer "+token)
+	}
+	return &API{URL: strings.TrimSuffix(apiURL, "/"), cache: c}
+}
+
+// Releases returns the most recent published releases of repo, newest first.
+// Drafts are left out.
+func (a *API) Releases(ctx context.Context, repo string) ([]Release, error) {
+	var releases []Release
+	if err := a.get(ctx, fmt.Sprintf("/repos/%s/releases?per_page=30", repo), &releases); err != nil {
+		return nil, err
+	}
+	published := releases[:0]
+	for _, release := range releases {
+		if !release.Draft {
+			published = append(published, release)
+		}
+	}
+	return published, nil
+}
+
+// LatestRelease returns the tag of the release GitHub marks as the latest of
+// repo, or "" when it has none.
+func (a *API) LatestRelease(ctx context.Context, repo string) (string, error) {
+	var release Release
+	err := a.get(ctx, fmt.Sprintf("/repos/%s/releases/latest", repo), &release)
+	if errors.Is(err, cache.ErrNotFound) {
+		return "", nil
+	}
+	return release.TagName, err
+}
+
+// Advisories returns the most recently published security advisories of
+// repo, newest first.
+func (a *API) Advisories(ctx context.Context, repo string) ([]Advisory, error) {
+	var advisories []Advisory
+	if err := a.get(ctx, fmt.Sprintf("/repos/%s/security-advisories?state=published&sort=published&direction=desc&per_page=30", repo), &advisories); err != nil {
+		return nil, err
+	}
+	return advisories, nil
+}
+
+func (a *API) get(ctx context.Context, path string, v any) error {
+	resp, err := a.cache.Get(ctx, a.URL+path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resp.Body, v); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", path, err)
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package github
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -12,6 +14,24 @@ import (
 // GetReleaseFeedURL takes a GitHub repo name or URL and returns the RSS feed URL for the releases.
 // It supports full URLs, username/repoName, and GHCR container image URLs.
 func GetReleaseFeedURL(repo string) (string, error) {
+	repo, err := ParseRepo(repo)
+	if err != nil {
+		return "", err
+	}
+
+	log.Info("Repo is set to: ", repo)
+
+	// Construct the GitHub releases RSS feed URL
+	return fmt.Sprintf("https://github.com/%s/releases.atom", repo), nil
+}
+
+// ParseRepo takes a GitHub repo name or URL in any of the forms accepted by
+// GetReleaseFeedURL and returns the repository path used in its feed URL.
+// A pinned version such as owner/repo@v1.4.2 and any options after the repo,
+// such as filter options, are ignored.
+func ParseRepo(repo string) (string, error) {
+	repo, _ = SplitPin(repo)
+
 	var err error
 	switch {
 	// Case for GHCR container image URLs
@@ -49,8 +69,79 @@ func GetReleaseFeedURL(repo string) (string, error) {
 		}
 	}
 
-	log.Info("Repo is set to: ", repo)
+	return repo, nil
+}
 
-	// Construct the GitHub releases RSS feed URL
-	return fmt.Sprintf("https://github.com/%s/releases.atom", repo), nil
+// SplitPin splits a pinned input line such as owner/repo@v1.4.2 into the repo
+// and the version after the @. The version is empty when the line is not pinned.
+// Options following the repo after whitespace are dropped. The version of an
+// owner/repo line may itself contain slashes, as in monorepo tags like
+// owner/repo@service/s3/v1.50.0.
+func SplitPin(text string) (string, string) {
+	if fields := strings.Fields(text); len(fields) > 0 {
+		text = fields[0]
+	}
+	if strings.HasPrefix(text, "git@") {
+		return text, ""
+	}
+	at := strings.LastIndex(text, "@")
+	if !strings.Contains(text, "://") {
+		at = strings.Index(text, "@")
+	}
+	if at <= 0 || at < strings.Index(text, "/") || (strings.Contains(text, "://") && at < strings.LastIndex(text, "/")) {
+		return text, ""
+	}
+	return text[:at], text[at+1:]
+}
+
+// RepoFromURL extracts owner/repo from a github.com URL such as a repository,
+// tree or git clone URL. It reports false for URLs on other hosts.
+func RepoFromURL(rawURL string) (string, bool) {
+	rawURL = strings.TrimPrefix(strings.TrimSpace(rawURL), "git+")
+	if strings.HasPrefix(rawURL, "git@github.com:") {
+		rawURL = "https://github.com/" + strings.TrimPrefix(rawURL, "git@github.com:")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Host != "github.com" && u.Host != "www.github.com") {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git"), true
+}
+
+// ErrRepoNotFound is returned by CheckReleaseFeed when GitHub reports the repository does not exist.
+var ErrRepoNotFound = errors.New("repository not found")
+
+// checkTimeout limits how long CheckReleaseFeed waits for GitHub.
+const checkTimeout = 30 * time.Second
+
+// CheckReleaseFeed verifies that a release feed URL can be fetched, which confirms the repository exists.
+func CheckReleaseFeed(feedURL string) error {
+	req, err := http.NewRequest("HEAD", feedURL, nil)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: checkTimeout}
+	resp, err := client.Do(req) // #nosec G704 -- feedURL is constructed by GetReleaseFeedURL
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrRepoNotFound
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("failed to fetch release feed, status code: %d", resp.StatusCode)
+	}
+
+	log.Debugf("Release feed %s is reachable", feedURL)
+	return nil
 }
@@ -1,6 +1,10 @@
 package github
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -35,6 +39,18 @@ func TestGetReleaseFeedURL(t *testing.T) {
 			want:    "https://github.com/username/repo/releases.atom",
 			wantErr: false,
 		},
+		{
+			name:    "Pinned username/repoName",
+			input:   "username/repo@v1.4.2",
+			want:    "https://github.com/username/repo/releases.atom",
+			wantErr: false,
+		},
+		{
+			name:    "Pinned full GitHub URL",
+			input:   "https://github.com/username/repo@1.0.0",
+			want:    "https://github.com/username/repo/releases.atom",
+			wantErr: false,
+		},
 		{
 			name:    "Invalid GitHub URL",
 			input:   "https://invalid.com/username/repo",
@@ -68,3 +84,140 @@ func TestGetReleaseFeedURL(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitPin(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantRepo    string
+		wantVersion string
+	}{
+		{input: "username/repo@v1.4.2", wantRepo: "username/repo", wantVersion: "v1.4.2"},
+		{input: "username/repo", wantRepo: "username/repo"},
+		{input: "https://github.com/username/repo@2024.01", wantRepo: "https://github.com/username/repo", wantVersion: "2024.01"},
+		{input: "git@github.com:username/repo.git", wantRepo: "git@github.com:username/repo.git"},
+		{input: "@v1", wantRepo: "@v1"},
+		{input: "aws/aws-sdk-go-v2@service/s3/v1.50.0 prefix=service/s3/", wantRepo: "aws/aws-sdk-go-v2", wantVersion: "service/s3/v1.50.0"},
+		{input: "username/repo prerelease=false", wantRepo: "username/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			repo, version := SplitPin(tt.input)
+			if repo != tt.wantRepo || version != tt.wantVersion {
+				t.Errorf("SplitPin() = %v, %v, want %v, %v", repo, version, tt.wantRepo, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestCheckReleaseFeed(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/username/repo/releases.atom":
+			w.WriteHeader(http.StatusOK)
+		case "/username/broken/releases.atom":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	if err := CheckReleaseFeed(mockServer.URL + "/username/repo/releases.atom"); err != nil {
+		t.Errorf("CheckReleaseFeed() error = %v", err)
+	}
+	if err := CheckReleaseFeed(mockServer.URL + "/username/missing/releases.atom"); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("CheckReleaseFeed() error = %v, want %v", err, ErrRepoNotFound)
+	}
+	if err := CheckReleaseFeed(mockServer.URL + "/username/broken/releases.atom"); err == nil || errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("CheckReleaseFeed() error = %v, want status code error", err)
+	}
+}
+
+func TestRepoFromURL(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{"https://github.com/username/repo", "username/repo", true},
+		{"https://github.com/username/repo.git", "username/repo", true},
+		{"git+https://github.com/username/repo.git#main", "username/repo", true},
+		{"git@github.com:username/repo.git", "username/repo", true},
+		{"github.com/username/repo/tree/main/sub", "username/repo", true},
+		{"https://gitlab.com/username/repo", "", false},
+		{"https://github.com/username", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := RepoFromURL(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("RepoFromURL() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAPI(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases":
+			_, _ = w.Write([]byte(`[
+				{"tag_name": "v2.0.0", "draft": true},
+				{"tag_name": "v1.1.0-rc.1", "prerelease": true, "author": {"login": "octocat"},
+				 "assets": [{"name": "tool.tar.gz", "content_type": "application/gzip", "size": 1024}]},
+				{"tag_name": "v1.0.0"}
+			]`))
+		case "/repos/owner/repo/releases/latest":
+			_, _ = w.Write([]byte(`{"tag_name": "v1.0.0"}`))
+		case "/repos/owner/repo/security-advisories":
+			if r.URL.Query().Get("state") != "published" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`[
+				{"ghsa_id": "GHSA-jfh8-c2jp-5v3q", "cve_id": "CVE-2026-1234", "severity": "high", "cvss": {"score": 8.1},
+				 "vulnerabilities": [{"package": {"ecosystem": "go", "name": "example.com/repo"}, "vulnerable_version_range": "< 1.0.1", "patched_versions": "1.0.1"}]}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	api := NewAPI(mockServer.URL, "secret", nil)
+	releases, err := api.Releases(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("Releases() error = %v", err)
+	}
+	if len(releases) != 2 || releases[0].TagName != "v1.1.0-rc.1" || releases[1].TagName != "v1.0.0" {
+		t.Fatalf("Releases() = %+v, want v1.1.0-rc.1 and v1.0.0 without the draft", releases)
+	}
+	if first := releases[0]; !first.Prerelease || first.Author.Login != "octocat" || len(first.Assets) != 1 || first.Assets[0].Size != 1024 {
+		t.Errorf("Releases()[0] = %+v", first)
+	}
+
+	if latest, err := api.LatestRelease(context.Background(), "owner/repo"); err != nil || latest != "v1.0.0" {
+		t.Errorf("LatestRelease() = %q, %v, want v1.0.0", latest, err)
+	}
+	if latest, err := api.LatestRelease(context.Background(), "owner/none"); err != nil || latest != "" {
+		t.Errorf("LatestRelease() without releases = %q, %v, want empty", latest, err)
+	}
+	advisories, err := api.Advisories(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("Advisories() error = %v", err)
+	}
+	if len(advisories) != 1 || advisories[0].CVEID != "CVE-2026-1234" || advisories[0].CVSS.Score != 8.1 ||
+		len(advisories[0].Vulnerabilities) != 1 || advisories[0].Vulnerabilities[0].PatchedVersions != "1.0.1" {
+		t.Errorf("Advisories() = %+v", advisories)
+	}
+
+	if _, err := NewAPI(mockServer.URL, "", nil).Releases(context.Background(), "owner/repo"); err == nil {
+		t.Error("Releases() without a token should fail")
+	}
+}
@@ -0,0 +1,175 @@
+// Package gomod maps the requirements of a go.mod file to GitHub repositories,
+// resolving vanity import paths through their go-import and go-source meta tags.
+package gomod
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/net/html"
+)
+
+// maxMetaPageSize limits how much of a vanity import page is read.
+const maxMetaPageSize = 1 << 20
+
+// lookupTimeout bounds each vanity import page request made by NewResolver's
+// client, so an unresponsive host cannot hang the import.
+const lookupTimeout = 30 * time.Second
+
+// Module is a module required by a go.mod file.
+type Module struct {
+	Path     string
+	Version  string
+	Indirect bool
+	// Line is the line of the require directive within the go.mod file.
+	Line int
+}
+
+// Parse returns the modules required by the go.mod file contents in data.
+func Parse(path string, data []byte) ([]Module, error) {
+	file, err := modfile.ParseLax(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []Module
+	for _, req := range file.Require {
+		line := 0
+		if req.Syntax != nil {
+			line = req.Syntax.Start.Line
+		}
+		modules = append(modules, Module{
+			Path:     req.Mod.Path,
+			Version:  req.Mod.Version,
+			Indirect: req.Indirect,
+			Line:     line,
+		})
+	}
+	return modules, nil
+}
+
+// Resolver maps module paths to GitHub repositories.
+type Resolver struct {
+	// BaseURL replaces "https://" when fetching vanity import pages, so
+	// "golang.org/x/net" is looked up at BaseURL + "/golang.org/x/net?go-get=1".
+	// It is empty in production and points at a local stand-in in tests.
+	BaseURL string
+	// Client fetches vanity import pages. http.DefaultClient is used when nil.
+	Client *http.Client
+
+	cache map[string]string
+}
+
+// NewResolver returns a Resolver that looks up vanity import paths on the
+// internet, giving up on pages that do not load within lookupTimeout.
+func NewResolver() *Resolver {
+	return &Resolver{Client: &http.Client{Timeout: lookupTimeout}}
+}
+
+// Resolve returns the owner/repo GitHub repository that hosts modulePath.
+// Modules hosted on github.com are mapped directly, other paths are resolved
+// by fetching their go-import and go-source meta tags.
+func (r *Resolver) Resolve(modulePath string) (string, error) {
+	if repo, ok := github.RepoFromURL("https://" + modulePath); ok {
+		return repo, nil
+	}
+
+	if r.cache == nil {
+		r.cache = make(map[string]string)
+	}
+	if repo, ok := r.cache[modulePath]; ok {
+		if repo == "" {
+			return "", fmt.Errorf("module %s is not hosted on GitHub", modulePath)
+		}
+		return repo, nil
+	}
+
+	repo, err := r.lookup(modulePath)
+	if err != nil {
+		return "", err
+	}
+	r.cache[modulePath] = repo
+	if repo == "" {
+		return "", fmt.Errorf("module %s is not hosted on GitHub", modulePath)
+	}
+	log.Debugf("Resolved vanity import path %s to %s", modulePath, repo)
+	return repo, nil
+}
+
+// lookup fetches the vanity import page of modulePath and returns the GitHub
+// repository named by its meta tags, or "" when they point elsewhere.
+func (r *Resolver) lookup(modulePath string) (string, error) {
+	pageURL := "https://" + modulePath + "?go-get=1"
+	if r.BaseURL != "" {
+		pageURL = strings.TrimSuffix(r.BaseURL, "/") + "/" + modulePath + "?go-get=1"
+	}
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(pageURL) // #nosec G107 -- module paths come from the go.mod being imported
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch go-import meta tags for %s, status code: %d", modulePath, resp.StatusCode)
+	}
+
+	imports, sources := metaTags(io.LimitReader(resp.Body, maxMetaPageSize))
+	// prefer the repository root of the go-import tag, falling back to the
+	// go-source home and directory URLs, which point at GitHub mirrors for
+	// paths such as golang.org/x/net
+	candidates := append(imports, sources...)
+	for _, candidate := range candidates {
+		if repo, ok := github.RepoFromURL(candidate); ok {
+			return repo, nil
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no go-import meta tag found for %s", modulePath)
+	}
+	return "", nil
+}
+
+// metaTags returns the repository URLs of go-import meta tags and the
+// home and directory URLs of go-source meta tags found in an HTML page.
+func metaTags(r io.Reader) ([]string, []string) {
+	var imports, sources []string
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return imports, sources
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data != "meta" {
+				continue
+			}
+			var name, content string
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "name":
+					name = attr.Val
+				case "content":
+					content = attr.Val
+				}
+			}
+			fields := strings.Fields(content)
+			switch {
+			case name == "go-import" && len(fields) == 3:
+				imports = append(imports, fields[2])
+			case name == "go-source" && len(fields) >= 3:
+				sources = append(sources, fields[2:]...)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package gomod
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testGoMod = `module example.com/service
+
+go 1.26
+
+require (
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/net v0.54.0
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+require go.googlesource.only/tool v1.0.0
+`
+
+func TestParse(t *testing.T) {
+	got, err := Parse("go.mod", []byte(testGoMod))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Module{
+		{Path: "github.com/spf13/cobra", Version: "v1.10.2", Line: 6},
+		{Path: "golang.org/x/net", Version: "v0.54.0", Line: 7},
+		{Path: "gopkg.in/yaml.v3", Version: "v3.0.1", Indirect: true, Line: 8},
+		{Path: "go.googlesource.only/tool", Version: "v1.0.0", Line: 11},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Parse() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Parse()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	lookups := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		if r.URL.Query().Get("go-get") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/golang.org/x/net":
+			fmt.Fprintln(w, `<html><head>
+<meta name="go-import" content="golang.org/x/net git https://go.googlesource.com/net">
+<meta name="go-source" content="golang.org/x/net https://github.com/golang/net/ https://github.com/golang/net/tree/master{/dir} https://github.com/golang/net/blob/master{/dir}/{file}#L{line}">
+</head></html>`)
+		case "/gopkg.in/yaml.v3":
+			fmt.Fprintln(w, `<html><head><meta name="go-import" content="gopkg.in/yaml.v3 git https://gopkg.in/yaml.v3"><meta name="go-source" content="gopkg.in/yaml.v3 _ https://github.com/go-yaml/yaml/tree/v3.0.1{/dir} https://github.com/go-yaml/yaml/blob/v3.0.1{/dir}/{file}#L{line}"></head></html>`)
+		case "/example.com/direct":
+			fmt.Fprintln(w, `<meta name="go-import" content="example.com/direct git https://github.com/example/direct.git">`)
+		case "/go.googlesource.only/tool":
+			fmt.Fprintln(w, `<meta name="go-import" content="go.googlesource.only/tool git https://go.googlesource.com/tool">`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	resolver := &Resolver{BaseURL: mockServer.URL}
+
+	tests := []struct {
+		module  string
+		want    string
+		wantErr bool
+	}{
+		{module: "github.com/spf13/cobra", want: "spf13/cobra"},
+		{module: "github.com/aws/aws-sdk-go-v2/service/s3", want: "aws/aws-sdk-go-v2"},
+		{module: "golang.org/x/net", want: "golang/net"},
+		{module: "gopkg.in/yaml.v3", want: "go-yaml/yaml"},
+		{module: "example.com/direct", want: "example/direct"},
+		{module: "go.googlesource.only/tool", wantErr: true},
+		{module: "example.com/missing", wantErr: true},
+		{module: "golang.org/x/net", want: "golang/net"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.module, func(t *testing.T) {
+			got, err := resolver.Resolve(tt.module)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if lookups != 5 {
+		t.Errorf("resolver made %d lookups, want 5 with cached repeats", lookups)
+	}
+}
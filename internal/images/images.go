@@ -0,0 +1,283 @@
+// Package images finds container image references in Dockerfiles, Compose
+// files and Kubernetes manifests and maps them to their GitHub repositories.
+package images
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/oci"
+)
+
+// Image is a container image reference found in a file.
+type Image struct {
+	Ref  string
+	Line int
+}
+
+// Find returns the Dockerfiles, Containerfiles and YAML files below dir.
+func Find(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if IsDockerfile(d.Name()) || IsYAML(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// IsDockerfile reports whether a file name looks like a Dockerfile or Containerfile.
+func IsDockerfile(name string) bool {
+	lower := strings.ToLower(name)
+	return lower == "dockerfile" || lower == "containerfile" ||
+		strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile") ||
+		strings.HasPrefix(lower, "containerfile.")
+}
+
+// IsYAML reports whether a file name has a YAML extension.
+func IsYAML(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// Parse returns the image references in a Dockerfile or YAML file, chosen by its name.
+func Parse(path string, data []byte) ([]Image, error) {
+	if IsDockerfile(filepath.Base(path)) {
+		return ParseDockerfile(data), nil
+	}
+	return ParseYAML(data)
+}
+
+// ParseDockerfile returns the images used by FROM instructions, skipping
+// scratch, references to earlier build stages and references built from ARGs.
+func ParseDockerfile(data []byte) []Image {
+	var images []Image
+	stages := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+
+		args := fields[1:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		ref := args[0]
+		if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+			stages[strings.ToLower(args[2])] = true
+		}
+
+		if strings.EqualFold(ref, "scratch") || stages[strings.ToLower(ref)] || strings.Contains(ref, "$") {
+			continue
+		}
+		images = append(images, Image{Ref: ref, Line: lineNumber})
+	}
+	return images
+}
+
+// ParseYAML returns the images named by `image:` keys in every document of a
+// Compose file or Kubernetes manifest. Helm-style values with repository and
+// tag keys below image are supported as well.
+func ParseYAML(data []byte) ([]Image, error) {
+	var images []Image
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return images, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		walk(&doc, func(value *yaml.Node) {
+			if ref := imageRef(value); ref != "" && !strings.Contains(ref, "{{") && !strings.Contains(ref, "$") {
+				images = append(images, Image{Ref: ref, Line: value.Line})
+			}
+		})
+	}
+}
+
+// walk calls fn for the value of every `image` key below node.
+func walk(node *yaml.Node, fn func(*yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "image" {
+				fn(value)
+				if value.Kind == yaml.ScalarNode {
+					continue
+				}
+			}
+			walk(value, fn)
+		}
+		return
+	}
+	for _, child := range node.Content {
+		walk(child, fn)
+	}
+}
+
+// imageRef returns the image reference held by the value of an `image` key.
+func imageRef(value *yaml.Node) string {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		return strings.TrimSpace(value.Value)
+	case yaml.MappingNode:
+		fields := make(map[string]string)
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if value.Content[i+1].Kind == yaml.ScalarNode {
+				fields[value.Content[i].Value] = strings.TrimSpace(value.Content[i+1].Value)
+			}
+		}
+		ref := fields["repository"]
+		if ref == "" {
+			return ""
+		}
+		if fields["registry"] != "" {
+			ref = fields["registry"] + "/" + ref
+		}
+		if fields["tag"] != "" {
+			ref += ":" + fields["tag"]
+		}
+		return ref
+	}
+	return ""
+}
+
+// cacheEntry is a cached mapping from an image name to its GitHub repository.
+// An empty Repo records that the image has no usable source label.
+type cacheEntry struct {
+	Repo    string    `json:"repo"`
+	Checked time.Time `json:"checked"`
+}
+
+// Resolver maps image references to the input line of their GitHub repository.
+type Resolver struct {
+	// Registry inspects images that are not hosted on GHCR.
+	Registry *oci.Client
+	// CachePath is a JSON file that keeps registry lookups between runs.
+	// Lookups are only cached in memory when it is empty.
+	CachePath string
+	// TTL is how long cached lookups are trusted. Zero means one week.
+	TTL time.Duration
+
+	cache map[string]cacheEntry
+	dirty bool
+}
+
+// Resolve returns the input line for the GitHub repository behind ref. GHCR
+// images are returned as-is because the input pipeline maps them directly;
+// other images are mapped through their org.opencontainers.image.source label.
+func (r *Resolver) Resolve(ref string) (string, error) {
+	parsed, err := oci.ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Registry == "ghcr.io" {
+		return parsed.Name(), nil
+	}
+
+	r.load()
+	name := parsed.Name()
+	if entry, ok := r.cache[name]; ok && time.Since(entry.Checked) < r.ttl() {
+		if entry.Repo == "" {
+			return "", fmt.Errorf("image %s has no GitHub %s label", name, oci.SourceLabel)
+		}
+		return entry.Repo, nil
+	}
+
+	registry := r.Registry
+	if registry == nil {
+		registry = &oci.Client{}
+	}
+	source, err := registry.Source(parsed)
+	if err != nil && !errors.Is(err, oci.ErrNoSourceLabel) {
+		return "", err
+	}
+
+	repo, _ := github.RepoFromURL(source)
+	r.cache[name] = cacheEntry{Repo: repo, Checked: time.Now()}
+	r.dirty = true
+	if repo == "" {
+		return "", fmt.Errorf("image %s has no GitHub %s label", name, oci.SourceLabel)
+	}
+	log.Debugf("Resolved image %s to %s", name, repo)
+	return repo, nil
+}
+
+// Save writes the lookup cache to CachePath if it changed.
+func (r *Resolver) Save() error {
+	if r.CachePath == "" || !r.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.CachePath), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.CachePath, data, 0600); err != nil {
+		return err
+	}
+	r.dirty = false
+	return nil
+}
+
+// load reads the lookup cache from CachePath on first use.
+func (r *Resolver) load() {
+	if r.cache != nil {
+		return
+	}
+	r.cache = make(map[string]cacheEntry)
+	if r.CachePath == "" {
+		return
+	}
+	data, err := os.ReadFile(r.CachePath) // #nosec G304 -- cache path comes from configuration
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &r.cache); err != nil {
+		log.Warnf("Ignoring unreadable image cache %s: %v", r.CachePath, err)
+		r.cache = make(map[string]cacheEntry)
+	}
+}
+
+func (r *Resolver) ttl() time.Duration {
+	if r.TTL > 0 {
+		return r.TTL
+	}
+	return 7 * 24 * time.Hour
+}
@@ -0,0 +1,123 @@
+package images
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toozej/ghreleases2rss/internal/oci"
+)
+
+const testDockerfile = `ARG GO_VERSION=1.26
+FROM golang:${GO_VERSION} AS build
+FROM --platform=linux/amd64 golang:1.26-trixie AS init
+FROM init AS test
+FROM gcr.io/distroless/static:nonroot
+from scratch
+`
+
+const testManifests = `services:
+  web:
+    image: ghcr.io/owner/web:1.2.3
+  db:
+    image: postgres:16
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: quay.io/org/app@sha256:abc
+---
+image:
+  registry: docker.io
+  repository: grafana/grafana
+  tag: "11.0.0"
+templated:
+  image: "{{ .Values.image }}"
+`
+
+func TestParseDockerfile(t *testing.T) {
+	got := ParseDockerfile([]byte(testDockerfile))
+	want := []Image{{Ref: "golang:1.26-trixie", Line: 3}, {Ref: "gcr.io/distroless/static:nonroot", Line: 5}}
+	if len(got) != len(want) {
+		t.Fatalf("ParseDockerfile() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ParseDockerfile()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	got, err := ParseYAML([]byte(testManifests))
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v", err)
+	}
+	want := []Image{
+		{Ref: "ghcr.io/owner/web:1.2.3", Line: 3},
+		{Ref: "postgres:16", Line: 5},
+		{Ref: "quay.io/org/app@sha256:abc", Line: 14},
+		{Ref: "docker.io/grafana/grafana:11.0.0", Line: 17},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseYAML() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ParseYAML()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	lookups := 0
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		switch r.URL.Path {
+		case "/v2/org/app/manifests/1.0", "/v2/org/bare/manifests/latest":
+			fmt.Fprintf(w, `{"config":{"digest":"sha256:%s"}}`, strings.Split(r.URL.Path, "/")[3])
+		case "/v2/org/app/blobs/sha256:app":
+			fmt.Fprint(w, `{"config":{"Labels":{"org.opencontainers.image.source":"https://github.com/org/app.git"}}}`)
+		case "/v2/org/bare/blobs/sha256:bare":
+			fmt.Fprint(w, `{"config":{"Labels":{}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+	cachePath := filepath.Join(t.TempDir(), "images.json")
+
+	resolver := &Resolver{Registry: &oci.Client{PlainHTTP: true}, CachePath: cachePath}
+	if got, err := resolver.Resolve("ghcr.io/owner/web:1.2.3"); err != nil || got != "ghcr.io/owner/web" {
+		t.Errorf("Resolve(ghcr) = %v, %v, want ghcr.io/owner/web", got, err)
+	}
+	if got, err := resolver.Resolve(host + "/org/app:1.0"); err != nil || got != "org/app" {
+		t.Errorf("Resolve() = %v, %v, want org/app", got, err)
+	}
+	if _, err := resolver.Resolve(host + "/org/bare"); err == nil {
+		t.Error("Resolve() should fail for images without a source label")
+	}
+	if err := resolver.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	before := lookups
+	cached := &Resolver{Registry: &oci.Client{PlainHTTP: true}, CachePath: cachePath}
+	if got, err := cached.Resolve(host + "/org/app:2.0"); err != nil || got != "org/app" {
+		t.Errorf("Resolve() from cache = %v, %v, want org/app", got, err)
+	}
+	if _, err := cached.Resolve(host + "/org/bare"); err == nil {
+		t.Error("Resolve() from cache should remember images without a source label")
+	}
+	if lookups != before {
+		t.Errorf("cached resolver made %d registry requests, want none", lookups-before)
+	}
+}
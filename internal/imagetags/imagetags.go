@@ -0,0 +1,340 @@
+// Package imagetags turns the tags of container images into feeds of newly
+// seen tags. Tags are listed through the OCI distribution API and compared
+// with the tags seen before, which are kept on disk.
+package imagetags
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/oci"
+)
+
+// DefaultRegistries are the registries served for an empty
+// Tracker.Registries: Docker Hub, GHCR and Quay.
+var DefaultRegistries = []string{"docker.io", "ghcr.io", "quay.io"}
+
+// maxEntries limits the number of tags in a feed and the digests looked up
+// per listing.
+const maxEntries = 100
+
+var (
+	// ErrInvalidImage is returned for image names that cannot be parsed or
+	// that include a tag or digest.
+	ErrInvalidImage = errors.New("invalid image name")
+	// ErrRegistryNotAllowed is returned for images on registries that are
+	// not in Tracker.Registries.
+	ErrRegistryNotAllowed = errors.New("registry is not allowed")
+	// ErrImageNotTracked is returned for images that are not in
+	// Tracker.Images.
+	ErrImageNotTracked = errors.New("image is not tracked")
+)
+
+// Tag is a tag of an image as first seen.
+type Tag struct {
+	// Digest is the manifest digest the tag pointed at when it was seen, or
+	// "" when the registry did not report it.
+	Digest string    `json:"digest,omitempty"`
+	Seen   time.Time `json:"seen"`
+	// Baseline is set for the tags that existed when the image was first
+	// listed, which are not new.
+	Baseline bool `json:"baseline,omitempty"`
+}
+
+// state is the stored record of the tags of an image.
+type state struct {
+	Listed time.Time      `json:"listed"`
+	Tags   map[string]Tag `json:"tags"`
+}
+
+// image guards the state of an image, so concurrent requests list it once.
+type image struct {
+	mu    sync.Mutex
+	state *state
+}
+
+// Tracker lists the tags of container images and remembers when each tag
+// was first seen.
+type Tracker struct {
+	// Client talks to the registries.
+	Client *oci.Client
+	// Dir stores the tags seen per image. They are only kept in memory when
+	// it is empty.
+	Dir string
+	// Registries are the registry hosts images may be on, DefaultRegistries
+	// when empty, so the server cannot be used to reach arbitrary hosts.
+	Registries []string
+	// Images are the images whose tags are served, such as nginx or
+	// ghcr.io/owner/image, so the images kept in memory and in Dir are
+	// bounded by the configuration rather than by requests. No image is
+	// served when it is empty.
+	Images []string
+	// MaxAge is how long a listing is used before the tags are listed again,
+	// cache.DefaultMaxAge when zero.
+	MaxAge time.Duration
+
+	mu     sync.Mutex
+	images map[string]*image
+}
+
+// ParseImage parses an image name without tag or digest, such as
+// ghcr.io/owner/image or nginx.
+func ParseImage(name string) (oci.Reference, error) {
+	last := name[strings.LastIndex(name, "/")+1:]
+	if strings.ContainsAny(last, ":@") {
+		return oci.Reference{}, fmt.Errorf("%w %q, expected a name without tag or digest", ErrInvalidImage, name)
+	}
+	ref, err := oci.ParseReference(name)
+	if err != nil {
+		return oci.Reference{}, fmt.Errorf("%w %q", ErrInvalidImage, name)
+	}
+	ref.Tag = ""
+	return ref, nil
+}
+
+// Feed returns the feed of the tags of image first seen after the image was
+// first listed, newest first. The first listing of an image records its
+// existing tags without adding them to the feed, so the feed starts empty
+// and every tag pushed afterwards becomes an entry. When listing fails the
+// tags of the last listing are used.
+func (t *Tracker) Feed(name string) (*feed.Feed, error) {
+	ref, err := ParseImage(name)
+	if err != nil {
+		return nil, err
+	}
+	registries := t.Registries
+	if len(registries) == 0 {
+		registries = DefaultRegistries
+	}
+	if !slices.Contains(registries, ref.Registry) {
+		return nil, fmt.Errorf("%s: %w", ref.Registry, ErrRegistryNotAllowed)
+	}
+	if !t.tracks(ref) {
+		return nil, fmt.Errorf("%s: %w", ref.Name(), ErrImageNotTracked)
+	}
+
+	img := t.image(ref.Name())
+	img.mu.Lock()
+	defer img.mu.Unlock()
+	if img.state == nil {
+		if img.state, err = t.load(ref.Name()); err != nil {
+			log.Warnf("Unable to read seen tags of %s: %v", ref.Name(), err)
+			img.state = &state{}
+		}
+	}
+	maxAge := t.MaxAge
+	if maxAge <= 0 {
+		maxAge = cache.DefaultMaxAge
+	}
+	if time.Since(img.state.Listed) >= maxAge {
+		if err := t.update(ref, img.state); err != nil {
+			if img.state.Listed.IsZero() || errors.Is(err, oci.ErrNotFound) {
+				return nil, err
+			}
+			log.Warnf("Serving last seen tags of %s: %v", ref.Name(), err)
+		} else if err := t.save(ref.Name(), img.state); err != nil {
+			log.Warnf("Unable to store seen tags of %s: %v", ref.Name(), err)
+		}
+	}
+	return newFeed(ref, img.state), nil
+}
+
+// tracks reports whether ref is one of Images, comparing normalized names
+// so nginx matches docker.io/library/nginx.
+func (t *Tracker) tracks(ref oci.Reference) bool {
+	for _, name := range t.Images {
+		if tracked, err := ParseImage(name); err == nil && tracked.Name() == ref.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tracker) image(name string) *image {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.images == nil {
+		t.images = make(map[string]*image)
+	}
+	img, ok := t.images[name]
+	if !ok {
+		img = &image{}
+		t.images[name] = img
+	}
+	return img
+}
+
+// update lists the tags of ref and records the ones not seen before, with
+// the digest they point at unless they are part of the first listing.
+func (t *Tracker) update(ref oci.Reference, s *state) error {
+	tags, err := t.Client.Tags(ref)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	baseline := s.Listed.IsZero()
+	if s.Tags == nil {
+		s.Tags = make(map[string]Tag)
+	}
+	lookups := 0
+	for _, name := range tags {
+		if _, ok := s.Tags[name]; ok {
+			continue
+		}
+		tag := Tag{Seen: now, Baseline: baseline}
+		if !baseline && lookups < maxEntries {
+			lookups++
+			tagRef := ref
+			tagRef.Tag = name
+			if tag.Digest, err = t.Client.Digest(tagRef); err != nil {
+				log.Debugf("Unable to look up the digest of %s: %v", tagRef, err)
+			}
+		}
+		s.Tags[name] = tag
+	}
+	if !baseline && lookups > 0 {
+		log.Debugf("Found %d new tags of %s", lookups, ref.Name())
+	}
+	s.Listed = now
+	return nil
+}
+
+// newFeed returns the feed of the tags in s that are not part of the
+// baseline.
+func newFeed(ref oci.Reference, s *state) *feed.Feed {
+	id := "urn:ghreleases2rss:image-tags:" + ref.Name()
+	f := &feed.Feed{ID: id, Title: "Tags of " + ref.Name(), Link: webURL(ref, ""), Updated: s.Listed}
+
+	var names []string
+	for name, tag := range s.Tags {
+		if !tag.Baseline {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.Tags[names[i]], s.Tags[names[j]]
+		if !a.Seen.Equal(b.Seen) {
+			return a.Seen.After(b.Seen)
+		}
+		return names[i] > names[j]
+	})
+	if len(names) > maxEntries {
+		names = names[:maxEntries]
+	}
+
+	for _, name := range names {
+		tag := s.Tags[name]
+		tagRef := ref
+		tagRef.Tag = name
+		content := "<p><code>docker pull " + html.EscapeString(tagRef.String()) + "</code></p>"
+		if tag.Digest != "" {
+			content = "<p>Digest: <code>" + html.EscapeString(tag.Digest) + "</code></p>" + content
+		}
+		f.Entries = append(f.Entries, feed.Entry{
+			ID:      id + ":" + name,
+			Title:   name,
+			Link:    webURL(ref, name),
+			Updated: tag.Seen,
+			Content: content,
+		})
+	}
+	if len(f.Entries) > 0 {
+		f.Updated = f.Entries[0].Updated
+	}
+	return f
+}
+
+// webURL returns the web page of the image ref on Docker Hub, GHCR or Quay,
+// showing tag when the registry has tag pages, or "" for other registries.
+func webURL(ref oci.Reference, tag string) string {
+	switch ref.Registry {
+	case "docker.io":
+		page := "https://hub.docker.com/r/" + ref.Repository
+		if name, ok := strings.CutPrefix(ref.Repository, "library/"); ok {
+			page = "https://hub.docker.com/_/" + name
+		}
+		if tag != "" {
+			page += "/tags?name=" + url.QueryEscape(tag)
+		}
+		return page
+	case "ghcr.io":
+		return "https://ghcr.io/" + ref.Repository
+	case "quay.io":
+		page := "https://quay.io/repository/" + ref.Repository + "?tab=tags"
+		if tag != "" {
+			page += "&tag=" + url.QueryEscape(tag)
+		}
+		return page
+	}
+	return ""
+}
+
+// path returns where the seen tags of the image name are stored.
+func (t *Tracker) path(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return filepath.Join(t.Dir, "image-tags", hex.EncodeToString(sum[:])+".json")
+}
+
+func (t *Tracker) load(name string) (*state, error) {
+	s := &state{}
+	if t.Dir == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(t.path(name)) // #nosec G304 -- path is derived from a hash inside Dir
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// save writes s through a temporary file, so readers never see a partly
+// written file.
+func (t *Tracker) save(name string, s *state) error {
+	if t.Dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	path := t.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,184 @@
+package imagetags
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/oci"
+)
+
+// testRegistry serves the tags of the image "org/app" and the digest of
+// each tag.
+type testRegistry struct {
+	mu   sync.Mutex
+	tags []string
+}
+
+func (reg *testRegistry) setTags(tags ...string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.tags = tags
+}
+
+func (reg *testRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if r.URL.Path == "/v2/org/app/tags/list" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "org/app", "tags": reg.tags})
+		return
+	}
+	if tag, ok := strings.CutPrefix(r.URL.Path, "/v2/org/app/manifests/"); ok {
+		for _, t := range reg.tags {
+			if t == tag {
+				w.Header().Set("Docker-Content-Digest", "sha256:"+tag)
+				return
+			}
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func newTracker(t *testing.T, dir string) (*Tracker, *testRegistry, string) {
+	t.Helper()
+	reg := &testRegistry{}
+	srv := httptest.NewServer(reg)
+	t.Cleanup(srv.Close)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	tracker := &Tracker{
+		Client:     &oci.Client{PlainHTTP: true},
+		Dir:        dir,
+		Registries: []string{host},
+		Images:     []string{host + "/org/app", host + "/org/missing"},
+		MaxAge:     time.Nanosecond,
+	}
+	return tracker, reg, host
+}
+
+func TestTrackerFeed(t *testing.T) {
+	dir := t.TempDir()
+	tracker, reg, host := newTracker(t, dir)
+	image := host + "/org/app"
+
+	reg.setTags("1.0", "1.1")
+	f, err := tracker.Feed(image)
+	if err != nil {
+		t.Fatalf("Feed() error = %v", err)
+	}
+	if len(f.Entries) != 0 {
+		t.Fatalf("first Feed() entries = %d, want the existing tags as baseline", len(f.Entries))
+	}
+
+	reg.setTags("1.0", "1.1", "1.2", "2.0")
+	f, err = tracker.Feed(image)
+	if err != nil {
+		t.Fatalf("Feed() error = %v", err)
+	}
+	var titles []string
+	for _, entry := range f.Entries {
+		titles = append(titles, entry.Title)
+	}
+	if got := strings.Join(titles, ","); got != "2.0,1.2" {
+		t.Fatalf("Feed() titles = %s, want 2.0,1.2", got)
+	}
+	entry := f.Entries[0]
+	if entry.ID != "urn:ghreleases2rss:image-tags:"+image+":2.0" {
+		t.Errorf("entry ID = %s", entry.ID)
+	}
+	if !strings.Contains(entry.Content, "sha256:2.0") || !strings.Contains(entry.Content, "docker pull "+image+":2.0") {
+		t.Errorf("entry content = %s, want the digest and pull command", entry.Content)
+	}
+
+	// A new tracker reads the seen tags back from Dir
+	other, _, _ := newTracker(t, dir)
+	other.Registries = tracker.Registries
+	other.Images = tracker.Images
+	other.Client = tracker.Client
+	other.MaxAge = time.Hour
+	f, err = other.Feed(image)
+	if err != nil {
+		t.Fatalf("Feed() error = %v", err)
+	}
+	if len(f.Entries) != 2 {
+		t.Errorf("Feed() from stored tags entries = %d, want 2", len(f.Entries))
+	}
+}
+
+func TestTrackerFeedErrors(t *testing.T) {
+	tracker, _, host := newTracker(t, "")
+	tests := []struct {
+		image string
+		want  error
+	}{
+		{image: "ghcr.io/owner/image", want: ErrRegistryNotAllowed},
+		{image: host + "/org/app:1.0", want: ErrInvalidImage},
+		{image: host + "/org/app@sha256:abc", want: ErrInvalidImage},
+		{image: host + "/org/missing", want: oci.ErrNotFound},
+		{image: host + "/org/other", want: ErrImageNotTracked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if _, err := tracker.Feed(tt.image); !errors.Is(err, tt.want) {
+				t.Errorf("Feed() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebURL(t *testing.T) {
+	tests := []struct {
+		image string
+		tag   string
+		want  string
+	}{
+		{image: "nginx", tag: "1.27", want: "https://hub.docker.com/_/nginx/tags?name=1.27"},
+		{image: "grafana/grafana", want: "https://hub.docker.com/r/grafana/grafana"},
+		{image: "ghcr.io/owner/image", tag: "v1", want: "https://ghcr.io/owner/image"},
+		{image: "quay.io/prometheus/node-exporter", tag: "v1.8.0", want: "https://quay.io/repository/prometheus/node-exporter?tab=tags&tag=v1.8.0"},
+		{image: "registry.example.com/app", tag: "1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ref, err := ParseImage(tt.image)
+			if err != nil {
+				t.Fatalf("ParseImage() error = %v", err)
+			}
+			if got := webURL(ref, tt.tag); got != tt.want {
+				t.Errorf("webURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerTracks(t *testing.T) {
+	tracker := &Tracker{Images: []string{"nginx", "ghcr.io/owner/image", "not a valid image"}}
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{image: "nginx", want: true},
+		{image: "docker.io/library/nginx", want: true},
+		{image: "ghcr.io/owner/image", want: true},
+		{image: "ghcr.io/owner/other", want: false},
+		{image: "library/redis", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ref, err := ParseImage(tt.image)
+			if err != nil {
+				t.Fatalf("ParseImage() error = %v", err)
+			}
+			if got := tracker.tracks(ref); got != tt.want {
+				t.Errorf("tracks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Package input reads GitHub repository lists from local files, glob patterns,
+// stdin, HTTPS URLs and git repositories.
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// Stdin is the source spec and source name used for standard input.
+const Stdin = "-"
+
+// Line is a single non-empty line read from an input source.
+type Line struct {
+	// Source names where the line came from, e.g. a file path or "stdin".
+	Source string
+	// Number is the 1-based line number within Source.
+	Number int
+	// Text is the trimmed content of the line.
+	Text string
+	// Section is the name of the closest [Section] header above the line in
+	// its source, or empty when there is none.
+	Section string
+}
+
+// Options configures how input sources are read.
+type Options struct {
+	// Stdin is read when the "-" source is given.
+	Stdin io.Reader
+	// Token is sent as a bearer token to HTTPS and git+https sources when set.
+	Token string
+	// CacheDir stores remote sources and their ETags between runs. Caching is
+	// disabled when it is empty.
+	CacheDir string
+	// HTTPClient fetches HTTPS sources. http.DefaultClient is used when nil.
+	HTTPClient *http.Client
+	// AllowedDirs lists the directories local files may be read from. Only the
+	// current working directory is allowed when it is empty.
+	AllowedDirs []string
+}
+
+// Read expands each spec into its sources and returns the non-empty lines of
+// all of them in order. A spec is a file path, a glob pattern such as
+// "lists/*.txt", "-" for stdin (which may only be given once), an HTTPS URL,
+// or a git repository in the form git+https://host/org/repo.git//path/file@ref.
+func Read(specs []string, opts Options) ([]Line, error) {
+	if len(specs) == 0 {
+		return nil, errors.New("no input sources given")
+	}
+
+	roots, err := config.AllowedRoots(opts.AllowedDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []Line
+	usedStdin := false
+	for _, spec := range specs {
+		var read []Line
+		var err error
+		switch {
+		case spec == Stdin:
+			if usedStdin {
+				return nil, errors.New("stdin can only be used as an input source once")
+			}
+			usedStdin = true
+			read, err = readLines("stdin", opts.Stdin)
+		case strings.HasPrefix(spec, "git+"):
+			read, err = readGit(spec, opts, roots)
+		case isHTTP(spec):
+			read, err = readHTTP(spec, opts)
+		default:
+			read, err = readFiles(spec, roots)
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, read...)
+	}
+
+	return lines, nil
+}
+
+// expand returns the files matched by spec, or spec itself when it is not a glob pattern.
+func expand(spec string) ([]string, error) {
+	if !strings.ContainsAny(spec, "*?[") {
+		return []string{spec}, nil
+	}
+
+	matches, err := filepath.Glob(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", spec, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("glob pattern %q matched no files", spec)
+	}
+	return matches, nil
+}
+
+// readFiles reads the lines of every local file matched by spec.
+func readFiles(spec string, roots []string) ([]Line, error) {
+	paths, err := expand(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []Line
+	for _, path := range paths {
+		read, err := readFile(path, roots)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, read...)
+	}
+	return lines, nil
+}
+
+// readFile reads the lines of a local file opened with traversal protection.
+func readFile(path string, roots []string) ([]Line, error) {
+	file, err := openFileSecurely(path, roots)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer file.Close()
+
+	return readLines(path, file)
+}
+
+// isHTTP reports whether spec is an HTTP(S) URL source.
+func isHTTP(spec string) bool {
+	return strings.HasPrefix(spec, "https://") || strings.HasPrefix(spec, "http://")
+}
+
+// readLines returns the trimmed non-empty lines of r, labelled with source.
+// Lines starting with # and trailing " # ..." comments are ignored, and
+// [Section] headers set the Section of the lines below them.
+func readLines(source string, r io.Reader) ([]Line, error) {
+	var lines []Line
+	number := 0
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		number++
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if name, ok := SectionName(text); ok {
+			section = name
+			continue
+		}
+		lines = append(lines, Line{Source: source, Number: number, Text: text, Section: section})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", source, err)
+	}
+	return lines, nil
+}
+
+// SectionName reports whether text is a [Section] header and returns the
+// trimmed name between the brackets.
+func SectionName(text string) (string, bool) {
+	if len(text) < 2 || text[0] != '[' || text[len(text)-1] != ']' {
+		return "", false
+	}
+	return strings.TrimSpace(text[1 : len(text)-1]), true
+}
+
+// ReadFile reads a local manifest file with the same traversal protection as
+// input files. Only files inside allowedDirs, or the current working directory
+// when it is empty, can be read.
+func ReadFile(path string, allowedDirs []string) ([]byte, error) {
+	roots, err := config.AllowedRoots(allowedDirs)
+	if err != nil {
+		return nil, err
+	}
+	file, err := openFileSecurely(path, roots)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// openFileSecurely opens a file with path traversal protection. The file must
+// resolve, after following symlinks, to a path inside one of the allowed roots.
+func openFileSecurely(filePath string, roots []string) (*os.File, error) {
+	resolved, err := config.ResolveAllowedPath(filePath, roots)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open the file - gosec G304 is acceptable here as we have directory traversal protection above
+	file, err := os.Open(resolved) // #nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+
+	return file, nil
+}
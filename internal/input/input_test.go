@@ -0,0 +1,123 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFile(t, "lists/a.txt", "username/a\n\n  username/b  \n")
+	writeFile(t, "lists/b.txt", "username/c\n")
+	writeFile(t, "repos.txt", "username/d\n")
+	writeFile(t, "commented.txt", "# go.mod:6\nusername/f # pinned\n  # username/g\n")
+	writeFile(t, "sections.txt", "username/h\n[ Dev Tools ]\nusername/i\n\n[Infra]\nusername/j\n")
+
+	tests := []struct {
+		name    string
+		specs   []string
+		stdin   string
+		want    []Line
+		wantErr bool
+	}{
+		{
+			name:  "Single file",
+			specs: []string{"repos.txt"},
+			want:  []Line{{Source: "repos.txt", Number: 1, Text: "username/d"}},
+		},
+		{
+			name:  "Comments",
+			specs: []string{"commented.txt"},
+			want:  []Line{{Source: "commented.txt", Number: 2, Text: "username/f"}},
+		},
+		{
+			name:  "Sections",
+			specs: []string{"sections.txt", "repos.txt"},
+			want: []Line{
+				{Source: "sections.txt", Number: 1, Text: "username/h"},
+				{Source: "sections.txt", Number: 3, Text: "username/i", Section: "Dev Tools"},
+				{Source: "sections.txt", Number: 6, Text: "username/j", Section: "Infra"},
+				{Source: "repos.txt", Number: 1, Text: "username/d"},
+			},
+		},
+		{
+			name:  "Glob and stdin",
+			specs: []string{"lists/*.txt", "-"},
+			stdin: "username/e\n",
+			want: []Line{
+				{Source: filepath.Join("lists", "a.txt"), Number: 1, Text: "username/a"},
+				{Source: filepath.Join("lists", "a.txt"), Number: 3, Text: "username/b"},
+				{Source: filepath.Join("lists", "b.txt"), Number: 1, Text: "username/c"},
+				{Source: "stdin", Number: 1, Text: "username/e"},
+			},
+		},
+		{
+			name:    "Stdin twice",
+			specs:   []string{"-", "-"},
+			wantErr: true,
+		},
+		{
+			name:    "Glob without matches",
+			specs:   []string{"missing/*.txt"},
+			wantErr: true,
+		},
+		{
+			name:    "File outside working directory",
+			specs:   []string{"../outside.txt"},
+			wantErr: true,
+		},
+		{
+			name:    "No sources",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(tt.specs, Options{Stdin: strings.NewReader(tt.stdin)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Read() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Read()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadAllowedDirs(t *testing.T) {
+	mounted := t.TempDir()
+	writeFile(t, filepath.Join(mounted, "repos.txt"), "username/a\n")
+	t.Chdir(t.TempDir())
+
+	path := filepath.Join(mounted, "repos.txt")
+	if _, err := Read([]string{path}, Options{}); err == nil {
+		t.Error("Read() should refuse files outside the working directory by default")
+	}
+
+	got, err := Read([]string{path}, Options{AllowedDirs: []string{mounted}})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Text != "username/a" {
+		t.Errorf("Read() = %v, want one line", got)
+	}
+}
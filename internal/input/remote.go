@@ -0,0 +1,250 @@
+package input
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/pkg/config"
+)
+
+// maxRemoteSize limits how much of a remote input source is read.
+const maxRemoteSize = 10 << 20
+
+// cacheEntry is the metadata stored next to a cached remote input source.
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// readHTTP fetches an HTTPS input source, revalidating any cached copy with
+// its ETag so unchanged lists are not downloaded again.
+func readHTTP(spec string, opts Options) ([]Line, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input URL: %w", err)
+	}
+	source := u.Redacted()
+	if u.Scheme != "https" && !isLoopback(u.Hostname()) {
+		return nil, fmt.Errorf("%s: refusing to fetch input over plain HTTP, use https", source)
+	}
+
+	bodyPath, metaPath := cachePaths(opts.CacheDir, spec)
+	var cached cacheEntry
+	if metaPath != "" {
+		if data, err := os.ReadFile(metaPath); err == nil { // #nosec G304 -- path is derived from a hash inside CacheDir
+			_ = json.Unmarshal(data, &cached)
+		}
+	}
+
+	req, err := http.NewRequest("GET", spec, nil)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.Token)
+	}
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) // #nosec G704 -- input URL is given by the user running the tool
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached.URL != "":
+		log.Debugf("Input source %s not modified, using cached copy", source)
+		data, err := os.ReadFile(bodyPath) // #nosec G304 -- path is derived from a hash inside CacheDir
+		if err != nil {
+			return nil, fmt.Errorf("%s: error reading cached copy: %w", source, err)
+		}
+		return readLines(source, bytes.NewReader(data))
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: failed to fetch input, status code: %d", source, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if len(data) > maxRemoteSize {
+		return nil, fmt.Errorf("%s: input larger than %d bytes", source, maxRemoteSize)
+	}
+
+	if metaPath != "" {
+		entry := cacheEntry{URL: source, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+		if err := writeCache(bodyPath, metaPath, data, entry); err != nil {
+			log.Warnf("Unable to cache input source %s: %v", source, err)
+		}
+	}
+
+	return readLines(source, bytes.NewReader(data))
+}
+
+// cachePaths returns where the body and metadata of a remote source are cached.
+func cachePaths(cacheDir, spec string) (string, string) {
+	if cacheDir == "" {
+		return "", ""
+	}
+	sum := sha256.Sum256([]byte(spec))
+	key := hex.EncodeToString(sum[:])
+	dir := filepath.Join(cacheDir, "inputs")
+	return filepath.Join(dir, key+".txt"), filepath.Join(dir, key+".json")
+}
+
+// writeCache stores a fetched remote source and its validators.
+func writeCache(bodyPath, metaPath string, data []byte, entry cacheEntry) error {
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(bodyPath), 0700); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(bodyPath, data, 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, meta, 0600)
+}
+
+// isLoopback reports whether host refers to the local machine.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// gitSource is a file at a ref within a git repository.
+type gitSource struct {
+	Repo string
+	Path string
+	Ref  string
+}
+
+// parseGitSpec splits git+<url>//<path>[@ref] into its parts.
+func parseGitSpec(spec string) (gitSource, error) {
+	raw := strings.TrimPrefix(spec, "git+")
+	schemeEnd := strings.Index(raw, "://")
+	if schemeEnd < 0 {
+		return gitSource{}, errors.New("invalid git input source, expected git+https://host/org/repo.git//path/file@ref")
+	}
+	scheme := raw[:schemeEnd]
+	if scheme != "https" && scheme != "ssh" && scheme != "file" {
+		return gitSource{}, fmt.Errorf("unsupported git input scheme %q", scheme)
+	}
+
+	rest := raw[schemeEnd+len("://"):]
+	sep := -1
+	if rest != "" {
+		sep = strings.Index(rest[1:], "//")
+	}
+	if sep < 0 {
+		return gitSource{}, errors.New("git input source is missing the //path/to/file part")
+	}
+	sep++
+	src := gitSource{Repo: raw[:schemeEnd+len("://")+sep], Path: rest[sep+len("//"):]}
+	if at := strings.LastIndex(src.Path, "@"); at >= 0 {
+		src.Ref = src.Path[at+1:]
+		src.Path = src.Path[:at]
+	}
+
+	if src.Path == "" || strings.HasPrefix(src.Ref, "-") {
+		return gitSource{}, errors.New("invalid git input source path or ref")
+	}
+	return src, nil
+}
+
+// readGit shallow-clones a git repository into a temporary directory and reads
+// one file from it. The file must resolve to a path inside the clone, and
+// file:// repositories must be inside roots like other local files.
+func readGit(spec string, opts Options, roots []string) ([]Line, error) {
+	src, err := parseGitSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	if repo, ok := strings.CutPrefix(src.Repo, "file://"); ok {
+		host, path, _ := strings.Cut(repo, "/")
+		if host != "" && host != "localhost" {
+			return nil, fmt.Errorf("unsupported git input host %q in %s", host, src.Repo)
+		}
+		resolved, err := config.ResolveAllowedPath(filepath.FromSlash("/"+path), roots)
+		if err != nil {
+			return nil, err
+		}
+		src.Repo = "file://" + filepath.ToSlash(resolved)
+	}
+	source := spec
+	if u, err := url.Parse(strings.TrimPrefix(spec, "git+")); err == nil {
+		source = "git+" + u.Redacted()
+	}
+
+	dir, err := os.MkdirTemp("", "ghreleases2rss-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if src.Ref != "" {
+		args = append(args, "--branch", src.Ref)
+	}
+	args = append(args, "--", src.Repo, dir)
+
+	scheme := src.Repo[:strings.Index(src.Repo, "://")]
+	cmd := exec.Command("git", args...) // #nosec G204 -- arguments are passed without a shell and the URL follows "--"
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL="+scheme)
+	if opts.Token != "" && scheme == "https" {
+		// pass the token through the environment so it does not show up in process listings
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Bearer "+opts.Token,
+		)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: git clone failed: %v: %s", source, err, strings.TrimSpace(stderr.String()))
+	}
+
+	cloneRoots, err := config.AllowedRoots([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+	file, err := openFileSecurely(filepath.Join(dir, filepath.FromSlash(src.Path)), cloneRoots)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	defer file.Close()
+
+	return readLines(source, io.LimitReader(file, maxRemoteSize))
+}
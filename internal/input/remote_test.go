@@ -0,0 +1,138 @@
+package input
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadHTTP(t *testing.T) {
+	requests := 0
+	notModified := 0
+	mockServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("username/a\n\nusername/b\n"))
+	}))
+	defer mockServer.Close()
+
+	opts := Options{Token: "secret", CacheDir: t.TempDir(), HTTPClient: mockServer.Client()}
+	spec := mockServer.URL + "/repos.txt"
+
+	for i := 0; i < 2; i++ {
+		got, err := Read([]string{spec}, opts)
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		want := []Line{{Source: spec, Number: 1, Text: "username/a"}, {Source: spec, Number: 3, Text: "username/b"}}
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("Read() = %v, want %v", got, want)
+		}
+	}
+	if requests != 2 || notModified != 1 {
+		t.Errorf("server saw %d requests with %d revalidations, want 2 and 1", requests, notModified)
+	}
+
+	opts.Token = ""
+	if _, err := Read([]string{spec}, opts); err == nil {
+		t.Error("Read() without token should fail")
+	}
+}
+
+func TestReadHTTPRefusesPlainHTTP(t *testing.T) {
+	if _, err := Read([]string{"http://example.com/repos.txt"}, Options{}); err == nil {
+		t.Error("Read() should refuse plain HTTP sources on non-loopback hosts")
+	}
+}
+
+func TestParseGitSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    gitSource
+		wantErr bool
+	}{
+		{
+			spec: "git+https://example.com/org/lists.git//path/repos.txt@main",
+			want: gitSource{Repo: "https://example.com/org/lists.git", Path: "path/repos.txt", Ref: "main"},
+		},
+		{
+			spec: "git+file:///srv/lists.git//repos.txt",
+			want: gitSource{Repo: "file:///srv/lists.git", Path: "repos.txt"},
+		},
+		{spec: "git+https://example.com/org/lists.git", wantErr: true},
+		{spec: "git+ext::sh -c touch% /tmp/pwned//repos.txt", wantErr: true},
+		{spec: "git+https://example.com/org/lists.git//repos.txt@--upload-pack=x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseGitSpec(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGitSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseGitSpec() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "init.defaultBranch=main"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, out)
+	}
+}
+
+func TestReadGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	base := t.TempDir()
+	bare := filepath.Join(base, "lists.git")
+	work := filepath.Join(base, "work")
+	runGit(t, base, "init", "--quiet", "--bare", bare)
+	runGit(t, base, "clone", "--quiet", bare, work)
+	writeFile(t, filepath.Join(work, "teams", "repos.txt"), "username/a\nusername/b\n")
+	if err := os.Symlink("/etc/passwd", filepath.Join(work, "escape.txt")); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "--quiet", "-m", "add lists")
+	runGit(t, work, "push", "--quiet", "origin", "HEAD:main")
+
+	spec := "git+file://" + filepath.ToSlash(bare) + "//teams/repos.txt@main"
+	allowed := Options{AllowedDirs: []string{base}}
+	got, err := Read([]string{spec}, allowed)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(got) != 2 || got[1].Text != "username/b" || got[1].Source != spec {
+		t.Errorf("Read() = %v, want two lines from %s", got, spec)
+	}
+
+	if _, err := Read([]string{spec}, Options{}); err == nil {
+		t.Error("Read() should refuse file:// repositories outside the allowed directories")
+	}
+	if _, err := Read([]string{"git+file://" + filepath.ToSlash(bare) + "//escape.txt@main"}, allowed); err == nil {
+		t.Error("Read() should refuse files that resolve outside the clone")
+	}
+	if _, err := Read([]string{"git+file://" + filepath.ToSlash(bare) + "//../../etc/passwd@main"}, allowed); err == nil {
+		t.Error("Read() should refuse paths that traverse outside the clone")
+	}
+}
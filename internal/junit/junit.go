@@ -0,0 +1,150 @@
+// Package junit writes JUnit XML reports so CI systems can show per-line
+// validation and sync results in their test results UI.
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TestSuites is the root element of a JUnit XML report.
+type TestSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Suites   []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite groups the test cases of a single input source.
+type TestSuite struct {
+	Name      string     `xml:"name,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Skipped   int        `xml:"skipped,attr"`
+	Time      string     `xml:"time,attr"`
+	TestCases []TestCase `xml:"testcase"`
+}
+
+// TestCase is the result of processing one input line.
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	File      string   `xml:"file,attr,omitempty"`
+	Line      int      `xml:"line,attr,omitempty"`
+	Time      string   `xml:"time,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+
+	elapsed time.Duration
+}
+
+// Failure describes why a test case failed.
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Skipped marks a test case that was not executed, such as a duplicate line.
+type Skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Report collects test cases grouped by input source in insertion order.
+type Report struct {
+	Name   string
+	suites []TestSuite
+	index  map[string]int
+}
+
+// NewReport returns an empty report with the given top-level name.
+func NewReport(name string) *Report {
+	return &Report{Name: name, index: make(map[string]int)}
+}
+
+// Pass records a successful test case in the suite of file.
+func (r *Report) Pass(file string, line int, name string, elapsed time.Duration) {
+	r.add(TestCase{Name: name, File: file, Line: line, elapsed: elapsed})
+}
+
+// Fail records a failed test case in the suite of file. The failure message
+// carries the line number so it remains visible in UIs that ignore attributes.
+func (r *Report) Fail(file string, line int, name, kind string, err error, elapsed time.Duration) {
+	msg := fmt.Sprintf("line %d: %v", line, err)
+	r.add(TestCase{
+		Name:    name,
+		File:    file,
+		Line:    line,
+		elapsed: elapsed,
+		Failure: &Failure{Message: msg, Type: kind, Text: msg},
+	})
+}
+
+// Skip records a skipped test case in the suite of file.
+func (r *Report) Skip(file string, line int, name, reason string) {
+	r.add(TestCase{Name: name, File: file, Line: line, Skipped: &Skipped{Message: reason}})
+}
+
+// add appends tc to the suite named after its file.
+func (r *Report) add(tc TestCase) {
+	tc.ClassName = tc.File
+	tc.Time = seconds(tc.elapsed)
+	i, ok := r.index[tc.File]
+	if !ok {
+		r.suites = append(r.suites, TestSuite{Name: tc.File})
+		i = len(r.suites) - 1
+		r.index[tc.File] = i
+	}
+	r.suites[i].TestCases = append(r.suites[i].TestCases, tc)
+}
+
+// Build assembles the report into its XML document structure.
+func (r *Report) Build() TestSuites {
+	root := TestSuites{Name: r.Name}
+	var total time.Duration
+	for _, s := range r.suites {
+		var elapsed time.Duration
+		for _, tc := range s.TestCases {
+			s.Tests++
+			if tc.Failure != nil {
+				s.Failures++
+			}
+			if tc.Skipped != nil {
+				s.Skipped++
+			}
+			elapsed += tc.elapsed
+		}
+		s.Time = seconds(elapsed)
+		root.Tests += s.Tests
+		root.Failures += s.Failures
+		root.Skipped += s.Skipped
+		total += elapsed
+		root.Suites = append(root.Suites, s)
+	}
+	root.Time = seconds(total)
+	return root
+}
+
+// WriteFile writes the report as JUnit XML to path.
+func (r *Report) WriteFile(path string) error {
+	out, err := xml.MarshalIndent(r.Build(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding JUnit report: %w", err)
+	}
+	out = append([]byte(xml.Header), out...)
+	out = append(out, '\n')
+	if err := os.WriteFile(filepath.Clean(path), out, 0600); err != nil {
+		return fmt.Errorf("error writing JUnit report: %w", err)
+	}
+	return nil
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,59 @@
+package junit
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportBuild(t *testing.T) {
+	report := NewReport("ghreleases2rss validate")
+	report.Pass("repos.txt", 1, "username/repo", time.Second)
+	report.Fail("repos.txt", 2, "username", "ParseError", errors.New("invalid username/reponame format"), 0)
+	report.Skip("other.txt", 1, "username/repo", "duplicate")
+
+	got := report.Build()
+	if got.Tests != 3 || got.Failures != 1 || got.Skipped != 1 {
+		t.Errorf("Build() totals = %d/%d/%d, want 3/1/1", got.Tests, got.Failures, got.Skipped)
+	}
+	if len(got.Suites) != 2 {
+		t.Fatalf("Build() produced %d suites, want 2", len(got.Suites))
+	}
+	if got.Suites[0].Name != "repos.txt" || got.Suites[0].Tests != 2 {
+		t.Errorf("first suite = %s with %d tests, want repos.txt with 2", got.Suites[0].Name, got.Suites[0].Tests)
+	}
+
+	failure := got.Suites[0].TestCases[1].Failure
+	if failure == nil {
+		t.Fatal("expected a failure for line 2")
+	}
+	if failure.Type != "ParseError" || !strings.Contains(failure.Message, "line 2") {
+		t.Errorf("failure = %+v, want ParseError mentioning line 2", failure)
+	}
+}
+
+func TestReportWriteFile(t *testing.T) {
+	report := NewReport("ghreleases2rss sync")
+	report.Fail("repos.txt", 3, "username/missing", "SubscribeError", errors.New("status code: 500"), 0)
+
+	path := filepath.Join(t.TempDir(), "out.xml")
+	if err := report.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed TestSuites
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+	if parsed.Failures != 1 || parsed.Suites[0].TestCases[0].Line != 3 {
+		t.Errorf("parsed report = %+v, want one failure on line 3", parsed)
+	}
+}
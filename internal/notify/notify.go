@@ -0,0 +1,159 @@
+// Package notify delivers new releases to sinks outside of feed readers: a
+// JSON webhook with a templated body, an ntfy topic and SMTP email, retrying
+// failed deliveries with exponential backoff.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+)
+
+// Defaults used for zero Retry fields.
+const (
+	DefaultAttempts = 3
+	DefaultBackoff  = 2 * time.Second
+)
+
+// Release is a new release to notify about.
+type Release struct {
+	// Repo is the owner/repo the release was published in.
+	Repo string `json:"repo"`
+	Tag  string `json:"tag"`
+	// Title is the release title from the feed, which is often the tag.
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	Published  time.Time `json:"published"`
+	Author     string    `json:"author,omitempty"`
+	Prerelease bool      `json:"prerelease"`
+	// Notes are the release notes converted to Markdown.
+	Notes string `json:"notes"`
+}
+
+// NewRelease returns the release of repo published as entry.
+func NewRelease(repo string, entry feed.Entry) Release {
+	return Release{
+		Repo:       repo,
+		Tag:        entry.Tag(),
+		Title:      entry.Title,
+		URL:        entry.Link,
+		Published:  entry.Updated,
+		Author:     entry.Author,
+		Prerelease: slices.Contains(entry.Categories, feed.CategoryPrerelease),
+		Notes:      feed.Markdown(entry.Content),
+	}
+}
+
+// Summary returns the one-line description of r used as message title,
+// such as "owner/repo v1.5.0".
+func (r Release) Summary() string {
+	summary := r.Repo + " " + r.Tag
+	if r.Prerelease {
+		summary += " (prerelease)"
+	}
+	return summary
+}
+
+// Sink delivers release notifications.
+type Sink interface {
+	// Name identifies the sink in logs.
+	Name() string
+	// Send delivers a notification of r. Errors wrapped with Permanent are
+	// not retried.
+	Send(ctx context.Context, r Release) error
+}
+
+// permanentError marks an error retrying will not fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as an error that retrying the delivery will not fix,
+// such as a rejected request.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Retry configures how often deliveries are attempted.
+type Retry struct {
+	// Attempts is the number of attempts per delivery, DefaultAttempts when
+	// zero.
+	Attempts int
+	// Backoff is the wait before the second attempt, doubling after every
+	// further attempt, DefaultBackoff when zero.
+	Backoff time.Duration
+}
+
+// Deliver sends r to sink, retrying failures that are not permanent with
+// exponential backoff until the attempts are used up or ctx is cancelled.
+func Deliver(ctx context.Context, sink Sink, r Release, retry Retry) error {
+	attempts := retry.Attempts
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+	backoff := retry.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = sink.Send(ctx, r); err == nil {
+			log.Debugf("Sent %s to %s", r.Summary(), sink.Name())
+			return nil
+		}
+		if IsPermanent(err) || attempt >= attempts {
+			break
+		}
+		log.Warnf("Error sending %s to %s, retrying in %s: %v", r.Summary(), sink.Name(), backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+	return fmt.Errorf("error sending %s to %s: %w", r.Summary(), sink.Name(), err)
+}
+
+// maxErrorBody limits how much of an error response is included in errors.
+const maxErrorBody = 512
+
+// post sends an HTTP request and turns unsuccessful responses into errors,
+// permanent for client errors other than timeouts and throttling.
+func post(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) // #nosec G704 -- sink URLs are configured by the user
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	err = fmt.Errorf("status code %d: %s", resp.StatusCode, body)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,282 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+)
+
+var release = Release{
+	Repo:      "owner/repo",
+	Tag:       "v1.5.0",
+	Title:     "v1.5.0",
+	URL:       "https://github.com/owner/repo/releases/tag/v1.5.0",
+	Published: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+	Notes:     "## Changes\n\n- Faster",
+}
+
+func TestNewRelease(t *testing.T) {
+	entry := feed.Entry{
+		Title:      "v2.0.0-rc.1",
+		Link:       "https://github.com/owner/repo/releases/tag/v2.0.0-rc.1",
+		Updated:    time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		Author:     "octocat",
+		Content:    "<p>Fixed <strong>bugs</strong></p>",
+		Categories: []string{feed.CategoryPrerelease},
+	}
+	r := NewRelease("owner/repo", entry)
+	if r.Tag != "v2.0.0-rc.1" || !r.Prerelease || r.Author != "octocat" || r.Notes != "Fixed **bugs**" {
+		t.Errorf("NewRelease() = %+v", r)
+	}
+	if got := r.Summary(); got != "owner/repo v2.0.0-rc.1 (prerelease)" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		check    func(t *testing.T, body map[string]any)
+	}{
+		{
+			name: "default",
+			check: func(t *testing.T, body map[string]any) {
+				if body["repo"] != "owner/repo" || body["tag"] != "v1.5.0" || body["notes"] != release.Notes {
+					t.Errorf("body = %v", body)
+				}
+			},
+		},
+		{
+			name:     "template",
+			template: `{"text": {{json .Summary}}, "link": {{json .URL}}}`,
+			check: func(t *testing.T, body map[string]any) {
+				if body["text"] != "owner/repo v1.5.0" || body["link"] != release.URL || len(body) != 2 {
+					t.Errorf("body = %v", body)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			var header http.Header
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("invalid JSON body: %v", err)
+				}
+			}))
+			defer ts.Close()
+
+			w := &Webhook{URL: ts.URL, Header: http.Header{"Authorization": {"Bearer secret"}}}
+			if tt.template != "" {
+				tmpl, err := ParseTemplate(tt.template)
+				if err != nil {
+					t.Fatalf("ParseTemplate() error = %v", err)
+				}
+				w.Template = tmpl
+			}
+			if err := w.Send(context.Background(), release); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if header.Get("Content-Type") != "application/json" || header.Get("Authorization") != "Bearer secret" {
+				t.Errorf("headers = %v", header)
+			}
+			tt.check(t, body)
+		})
+	}
+}
+
+func TestNtfy(t *testing.T) {
+	var header http.Header
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer ts.Close()
+
+	n := &Ntfy{URL: ts.URL + "/releases", Token: "tk_secret", Priority: 4}
+	if err := n.Send(context.Background(), release); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if body != release.Notes {
+		t.Errorf("body = %q, want notes", body)
+	}
+	want := map[string]string{
+		"Title":         "owner/repo v1.5.0",
+		"Click":         release.URL,
+		"Markdown":      "yes",
+		"Priority":      "4",
+		"Authorization": "Bearer tk_secret",
+	}
+	for key, value := range want {
+		if got := header.Get(key); got != value {
+			t.Errorf("header %s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		wantHits int32
+	}{
+		{name: "success", statuses: []int{http.StatusOK}, wantHits: 1},
+		{name: "retried", statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, wantHits: 2},
+		{name: "throttled", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, wantHits: 2},
+		{name: "attempts used up", statuses: []int{http.StatusBadGateway}, wantErr: true, wantHits: 3},
+		{name: "rejected", statuses: []int{http.StatusBadRequest}, wantErr: true, wantHits: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hit := int(hits.Add(1))
+				w.WriteHeader(tt.statuses[min(hit, len(tt.statuses))-1])
+			}))
+			defer ts.Close()
+
+			err := Deliver(context.Background(), &Webhook{URL: ts.URL}, release, Retry{Attempts: 3, Backoff: time.Millisecond})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if hits.Load() != tt.wantHits {
+				t.Errorf("Deliver() sent %d requests, want %d", hits.Load(), tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestSMTP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer l.Close()
+
+	type session struct {
+		commands []string
+		envelope []string
+		data     string
+	}
+	done := make(chan session, 1)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			var s session
+			r := bufio.NewReader(conn)
+			reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+			reply("220 localhost ESMTP")
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					break
+				}
+				line = strings.TrimRight(line, "\r\n")
+				s.commands = append(s.commands, strings.Fields(line)[0])
+				switch {
+				case strings.HasPrefix(line, "EHLO"):
+					reply("250-localhost")
+					reply("250 8BITMIME")
+				case strings.HasPrefix(line, "MAIL"), strings.HasPrefix(line, "RCPT"):
+					s.envelope = append(s.envelope, line)
+					reply("250 OK")
+				case line == "DATA":
+					reply("354 Go ahead")
+					for {
+						dataLine, err := r.ReadString('\n')
+						if err != nil || dataLine == ".\r\n" {
+							break
+						}
+						s.data += dataLine
+					}
+					reply("250 Queued")
+				case line == "QUIT":
+					reply("221 Bye")
+				default:
+					reply("250 OK")
+				}
+				if line == "QUIT" {
+					break
+				}
+			}
+			conn.Close()
+			done <- s
+		}
+	}()
+
+	tests := []struct {
+		name         string
+		from         string
+		to           []string
+		wantEnvelope string
+		wantHeaders  []string
+	}{
+		{
+			name:         "bare addresses",
+			from:         "releases@example.com",
+			to:           []string{"dev@example.com", "ops@example.com"},
+			wantEnvelope: "MAIL FROM:<releases@example.com> BODY=8BITMIME|RCPT TO:<dev@example.com>|RCPT TO:<ops@example.com>",
+			wantHeaders: []string{
+				"From: <releases@example.com>\r\n",
+				"To: <dev@example.com>, <ops@example.com>\r\n",
+			},
+		},
+		{
+			name:         "display names",
+			from:         "Releases <releases@example.com>",
+			to:           []string{"Dev Team <dev@example.com>"},
+			wantEnvelope: "MAIL FROM:<releases@example.com> BODY=8BITMIME|RCPT TO:<dev@example.com>",
+			wantHeaders: []string{
+				"From: \"Releases\" <releases@example.com>\r\n",
+				"To: \"Dev Team\" <dev@example.com>\r\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &SMTP{Addr: l.Addr().String(), From: tt.from, To: tt.to}
+			if err := sink.Send(context.Background(), release); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			s := <-done
+			if got := strings.Join(s.commands, " "); got != "EHLO MAIL "+strings.Repeat("RCPT ", len(tt.to))+"DATA QUIT" {
+				t.Errorf("commands = %s", got)
+			}
+			if got := strings.Join(s.envelope, "|"); got != tt.wantEnvelope {
+				t.Errorf("envelope = %s, want %s", got, tt.wantEnvelope)
+			}
+			for _, want := range append(tt.wantHeaders,
+				"Subject: New release: owner/repo v1.5.0\r\n",
+				release.URL,
+				"## Changes",
+			) {
+				if !strings.Contains(s.data, want) {
+					t.Errorf("message does not contain %q:\n%s", want, s.data)
+				}
+			}
+		})
+	}
+
+	if err := (&SMTP{Addr: l.Addr().String(), From: "releases@example.com"}).Send(context.Background(), release); !IsPermanent(err) {
+		t.Errorf("Send() without recipients error = %v, want permanent", err)
+	}
+}
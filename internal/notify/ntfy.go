@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxNtfyMessage keeps ntfy messages below the size ntfy turns into an
+// attachment.
+const maxNtfyMessage = 4000
+
+// Ntfy publishes to an ntfy topic, or any service accepting ntfy-style
+// posts: the message as the body and the title, click URL and tags as
+// headers.
+type Ntfy struct {
+	// URL is the topic URL, such as https://ntfy.sh/releases.
+	URL string
+	// Token authenticates as a bearer token when set.
+	Token string
+	// Priority is the ntfy message priority from 1 to 5, the server default
+	// when zero.
+	Priority int
+	// Client sends requests. http.DefaultClient is used when nil.
+	Client *http.Client
+}
+
+// Name implements Sink.
+func (n *Ntfy) Name() string {
+	return "ntfy"
+}
+
+// Send implements Sink.
+func (n *Ntfy) Send(ctx context.Context, r Release) error {
+	message := r.Notes
+	if message == "" {
+		message = r.Title
+	}
+	if len(message) > maxNtfyMessage {
+		message = strings.ToValidUTF8(message[:maxNtfyMessage], "") + "…"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, strings.NewReader(message))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Title", r.Summary())
+	req.Header.Set("Tags", "package")
+	req.Header.Set("Markdown", "yes")
+	if r.URL != "" {
+		req.Header.Set("Click", r.URL)
+	}
+	if n.Priority > 0 {
+		req.Header.Set("Priority", strconv.Itoa(n.Priority))
+	}
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+	return post(n.Client, req)
+}
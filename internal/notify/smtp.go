@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// smtpTimeout limits how long a delivery may take.
+const smtpTimeout = 30 * time.Second
+
+// SMTP emails release notifications through an SMTP server, upgrading the
+// connection with STARTTLS when the server offers it.
+type SMTP struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+	From string
+	To   []string
+	// Username and Password authenticate with PLAIN auth when Username is
+	// set, which requires TLS unless the server is on localhost.
+	Username string
+	Password string
+}
+
+// Name implements Sink.
+func (s *SMTP) Name() string {
+	return "smtp"
+}
+
+// Send implements Sink by emailing the release URL and notes.
+func (s *SMTP) Send(ctx context.Context, r Release) error {
+	body := r.Title + "\n" + r.URL + "\n"
+	if r.Notes != "" {
+		body += "\n" + r.Notes + "\n"
+	}
+	return s.Mail(ctx, "New release: "+r.Summary(), "text/plain", body)
+}
+
+// Mail emails body, of the given media type such as text/plain or
+// text/html, with subject to the recipients. Server replies with 5xx codes
+// are permanent errors.
+func (s *SMTP) Mail(ctx context.Context, subject, contentType, body string) error {
+	from, to, err := s.addresses()
+	if err != nil {
+		return Permanent(err)
+	}
+	message, err := message(from, to, subject, contentType, body)
+	if err != nil {
+		return Permanent(err)
+	}
+	err = s.send(ctx, from, to, message)
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
+
+// send delivers message over SMTP, giving the server the bare addresses of
+// the sender and recipients in the envelope.
+func (s *SMTP) send(ctx context.Context, from *mail.Address, to []*mail.Address, message []byte) error {
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(smtpTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := c.Rcpt(recipient.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// addresses parses the sender and recipients, which may have display names.
+func (s *SMTP) addresses() (*mail.Address, []*mail.Address, error) {
+	if len(s.To) == 0 {
+		return nil, nil, errors.New("no recipients")
+	}
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid sender: %w", err)
+	}
+	var to []*mail.Address
+	for _, recipient := range s.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid recipient: %w", err)
+		}
+		to = append(to, address)
+	}
+	return from, to, nil
+}
+
+// message returns the email with subject and body, quoted-printable encoded.
+func message(from *mail.Address, to []*mail.Address, subject, contentType, body string) ([]byte, error) {
+	var recipients []string
+	for _, address := range to {
+		recipients = append(recipients, address.String())
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
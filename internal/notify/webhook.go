@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"text/template"
+)
+
+// DefaultWebhookTemplate is the body posted by a Webhook without a Template:
+// the release as a JSON object.
+const DefaultWebhookTemplate = `{{json .}}`
+
+// templateFuncs are available in webhook templates: json encodes a value as
+// JSON, such as {"text": {{json .Summary}}}.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// ParseTemplate parses a webhook body template. The template is executed
+// with the Release as data and may use the json function to encode values.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(templateFuncs).Parse(text)
+}
+
+// Webhook posts a JSON body rendered from a Go template to a URL.
+type Webhook struct {
+	URL string
+	// Template renders the request body, DefaultWebhookTemplate when nil.
+	Template *template.Template
+	// Header is sent with every request, e.g. to authenticate.
+	Header http.Header
+	// Client sends requests. http.DefaultClient is used when nil.
+	Client *http.Client
+}
+
+// Name implements Sink.
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Send implements Sink.
+func (w *Webhook) Send(ctx context.Context, r Release) error {
+	tmpl := w.Template
+	if tmpl == nil {
+		tmpl = template.Must(ParseTemplate(DefaultWebhookTemplate))
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, r); err != nil {
+		return Permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, &body)
+	if err != nil {
+		return Permanent(err)
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return post(w.Client, req)
+}
@@ -0,0 +1,466 @@
+// Package oci is a minimal client for the OCI distribution API used by
+// container registries such as Docker Hub, Quay and GHCR.
+package oci
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxDocumentSize limits how much of a manifest, config blob, tag list or token response is read.
+const maxDocumentSize = 4 << 20
+
+// Tag listing page size and the most pages Tags follows.
+const (
+	tagsPageSize = 1000
+	maxTagPages  = 50
+)
+
+// Media types of the manifests requested from registries.
+const (
+	mediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	manifestAcceptHeader    = mediaTypeOCIIndex + ", " + mediaTypeOCIManifest + ", " + mediaTypeDockerList + ", " + mediaTypeDockerManifest
+)
+
+// Docker Hub naming conventions applied to short image references.
+const (
+	defaultRegistry        = "docker.io"
+	defaultRegistryAPIHost = "registry-1.docker.io"
+	officialImageNamespace = "library"
+	defaultTag             = "latest"
+)
+
+// SourceLabel is the image label that names the source repository of an image.
+const SourceLabel = "org.opencontainers.image.source"
+
+// ErrNotFound is returned when a registry reports that an image does not exist.
+var ErrNotFound = errors.New("image not found")
+
+// ErrNoSourceLabel is returned by Source when an image does not name its source repository.
+var ErrNoSourceLabel = errors.New("image has no " + SourceLabel + " label")
+
+// Reference is a parsed container image reference.
+type Reference struct {
+	// Registry is the registry host, e.g. "docker.io" or "ghcr.io".
+	Registry string
+	// Repository is the image name within the registry, e.g. "library/nginx".
+	Repository string
+	// Tag is the image tag, "latest" when neither a tag nor a digest was given.
+	Tag string
+	// Digest is the content digest when the reference is pinned with @sha256:...
+	Digest string
+}
+
+// ParseReference parses an image reference such as "nginx:1.27",
+// "quay.io/prometheus/node-exporter" or "ghcr.io/owner/image@sha256:...".
+func ParseReference(ref string) (Reference, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.ContainsAny(ref, " \t$") {
+		return Reference{}, fmt.Errorf("invalid image reference %q", ref)
+	}
+
+	var r Reference
+	name := ref
+	if at := strings.Index(name, "@"); at >= 0 {
+		r.Digest = name[at+1:]
+		name = name[:at]
+	}
+	// a colon after the last slash separates the tag, earlier ones belong to a registry port
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		r.Tag = name[colon+1:]
+		name = name[:colon]
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		r.Registry = parts[0]
+		r.Repository = parts[1]
+	} else {
+		r.Registry = defaultRegistry
+		r.Repository = name
+	}
+	if r.Registry == "index.docker.io" {
+		r.Registry = defaultRegistry
+	}
+	if r.Registry == defaultRegistry && !strings.Contains(r.Repository, "/") {
+		r.Repository = officialImageNamespace + "/" + r.Repository
+	}
+	if r.Repository == "" || strings.HasSuffix(r.Repository, "/") {
+		return Reference{}, fmt.Errorf("invalid image reference %q", ref)
+	}
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = defaultTag
+	}
+	return r, nil
+}
+
+// Name returns the registry and repository without tag or digest.
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the full reference including tag or digest.
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Client talks to container registries over the OCI distribution API.
+type Client struct {
+	// HTTPClient performs requests. http.DefaultClient is used when nil.
+	HTTPClient *http.Client
+	// PlainHTTP talks to registries over http:// instead of https://. It is
+	// only meant for local registry stand-ins in tests.
+	PlainHTTP bool
+	// Username and Password authenticate token requests when set.
+	Username string
+	Password string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// descriptor points at another manifest or blob.
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Platform  *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform,omitempty"`
+}
+
+// manifest covers the fields of image indexes and image manifests that are used here.
+type manifest struct {
+	MediaType   string            `json:"mediaType"`
+	Manifests   []descriptor      `json:"manifests"`
+	Config      descriptor        `json:"config"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// imageConfig is the part of an image config blob that carries labels.
+type imageConfig struct {
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// Labels returns the labels and annotations of an image, merged so that the
+// config labels win. For multi-platform images the linux/amd64 image, or the
+// first one listed, is inspected.
+func (c *Client) Labels(ref Reference) (map[string]string, error) {
+	target := ref.Digest
+	if target == "" {
+		target = ref.Tag
+	}
+
+	labels := make(map[string]string)
+	m, err := c.manifest(ref, target)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range m.Annotations {
+		labels[k] = v
+	}
+
+	if len(m.Manifests) > 0 {
+		chosen := m.Manifests[0]
+		for _, d := range m.Manifests {
+			if d.Platform != nil && d.Platform.OS == "linux" && d.Platform.Architecture == "amd64" {
+				chosen = d
+				break
+			}
+		}
+		m, err = c.manifest(ref, chosen.Digest)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range m.Annotations {
+			labels[k] = v
+		}
+	}
+
+	if m.Config.Digest == "" {
+		return labels, nil
+	}
+	var config imageConfig
+	if err := c.getJSON(ref, "/blobs/"+m.Config.Digest, "", &config); err != nil {
+		return nil, err
+	}
+	for k, v := range config.Config.Labels {
+		labels[k] = v
+	}
+	return labels, nil
+}
+
+// Source returns the org.opencontainers.image.source label of an image.
+func (c *Client) Source(ref Reference) (string, error) {
+	labels, err := c.Labels(ref)
+	if err != nil {
+		return "", err
+	}
+	source := labels[SourceLabel]
+	if source == "" {
+		return "", fmt.Errorf("%s: %w", ref, ErrNoSourceLabel)
+	}
+	return source, nil
+}
+
+// Tags returns the tags of the image repository of ref, following the
+// pagination links of the tags/list endpoint.
+func (c *Client) Tags(ref Reference) ([]string, error) {
+	var tags []string
+	query := url.Values{"n": {strconv.Itoa(tagsPageSize)}}.Encode()
+	for page := 0; page < maxTagPages; page++ {
+		resp, err := c.get(ref, "/tags/list?"+query, "")
+		if err != nil {
+			return nil, err
+		}
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(&list)
+		link := resp.Header.Get("Link")
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding tags of %s: %w", ref.Name(), err)
+		}
+		tags = append(tags, list.Tags...)
+
+		next, ok := nextPage(link)
+		if !ok {
+			return tags, nil
+		}
+		query = next
+	}
+	log.Warnf("Listed only the first %d tags of %s", len(tags), ref.Name())
+	return tags, nil
+}
+
+// Digest returns the content digest of the manifest or index ref points at,
+// as reported by the registry without downloading the manifest.
+func (c *Client) Digest(ref Reference) (string, error) {
+	target := ref.Digest
+	if target == "" {
+		target = ref.Tag
+	}
+	resp, err := c.request(http.MethodHead, ref, "/manifests/"+target, manifestAcceptHeader)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry did not report the digest of %s", ref)
+	}
+	return digest, nil
+}
+
+// nextPage returns the query of the rel="next" URL in a Link header, such as
+// </v2/org/app/tags/list?last=v1.2.0&n=1000>; rel="next".
+func nextPage(link string) (string, bool) {
+	target, params, ok := strings.Cut(link, ";")
+	if !ok || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+		return "", false
+	}
+	u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+	if err != nil || u.RawQuery == "" {
+		return "", false
+	}
+	return u.RawQuery, true
+}
+
+// manifest fetches the manifest or index of ref at a tag or digest.
+func (c *Client) manifest(ref Reference, target string) (manifest, error) {
+	var m manifest
+	err := c.getJSON(ref, "/manifests/"+target, manifestAcceptHeader, &m)
+	return m, err
+}
+
+// getJSON fetches a path below /v2/<repository> and decodes its JSON body.
+func (c *Client) getJSON(ref Reference, path, accept string, v any) error {
+	resp, err := c.get(ref, path, accept)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(v); err != nil {
+		return fmt.Errorf("error decoding %s response for %s: %w", path, ref.Name(), err)
+	}
+	return nil
+}
+
+// get performs an authenticated GET below /v2/<repository>.
+func (c *Client) get(ref Reference, path, accept string) (*http.Response, error) {
+	return c.request(http.MethodGet, ref, path, accept)
+}
+
+// request performs an authenticated request below /v2/<repository>,
+// answering a bearer token challenge once if the registry asks for one.
+func (c *Client) request(method string, ref Reference, path, accept string) (*http.Response, error) {
+	endpoint := c.baseURL(ref.Registry) + "/v2/" + ref.Repository + path
+	scope := "repository:" + ref.Repository + ":pull"
+
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequest(method, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if token := c.token(ref.Registry, scope); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.httpClient().Do(req) // #nosec G704 -- registry hosts come from image references being imported
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && attempt == 0:
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if err := c.authenticate(ref.Registry, scope, challenge); err != nil {
+				return nil, err
+			}
+			continue
+		case resp.StatusCode == http.StatusNotFound:
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %w", ref.Name(), ErrNotFound)
+		case resp.StatusCode != http.StatusOK:
+			resp.Body.Close()
+			return nil, fmt.Errorf("registry request for %s failed, status code: %d", ref.Name(), resp.StatusCode)
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("registry request for %s is unauthorized", ref.Name())
+}
+
+// authenticate fetches a bearer token for scope as described by a
+// WWW-Authenticate challenge and remembers it for later requests.
+func (c *Client) authenticate(registry, scope, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		return fmt.Errorf("registry %s requires unsupported authentication %q", registry, challenge)
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil {
+		return fmt.Errorf("invalid token realm for registry %s: %w", registry, err)
+	}
+	query := tokenURL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		query.Set("scope", params["scope"])
+	} else {
+		query.Set("scope", scope)
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", tokenURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := c.httpClient().Do(req) // #nosec G704 -- token realm is announced by the registry
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch registry token for %s, status code: %d", registry, resp.StatusCode)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(&body); err != nil {
+		return fmt.Errorf("error decoding registry token for %s: %w", registry, err)
+	}
+	token := body.Token
+	if token == "" {
+		token = body.AccessToken
+	}
+	if token == "" {
+		return fmt.Errorf("registry %s returned an empty token", registry)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = make(map[string]string)
+	}
+	c.tokens[registry+" "+scope] = token
+	log.Debugf("Fetched registry token for %s %s", registry, scope)
+	return nil
+}
+
+// token returns a previously fetched bearer token for scope.
+func (c *Client) token(registry, scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[registry+" "+scope]
+}
+
+// baseURL returns the API base URL of a registry host.
+func (c *Client) baseURL(registry string) string {
+	if registry == defaultRegistry {
+		registry = defaultRegistryAPIHost
+	}
+	if c.PlainHTTP {
+		return "http://" + registry
+	}
+	return "https://" + registry
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and parameters.
+func parseChallenge(header string) (string, map[string]string) {
+	params := make(map[string]string)
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			params[strings.ToLower(key)] = value
+		}
+	}
+	return scheme, params
+}
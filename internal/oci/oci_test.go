@@ -0,0 +1,154 @@
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Reference
+		wantErr bool
+	}{
+		{input: "nginx", want: Reference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{input: "grafana/grafana:11.0.0", want: Reference{Registry: "docker.io", Repository: "grafana/grafana", Tag: "11.0.0"}},
+		{input: "quay.io/prometheus/node-exporter:v1.8.0", want: Reference{Registry: "quay.io", Repository: "prometheus/node-exporter", Tag: "v1.8.0"}},
+		{input: "localhost:5000/app", want: Reference{Registry: "localhost:5000", Repository: "app", Tag: "latest"}},
+		{input: "ghcr.io/owner/image@sha256:abc", want: Reference{Registry: "ghcr.io", Repository: "owner/image", Digest: "sha256:abc"}},
+		{input: "index.docker.io/library/redis:7", want: Reference{Registry: "docker.io", Repository: "library/redis", Tag: "7"}},
+		{input: "${BASE_IMAGE}", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseReference(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseReference() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestRegistry returns a registry stand-in that requires a bearer token and
+// serves a multi-platform image "org/app" and a single-platform image "org/bare"
+// without a source label.
+func newTestRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if !strings.HasPrefix(r.URL.Query().Get("scope"), "repository:org/") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"token":"test-token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/org/app/tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/org/app/tags/list?last=1.1&n=1000>; rel="next"`)
+				fmt.Fprint(w, `{"name":"org/app","tags":["1.0","1.1"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"name":"org/app","tags":["2.0"]}`)
+		case "/v2/org/app/manifests/1.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+			w.Header().Set("Content-Type", mediaTypeOCIIndex)
+			fmt.Fprint(w, `{"mediaType":"`+mediaTypeOCIIndex+`","manifests":[
+				{"digest":"sha256:arm","platform":{"os":"linux","architecture":"arm64"}},
+				{"digest":"sha256:amd","platform":{"os":"linux","architecture":"amd64"}}]}`)
+		case "/v2/org/app/manifests/sha256:amd":
+			fmt.Fprint(w, `{"mediaType":"`+mediaTypeOCIManifest+`","config":{"digest":"sha256:cfg"}}`)
+		case "/v2/org/app/blobs/sha256:cfg":
+			fmt.Fprint(w, `{"config":{"Labels":{"org.opencontainers.image.source":"https://github.com/org/app"}}}`)
+		case "/v2/org/bare/manifests/latest":
+			fmt.Fprint(w, `{"mediaType":"`+mediaTypeDockerManifest+`","config":{"digest":"sha256:bare"}}`)
+		case "/v2/org/bare/blobs/sha256:bare":
+			fmt.Fprint(w, `{"config":{"Labels":{"maintainer":"someone"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestSource(t *testing.T) {
+	registry := newTestRegistry(t)
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+	client := &Client{PlainHTTP: true}
+
+	ref, _ := ParseReference(host + "/org/app:1.0")
+	source, err := client.Source(ref)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+	if source != "https://github.com/org/app" {
+		t.Errorf("Source() = %v, want https://github.com/org/app", source)
+	}
+
+	ref, _ = ParseReference(host + "/org/bare")
+	if _, err := client.Source(ref); !errors.Is(err, ErrNoSourceLabel) {
+		t.Errorf("Source() error = %v, want %v", err, ErrNoSourceLabel)
+	}
+
+	ref, _ = ParseReference(host + "/org/missing")
+	if _, err := client.Source(ref); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Source() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestTags(t *testing.T) {
+	registry := newTestRegistry(t)
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+	client := &Client{PlainHTTP: true}
+
+	ref, _ := ParseReference(host + "/org/app")
+	tags, err := client.Tags(ref)
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	if strings.Join(tags, " ") != "1.0 1.1 2.0" {
+		t.Errorf("Tags() = %v, want 1.0 1.1 2.0 across two pages", tags)
+	}
+
+	ref, _ = ParseReference(host + "/org/app:1.0")
+	if digest, err := client.Digest(ref); err != nil || digest != "sha256:index" {
+		t.Errorf("Digest() = %q, %v, want sha256:index", digest, err)
+	}
+	ref, _ = ParseReference(host + "/org/bare")
+	if _, err := client.Digest(ref); err == nil {
+		t.Error("Digest() should fail when the registry does not report a digest")
+	}
+	ref, _ = ParseReference(host + "/org/missing")
+	if _, err := client.Tags(ref); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Tags() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`)
+	if scheme != "Bearer" {
+		t.Errorf("scheme = %v, want Bearer", scheme)
+	}
+	if params["realm"] != "https://auth.docker.io/token" || params["service"] != "registry.docker.io" || params["scope"] != "repository:library/nginx:pull" {
+		t.Errorf("params = %v", params)
+	}
+}
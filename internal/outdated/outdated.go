@@ -0,0 +1,141 @@
+// Package outdated compares a pinned release against a repository's release
+// feed and writes upgrade briefs from the release notes in between.
+package outdated
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+)
+
+// ErrNoReleases is returned by Compare for a feed without entries.
+var ErrNoReleases = errors.New("no releases found")
+
+// Result is how far a pinned version is behind the latest release.
+type Result struct {
+	Current string
+	Latest  string
+	// Behind is the number of releases newer than Current.
+	Behind int
+	// Partial is set when Current is older than every release in the feed
+	// or could not be found in it, so Behind is a lower bound.
+	Partial bool
+	// ByDate is set when versions could not be compared as semver and the
+	// feed order by date was used instead.
+	ByDate bool
+	// Skipped are the releases newer than Current, oldest first.
+	Skipped []feed.Entry
+}
+
+// Compare finds the releases in entries that are newer than current. Tags
+// are compared as semver where both sides parse, ignoring prereleases unless
+// current is one; otherwise entries are ordered by their update time.
+func Compare(current string, entries []feed.Entry) (Result, error) {
+	if len(entries) == 0 {
+		return Result{}, ErrNoReleases
+	}
+	if pinned, err := feed.ParseVersion(current); err == nil {
+		if result, ok := compareSemver(current, pinned, entries); ok {
+			return result, nil
+		}
+	}
+	return compareDates(current, entries), nil
+}
+
+type release struct {
+	version *semver.Version
+	entry   feed.Entry
+}
+
+func compareSemver(current string, pinned *semver.Version, entries []feed.Entry) (Result, bool) {
+	var releases []release
+	for _, entry := range entries {
+		version, err := feed.ParseVersion(entry.Tag())
+		if err != nil || (version.Prerelease() != "" && pinned.Prerelease() == "") {
+			continue
+		}
+		releases = append(releases, release{version: version, entry: entry})
+	}
+	if len(releases) == 0 {
+		return Result{}, false
+	}
+	sort.SliceStable(releases, func(i, j int) bool { return releases[i].version.GreaterThan(releases[j].version) })
+
+	result := Result{Current: current, Latest: releases[0].entry.Tag(), Partial: true}
+	for _, r := range releases {
+		if r.version.GreaterThan(pinned) {
+			result.Behind++
+			result.Skipped = append([]feed.Entry{r.entry}, result.Skipped...)
+		} else {
+			result.Partial = false
+		}
+	}
+	return result, true
+}
+
+func compareDates(current string, entries []feed.Entry) Result {
+	sorted := append([]feed.Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Updated.After(sorted[j].Updated) })
+
+	result := Result{Current: current, Latest: sorted[0].Tag(), ByDate: true, Behind: len(sorted), Partial: true}
+	for i, entry := range sorted {
+		if sameTag(entry.Tag(), current) {
+			result.Behind, result.Partial = i, false
+			break
+		}
+	}
+	for i := result.Behind - 1; i >= 0; i-- {
+		result.Skipped = append(result.Skipped, sorted[i])
+	}
+	return result
+}
+
+func sameTag(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+// BehindString formats Behind for display, marking lower bounds with a +.
+func (r Result) BehindString() string {
+	if r.Partial {
+		return fmt.Sprintf("%d+", r.Behind)
+	}
+	return fmt.Sprint(r.Behind)
+}
+
+// Brief returns a Markdown upgrade brief for repo that concatenates the
+// release notes of every skipped release, oldest first.
+func Brief(repo string, r Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Upgrading %s from %s to %s\n\n", repo, r.Current, r.Latest)
+	fmt.Fprintf(&b, "%s releases behind", r.BehindString())
+	if r.Partial {
+		b.WriteString("; the pinned version is not among the releases in the feed, so there may be more")
+	}
+	b.WriteString(".\n")
+
+	for _, entry := range r.Skipped {
+		fmt.Fprintf(&b, "\n## %s", entry.Tag())
+		if entry.Title != "" && entry.Title != entry.Tag() {
+			fmt.Fprintf(&b, ": %s", entry.Title)
+		}
+		b.WriteString("\n\n")
+		if !entry.Updated.IsZero() {
+			fmt.Fprintf(&b, "Released %s", entry.Updated.Format("2006-01-02"))
+			if entry.Link != "" {
+				fmt.Fprintf(&b, " · [release page](%s)", entry.Link)
+			}
+			b.WriteString("\n\n")
+		}
+		if notes := feed.Markdown(entry.Content); notes != "" {
+			b.WriteString(notes + "\n")
+		} else {
+			b.WriteString("_No release notes._\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package outdated
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+)
+
+func entry(tag string, day int, notes string) feed.Entry {
+	return feed.Entry{
+		Title:   tag,
+		Link:    "https://github.com/owner/repo/releases/tag/" + tag,
+		Updated: time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC),
+		Content: notes,
+	}
+}
+
+func TestCompare(t *testing.T) {
+	semverEntries := []feed.Entry{
+		entry("v1.6.0-rc.1", 20, ""),
+		entry("v1.5.1", 15, "<p>Fix</p>"),
+		entry("v1.5.0", 10, "<p>Feature</p>"),
+		entry("v1.4.2", 5, ""),
+		entry("v1.4.1", 1, ""),
+	}
+	dateEntries := []feed.Entry{
+		entry("release-2026-01-15", 15, ""),
+		entry("release-2026-01-20", 20, ""),
+		entry("nightly", 25, ""),
+	}
+
+	tests := []struct {
+		name        string
+		current     string
+		entries     []feed.Entry
+		wantLatest  string
+		wantBehind  string
+		wantByDate  bool
+		wantSkipped []string
+	}{
+		{
+			name:        "Semver behind",
+			current:     "v1.4.2",
+			entries:     semverEntries,
+			wantLatest:  "v1.5.1",
+			wantBehind:  "2",
+			wantSkipped: []string{"v1.5.0", "v1.5.1"},
+		},
+		{
+			name:       "Semver without v prefix",
+			current:    "1.5.1",
+			entries:    semverEntries,
+			wantLatest: "v1.5.1",
+			wantBehind: "0",
+		},
+		{
+			name:        "Prerelease pin",
+			current:     "v1.5.1-beta.1",
+			entries:     semverEntries,
+			wantLatest:  "v1.6.0-rc.1",
+			wantBehind:  "2",
+			wantSkipped: []string{"v1.5.1", "v1.6.0-rc.1"},
+		},
+		{
+			name:        "Older than the feed",
+			current:     "v1.0.0",
+			entries:     semverEntries[:3],
+			wantLatest:  "v1.5.1",
+			wantBehind:  "2+",
+			wantSkipped: []string{"v1.5.0", "v1.5.1"},
+		},
+		{
+			name:        "Date fallback",
+			current:     "release-2026-01-15",
+			entries:     dateEntries,
+			wantLatest:  "nightly",
+			wantBehind:  "2",
+			wantByDate:  true,
+			wantSkipped: []string{"release-2026-01-20", "nightly"},
+		},
+		{
+			name:        "Date fallback with unknown pin",
+			current:     "edge",
+			entries:     dateEntries,
+			wantLatest:  "nightly",
+			wantBehind:  "3+",
+			wantByDate:  true,
+			wantSkipped: []string{"release-2026-01-15", "release-2026-01-20", "nightly"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Compare(tt.current, tt.entries)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if got.Latest != tt.wantLatest || got.BehindString() != tt.wantBehind || got.ByDate != tt.wantByDate {
+				t.Errorf("Compare() = latest %v, behind %v, by date %v, want %v, %v, %v",
+					got.Latest, got.BehindString(), got.ByDate, tt.wantLatest, tt.wantBehind, tt.wantByDate)
+			}
+			var skipped []string
+			for _, e := range got.Skipped {
+				skipped = append(skipped, e.Tag())
+			}
+			if strings.Join(skipped, ",") != strings.Join(tt.wantSkipped, ",") {
+				t.Errorf("Compare() skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+
+	if _, err := Compare("v1.0.0", nil); !errors.Is(err, ErrNoReleases) {
+		t.Errorf("Compare() error = %v, want %v", err, ErrNoReleases)
+	}
+}
+
+func TestBrief(t *testing.T) {
+	result, err := Compare("v1.4.2", []feed.Entry{
+		entry("v1.5.1", 15, "<p>Fix <code>nil</code> panic</p>"),
+		entry("v1.5.0", 10, ""),
+		entry("v1.4.2", 5, ""),
+	})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+
+	brief := Brief("owner/repo", result)
+	for _, want := range []string{
+		"# Upgrading owner/repo from v1.4.2 to v1.5.1",
+		"2 releases behind.",
+		"## v1.5.0\n\nReleased 2026-01-10 · [release page](https://github.com/owner/repo/releases/tag/v1.5.0)\n\n_No release notes._",
+		"## v1.5.1\n\nReleased 2026-01-15",
+		"Fix `nil` panic",
+	} {
+		if !strings.Contains(brief, want) {
+			t.Errorf("Brief() is missing %q:\n%s", want, brief)
+		}
+	}
+	if strings.Index(brief, "## v1.5.0") > strings.Index(brief, "## v1.5.1") {
+		t.Error("Brief() should list releases oldest first")
+	}
+}
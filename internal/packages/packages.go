@@ -0,0 +1,434 @@
+// Package packages reads the dependencies of npm, Python and Rust manifests
+// and maps them to GitHub repositories through package registry metadata.
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Ecosystem is the package registry a dependency is published to.
+type Ecosystem string
+
+// Supported ecosystems.
+const (
+	NPM    Ecosystem = "npm"
+	PyPI   Ecosystem = "pypi"
+	Crates Ecosystem = "crates"
+)
+
+// Package is a dependency declared in a manifest.
+type Package struct {
+	Ecosystem Ecosystem
+	Name      string
+	// URL is a source URL given in the manifest itself, such as a git
+	// dependency. It is used instead of a registry lookup when set.
+	URL string
+	// Line is where the dependency is declared, or 0 when unknown.
+	Line int
+}
+
+// Find returns the package.json, requirements*.txt, pyproject.toml and
+// Cargo.toml files below dir, skipping vendored and virtualenv directories.
+func Find(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "node_modules", ".venv", "venv", "target", "__pycache__":
+				if path != dir {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if IsManifest(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// IsManifest reports whether a file name is a supported dependency manifest.
+func IsManifest(name string) bool {
+	return name == "package.json" || name == "pyproject.toml" || name == "Cargo.toml" || isRequirements(name)
+}
+
+func isRequirements(name string) bool {
+	return strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt")
+}
+
+// Parse returns the dependencies declared in a manifest, chosen by its name.
+// Local path and workspace dependencies are left out.
+func Parse(path string, data []byte) ([]Package, error) {
+	name := filepath.Base(path)
+	switch {
+	case name == "package.json":
+		return ParsePackageJSON(data)
+	case name == "pyproject.toml":
+		return ParsePyProject(data)
+	case name == "Cargo.toml":
+		return ParseCargo(data)
+	case isRequirements(name):
+		return ParseRequirements(data), nil
+	}
+	return nil, fmt.Errorf("unsupported manifest %s", name)
+}
+
+// packageJSONSections are the package.json keys that list dependencies.
+var packageJSONSections = map[string]bool{
+	"dependencies":         true,
+	"devDependencies":      true,
+	"peerDependencies":     true,
+	"optionalDependencies": true,
+}
+
+// ParsePackageJSON returns the dependencies of a package.json file. It walks
+// the JSON tokens rather than unmarshalling so every dependency keeps its line.
+func ParsePackageJSON(data []byte) ([]Package, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	var packages []Package
+	seen := make(map[string]bool)
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if !packageJSONSections[fmt.Sprint(key)] {
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := expectDelim(decoder, '{'); err != nil {
+			return nil, err
+		}
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			line := 1 + bytes.Count(data[:decoder.InputOffset()], []byte("\n"))
+			var spec string
+			if err := decoder.Decode(&spec); err != nil {
+				return nil, err
+			}
+			pkg, ok := npmPackage(fmt.Sprint(name), spec)
+			if !ok || seen[pkg.Name] {
+				continue
+			}
+			seen[pkg.Name] = true
+			pkg.Line = line
+			packages = append(packages, pkg)
+		}
+		if err := expectDelim(decoder, '}'); err != nil {
+			return nil, err
+		}
+	}
+	return packages, nil
+}
+
+func expectDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != want {
+		return fmt.Errorf("expected %q in package.json, found %v", want, token)
+	}
+	return nil
+}
+
+// npmPackage interprets an npm version spec, following npm: aliases and
+// recognising GitHub shorthands and git URLs. Local specs are rejected.
+func npmPackage(name, spec string) (Package, bool) {
+	pkg := Package{Ecosystem: NPM, Name: name}
+	switch {
+	case strings.HasPrefix(spec, "file:"), strings.HasPrefix(spec, "link:"),
+		strings.HasPrefix(spec, "workspace:"), strings.HasPrefix(spec, "portal:"):
+		return pkg, false
+	case strings.HasPrefix(spec, "npm:"):
+		alias := strings.TrimPrefix(spec, "npm:")
+		if at := strings.LastIndex(alias, "@"); at > 0 {
+			alias = alias[:at]
+		}
+		pkg.Name = alias
+	case strings.HasPrefix(spec, "github:"):
+		pkg.URL = "https://github.com/" + strings.TrimPrefix(spec, "github:")
+	case strings.Contains(spec, "://"), strings.HasPrefix(spec, "git@"):
+		pkg.URL = spec
+	case strings.Count(spec, "/") == 1 && !strings.HasPrefix(spec, "@") && !strings.ContainsAny(spec, " <>=^~*"):
+		pkg.URL = "https://github.com/" + spec
+	}
+	if pkg.URL != "" {
+		pkg.URL, _, _ = strings.Cut(pkg.URL, "#")
+	}
+	return pkg, true
+}
+
+// ParseRequirements returns the packages listed in a pip requirements file.
+// Options such as -r and -e and bare URLs are skipped.
+func ParseRequirements(data []byte) []Package {
+	var packages []Package
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		text, _, _ := strings.Cut(scanner.Text(), " #")
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "-") {
+			continue
+		}
+		if pkg, ok := pep508Package(text); ok {
+			pkg.Line = lineNumber
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// pep508Package returns the distribution name of a PEP 508 requirement such
+// as "requests[socks]>=2.31; python_version>'3.8'" or "pkg @ https://...".
+func pep508Package(requirement string) (Package, bool) {
+	requirement = strings.TrimSpace(requirement)
+	end := strings.IndexFunc(requirement, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.')
+	})
+	name := requirement
+	if end >= 0 {
+		name = requirement[:end]
+	}
+	if name == "" {
+		return Package{}, false
+	}
+
+	rest := strings.TrimSpace(requirement[len(name):])
+	if strings.HasPrefix(rest, "[") {
+		_, rest, _ = strings.Cut(rest, "]")
+		rest = strings.TrimSpace(rest)
+	}
+	pkg := Package{Ecosystem: PyPI, Name: name}
+	switch {
+	case strings.HasPrefix(rest, "@"):
+		url, _, _ := strings.Cut(strings.TrimPrefix(rest, "@"), ";")
+		url, _, _ = strings.Cut(strings.TrimSpace(url), "#")
+		// drop the @ref of VCS URLs such as git+https://github.com/o/r@v1.0
+		if at := strings.LastIndex(url, "@"); at > strings.LastIndex(url, "/") {
+			url = url[:at]
+		}
+		pkg.URL = url
+	case strings.Contains(rest, "://"):
+		// a bare URL or archive path rather than a named requirement
+		return Package{}, false
+	}
+	return pkg, true
+}
+
+// pyProject holds the dependency tables of a pyproject.toml file, covering
+// PEP 621, PEP 735 dependency groups and Poetry.
+type pyProject struct {
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	DependencyGroups map[string][]any `toml:"dependency-groups"`
+	Tool             struct {
+		Poetry struct {
+			Dependencies    map[string]any `toml:"dependencies"`
+			DevDependencies map[string]any `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]any `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// ParsePyProject returns the dependencies of a pyproject.toml file.
+func ParsePyProject(data []byte) ([]Package, error) {
+	var project pyProject
+	if err := toml.Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+
+	var requirements []string
+	requirements = append(requirements, project.Project.Dependencies...)
+	for _, extra := range sortedKeys(project.Project.OptionalDependencies) {
+		requirements = append(requirements, project.Project.OptionalDependencies[extra]...)
+	}
+	for _, group := range sortedKeys(project.DependencyGroups) {
+		for _, entry := range project.DependencyGroups[group] {
+			// entries may also be {include-group = "..."} tables
+			if requirement, ok := entry.(string); ok {
+				requirements = append(requirements, requirement)
+			}
+		}
+	}
+
+	collector := newCollector(data)
+	for _, requirement := range requirements {
+		if pkg, ok := pep508Package(requirement); ok {
+			collector.add(pkg, quotedPrefix(pkg.Name))
+		}
+	}
+
+	poetryTables := []map[string]any{project.Tool.Poetry.Dependencies, project.Tool.Poetry.DevDependencies}
+	for _, group := range sortedKeys(project.Tool.Poetry.Group) {
+		poetryTables = append(poetryTables, project.Tool.Poetry.Group[group].Dependencies)
+	}
+	for _, table := range poetryTables {
+		for _, name := range sortedKeys(table) {
+			if strings.EqualFold(name, "python") {
+				continue
+			}
+			if pkg, ok := tomlPackage(PyPI, name, table[name]); ok {
+				collector.add(pkg, keyPrefix(name))
+			}
+		}
+	}
+	return collector.packages, nil
+}
+
+// cargoManifest holds the dependency tables of a Cargo.toml file.
+type cargoManifest struct {
+	Dependencies      map[string]any `toml:"dependencies"`
+	DevDependencies   map[string]any `toml:"dev-dependencies"`
+	BuildDependencies map[string]any `toml:"build-dependencies"`
+	Workspace         struct {
+		Dependencies map[string]any `toml:"dependencies"`
+	} `toml:"workspace"`
+	Target map[string]struct {
+		Dependencies      map[string]any `toml:"dependencies"`
+		DevDependencies   map[string]any `toml:"dev-dependencies"`
+		BuildDependencies map[string]any `toml:"build-dependencies"`
+	} `toml:"target"`
+}
+
+// ParseCargo returns the dependencies of a Cargo.toml file, including
+// workspace and platform-specific dependency tables.
+func ParseCargo(data []byte) ([]Package, error) {
+	var manifest cargoManifest
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	tables := []map[string]any{manifest.Dependencies, manifest.DevDependencies, manifest.BuildDependencies, manifest.Workspace.Dependencies}
+	for _, target := range sortedKeys(manifest.Target) {
+		t := manifest.Target[target]
+		tables = append(tables, t.Dependencies, t.DevDependencies, t.BuildDependencies)
+	}
+
+	collector := newCollector(data)
+	for _, table := range tables {
+		for _, name := range sortedKeys(table) {
+			if pkg, ok := tomlPackage(Crates, name, table[name]); ok {
+				collector.add(pkg, keyPrefix(name))
+			}
+		}
+	}
+	return collector.packages, nil
+}
+
+// tomlPackage interprets a Cargo or Poetry dependency, which is either a
+// version string or a table that may rename the package or point at git.
+func tomlPackage(ecosystem Ecosystem, name string, value any) (Package, bool) {
+	pkg := Package{Ecosystem: ecosystem, Name: name}
+	table, ok := value.(map[string]any)
+	if !ok {
+		return pkg, true
+	}
+	if _, ok := table["path"]; ok {
+		return pkg, false
+	}
+	if workspace, _ := table["workspace"].(bool); workspace {
+		return pkg, false
+	}
+	if rename, ok := table["package"].(string); ok && rename != "" {
+		pkg.Name = rename
+	}
+	if git, ok := table["git"].(string); ok {
+		pkg.URL = git
+	}
+	return pkg, true
+}
+
+// collector dedupes packages within a manifest and finds the line each one
+// is declared on, as the TOML decoder does not report positions.
+type collector struct {
+	lines    []string
+	seen     map[string]bool
+	packages []Package
+}
+
+func newCollector(data []byte) *collector {
+	return &collector{lines: strings.Split(string(data), "\n"), seen: make(map[string]bool)}
+}
+
+func (c *collector) add(pkg Package, match func(line string) bool) {
+	if c.seen[pkg.Name] {
+		return
+	}
+	c.seen[pkg.Name] = true
+	for i, line := range c.lines {
+		if match(strings.TrimSpace(line)) {
+			pkg.Line = i + 1
+			break
+		}
+	}
+	c.packages = append(c.packages, pkg)
+}
+
+// keyPrefix matches a TOML line that declares name as a key.
+func keyPrefix(name string) func(string) bool {
+	return func(line string) bool {
+		rest, ok := strings.CutPrefix(line, name)
+		if !ok {
+			rest, ok = strings.CutPrefix(line, `"`+name+`"`)
+		}
+		rest = strings.TrimSpace(rest)
+		return ok && (strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, "."))
+	}
+}
+
+// quotedPrefix matches a TOML line holding a requirement string for name.
+func quotedPrefix(name string) func(string) bool {
+	return func(line string) bool {
+		for _, quote := range []string{`"`, `'`} {
+			i := strings.Index(line, quote+name)
+			if i < 0 {
+				continue
+			}
+			rest := line[i+1+len(name):]
+			if rest == "" || !strings.ContainsAny(rest[:1], "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
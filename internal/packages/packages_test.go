@@ -0,0 +1,134 @@
+package packages
+
+import (
+	"testing"
+)
+
+const testPackageJSON = `{
+  "name": "web",
+  "version": "1.0.0",
+  "scripts": {"build": "vite build"},
+  "dependencies": {
+    "react": "^18.3.1",
+    "@tanstack/react-query": "5.0.0",
+    "local-lib": "file:../lib",
+    "fork": "github:owner/fork#main"
+  },
+  "devDependencies": {
+    "vite": "^5.0.0",
+    "react": "^18.3.1",
+    "lodash-es": "npm:lodash@4.17.21"
+  }
+}
+`
+
+const testRequirements = `# core
+requests[socks]>=2.31 ; python_version > "3.8"
+-r base.txt
+--hash=sha256:abc
+numpy==1.26.4  # pinned
+mylib @ git+https://github.com/owner/mylib@v1.0#egg=mylib
+https://example.com/archive.tar.gz
+`
+
+const testPyProject = `[project]
+name = "service"
+dependencies = [
+  "httpx>=0.27",
+  'pydantic[email]~=2.7',
+]
+
+[project.optional-dependencies]
+cli = ["typer"]
+
+[dependency-groups]
+dev = ["pytest>=8", {include-group = "lint"}]
+
+[tool.poetry.dependencies]
+python = "^3.12"
+rich = "^13"
+local = { path = "../local" }
+`
+
+const testCargo = `[package]
+name = "tool"
+
+[dependencies]
+serde = { version = "1", features = ["derive"] }
+tokio = "1"
+helper = { path = "../helper" }
+shared = { workspace = true }
+rustls-pemfile = { package = "rustls-pki-types", version = "1" }
+forked = { git = "https://github.com/owner/forked" }
+
+[target.'cfg(unix)'.dependencies]
+nix = "0.29"
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want []Package
+	}{
+		{
+			path: "package.json",
+			data: testPackageJSON,
+			want: []Package{
+				{Ecosystem: NPM, Name: "react", Line: 6},
+				{Ecosystem: NPM, Name: "@tanstack/react-query", Line: 7},
+				{Ecosystem: NPM, Name: "fork", URL: "https://github.com/owner/fork", Line: 9},
+				{Ecosystem: NPM, Name: "vite", Line: 12},
+				{Ecosystem: NPM, Name: "lodash", Line: 14},
+			},
+		},
+		{
+			path: "requirements-dev.txt",
+			data: testRequirements,
+			want: []Package{
+				{Ecosystem: PyPI, Name: "requests", Line: 2},
+				{Ecosystem: PyPI, Name: "numpy", Line: 5},
+				{Ecosystem: PyPI, Name: "mylib", URL: "git+https://github.com/owner/mylib", Line: 6},
+			},
+		},
+		{
+			path: "pyproject.toml",
+			data: testPyProject,
+			want: []Package{
+				{Ecosystem: PyPI, Name: "httpx", Line: 4},
+				{Ecosystem: PyPI, Name: "pydantic", Line: 5},
+				{Ecosystem: PyPI, Name: "typer", Line: 9},
+				{Ecosystem: PyPI, Name: "pytest", Line: 12},
+				{Ecosystem: PyPI, Name: "rich", Line: 16},
+			},
+		},
+		{
+			path: "Cargo.toml",
+			data: testCargo,
+			want: []Package{
+				{Ecosystem: Crates, Name: "forked", URL: "https://github.com/owner/forked", Line: 10},
+				{Ecosystem: Crates, Name: "rustls-pki-types", Line: 9},
+				{Ecosystem: Crates, Name: "serde", Line: 5},
+				{Ecosystem: Crates, Name: "tokio", Line: 6},
+				{Ecosystem: Crates, Name: "nix", Line: 13},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := Parse(tt.path, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Parse()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
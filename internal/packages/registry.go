@@ -0,0 +1,215 @@
+package packages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+// Default registry base URLs.
+const (
+	DefaultNPMURL    = "https://registry.npmjs.org"
+	DefaultPyPIURL   = "https://pypi.org"
+	DefaultCratesURL = "https://crates.io"
+)
+
+// maxMetadataSize limits how much package metadata is read.
+const maxMetadataSize = 1 << 20
+
+// ErrNotFound is returned when a registry does not know a package.
+var ErrNotFound = errors.New("package not found in registry")
+
+// Resolver maps packages to GitHub repositories using registry metadata.
+type Resolver struct {
+	// NPMURL, PyPIURL and CratesURL are the registry base URLs. The public
+	// registries are used when they are empty.
+	NPMURL    string
+	PyPIURL   string
+	CratesURL string
+	// Client fetches package metadata. http.DefaultClient is used when nil.
+	Client *http.Client
+
+	cache map[string]string
+}
+
+// Resolve returns the owner/repo GitHub repository of pkg. Source URLs given
+// in the manifest are mapped directly, other packages are looked up in the
+// repository, homepage and project URL fields of their registry metadata.
+func (r *Resolver) Resolve(pkg Package) (string, error) {
+	if pkg.URL != "" {
+		if repo, ok := github.RepoFromURL(pkg.URL); ok {
+			return repo, nil
+		}
+		return "", fmt.Errorf("source %s is not hosted on GitHub", pkg.URL)
+	}
+
+	if r.cache == nil {
+		r.cache = make(map[string]string)
+	}
+	key := string(pkg.Ecosystem) + ":" + pkg.Name
+	repo, ok := r.cache[key]
+	if !ok {
+		candidates, err := r.lookup(pkg)
+		if err != nil {
+			return "", err
+		}
+		for _, candidate := range candidates {
+			if repo, ok = github.RepoFromURL(candidate); ok {
+				break
+			}
+		}
+		r.cache[key] = repo
+		if repo != "" {
+			log.Debugf("Resolved %s package %s to %s", pkg.Ecosystem, pkg.Name, repo)
+		}
+	}
+	if repo == "" {
+		return "", fmt.Errorf("%s package %s has no GitHub repository in its metadata", pkg.Ecosystem, pkg.Name)
+	}
+	return repo, nil
+}
+
+// lookup returns the source URLs listed in the registry metadata of pkg,
+// most specific first.
+func (r *Resolver) lookup(pkg Package) ([]string, error) {
+	switch pkg.Ecosystem {
+	case NPM:
+		var metadata struct {
+			Repository json.RawMessage `json:"repository"`
+			Homepage   string          `json:"homepage"`
+			Bugs       json.RawMessage `json:"bugs"`
+		}
+		// the latest version document carries the repository without the
+		// full packument of every version; scoped names keep their slash
+		if err := r.get(baseURL(r.NPMURL, DefaultNPMURL)+"/"+pkg.Name+"/latest", &metadata); err != nil {
+			return nil, fmt.Errorf("npm package %s: %w", pkg.Name, err)
+		}
+		return []string{npmURL(metadata.Repository), metadata.Homepage, npmURL(metadata.Bugs)}, nil
+
+	case PyPI:
+		var metadata struct {
+			Info struct {
+				ProjectURLs map[string]string `json:"project_urls"`
+				HomePage    string            `json:"home_page"`
+				DownloadURL string            `json:"download_url"`
+			} `json:"info"`
+		}
+		if err := r.get(baseURL(r.PyPIURL, DefaultPyPIURL)+"/pypi/"+url.PathEscape(pkg.Name)+"/json", &metadata); err != nil {
+			return nil, fmt.Errorf("PyPI package %s: %w", pkg.Name, err)
+		}
+		candidates := projectURLs(metadata.Info.ProjectURLs)
+		return append(candidates, metadata.Info.HomePage, metadata.Info.DownloadURL), nil
+
+	case Crates:
+		var metadata struct {
+			Crate struct {
+				Repository string `json:"repository"`
+				Homepage   string `json:"homepage"`
+			} `json:"crate"`
+		}
+		if err := r.get(baseURL(r.CratesURL, DefaultCratesURL)+"/api/v1/crates/"+url.PathEscape(pkg.Name), &metadata); err != nil {
+			return nil, fmt.Errorf("crate %s: %w", pkg.Name, err)
+		}
+		return []string{metadata.Crate.Repository, metadata.Crate.Homepage}, nil
+	}
+	return nil, fmt.Errorf("unsupported ecosystem %q", pkg.Ecosystem)
+}
+
+// get fetches a JSON document into v.
+func (r *Resolver) get(rawURL string, v any) error {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	// crates.io rejects requests without a user agent
+	req.Header.Set("User-Agent", "ghreleases2rss (+https://github.com/toozej/ghreleases2rss)")
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req) // #nosec G704 -- registry URLs come from configuration
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("failed to fetch package metadata, status code: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxMetadataSize)).Decode(v)
+}
+
+func baseURL(configured, fallback string) string {
+	if configured == "" {
+		return fallback
+	}
+	return strings.TrimSuffix(configured, "/")
+}
+
+// npmURL returns the URL of an npm repository or bugs field, which is either
+// a string or an object with a url key. GitHub shorthands are expanded.
+func npmURL(raw json.RawMessage) string {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		var object struct {
+			URL string `json:"url"`
+		}
+		if json.Unmarshal(raw, &object) != nil {
+			return ""
+		}
+		value = object.URL
+	}
+	if shorthand, ok := strings.CutPrefix(value, "github:"); ok {
+		return "https://github.com/" + shorthand
+	}
+	if strings.Count(value, "/") == 1 && !strings.Contains(value, ":") {
+		return "https://github.com/" + value
+	}
+	return value
+}
+
+// preferredProjectURLs are PyPI project URL labels that usually point at the
+// source repository, in order of preference.
+var preferredProjectURLs = []string{"source", "source code", "repository", "code", "github", "homepage", "home"}
+
+// projectURLs returns PyPI project URLs with source repository labels first.
+func projectURLs(urls map[string]string) []string {
+	labels := make([]string, 0, len(urls))
+	for label := range urls {
+		labels = append(labels, label)
+	}
+	rank := func(label string) int {
+		for i, preferred := range preferredProjectURLs {
+			if strings.EqualFold(label, preferred) {
+				return i
+			}
+		}
+		return len(preferredProjectURLs)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if rank(labels[i]) != rank(labels[j]) {
+			return rank(labels[i]) < rank(labels[j])
+		}
+		return labels[i] < labels[j]
+	})
+
+	candidates := make([]string, 0, len(labels))
+	for _, label := range labels {
+		candidates = append(candidates, urls[label])
+	}
+	return candidates
+}
@@ -0,0 +1,87 @@
+package packages
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	lookups := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		if r.Header.Get("User-Agent") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.EscapedPath() {
+		case "/npm/react/latest":
+			fmt.Fprint(w, `{"repository":{"type":"git","url":"git+https://github.com/facebook/react.git","directory":"packages/react"}}`)
+		case "/npm/@tanstack/react-query/latest":
+			fmt.Fprint(w, `{"repository":"github:TanStack/query"}`)
+		case "/npm/no-repo/latest":
+			fmt.Fprint(w, `{"homepage":"https://example.com"}`)
+		case "/pypi/pypi/requests/json":
+			fmt.Fprint(w, `{"info":{"home_page":"https://requests.readthedocs.io","project_urls":{"Documentation":"https://requests.readthedocs.io","Source":"https://github.com/psf/requests"}}}`)
+		case "/crates/api/v1/crates/serde":
+			fmt.Fprint(w, `{"crate":{"homepage":"https://serde.rs","repository":"https://github.com/serde-rs/serde"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	resolver := &Resolver{
+		NPMURL:    mockServer.URL + "/npm",
+		PyPIURL:   mockServer.URL + "/pypi/",
+		CratesURL: mockServer.URL + "/crates",
+	}
+
+	tests := []struct {
+		pkg     Package
+		want    string
+		wantErr error
+	}{
+		{pkg: Package{Ecosystem: NPM, Name: "react"}, want: "facebook/react"},
+		{pkg: Package{Ecosystem: NPM, Name: "@tanstack/react-query"}, want: "TanStack/query"},
+		{pkg: Package{Ecosystem: NPM, Name: "fork", URL: "https://github.com/owner/fork"}, want: "owner/fork"},
+		{pkg: Package{Ecosystem: PyPI, Name: "requests"}, want: "psf/requests"},
+		{pkg: Package{Ecosystem: Crates, Name: "serde"}, want: "serde-rs/serde"},
+		{pkg: Package{Ecosystem: NPM, Name: "no-repo"}},
+		{pkg: Package{Ecosystem: Crates, Name: "missing"}, wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.pkg.Ecosystem)+"/"+tt.pkg.Name, func(t *testing.T) {
+			got, err := resolver.Resolve(tt.pkg)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("Resolve() = %v, want error", got)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Resolve() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	before := lookups
+	if _, err := resolver.Resolve(Package{Ecosystem: NPM, Name: "react"}); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if _, err := resolver.Resolve(Package{Ecosystem: NPM, Name: "no-repo"}); err == nil {
+		t.Error("Resolve() should remember packages without a GitHub repository")
+	}
+	if lookups != before {
+		t.Errorf("resolver made %d repeated lookups, want none", lookups-before)
+	}
+}
@@ -0,0 +1,326 @@
+// Package sbom reads the components of CycloneDX and SPDX JSON SBOMs and
+// maps them to GitHub repositories through VCS references and purls.
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+// Component is a package listed in an SBOM.
+type Component struct {
+	Name    string
+	Version string
+	// Type is the CycloneDX component type or the lower-cased SPDX primary
+	// package purpose, such as "library", "application" or "container".
+	Type string
+	// Scope is the CycloneDX scope ("required", "optional" or "excluded").
+	// It is empty for SPDX packages, which have no scope.
+	Scope string
+	PURL  string
+	// URLs are VCS and website references, most specific first.
+	URLs []string
+	// Line is where the component's purl or name appears in the SBOM.
+	Line int
+}
+
+// Filter selects components by type and scope. Empty lists match everything,
+// and components without a scope match any scope filter.
+type Filter struct {
+	Types  []string
+	Scopes []string
+}
+
+// Match reports whether c passes the filter.
+func (f Filter) Match(c Component) bool {
+	if len(f.Types) > 0 && !containsFold(f.Types, c.Type) {
+		return false
+	}
+	if len(f.Scopes) > 0 && c.Scope != "" && !containsFold(f.Scopes, c.Scope) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrUnknownFormat is returned by Parse for JSON documents that are neither
+// CycloneDX nor SPDX.
+var ErrUnknownFormat = errors.New("not a CycloneDX or SPDX JSON document")
+
+// Parse returns the components of a CycloneDX or SPDX JSON SBOM, detected
+// from its bomFormat or spdxVersion field.
+func Parse(data []byte) ([]Component, error) {
+	var header struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	var err error
+	switch {
+	case header.BOMFormat == "CycloneDX":
+		components, err = parseCycloneDX(data)
+	case strings.HasPrefix(header.SPDXVersion, "SPDX-"):
+		components, err = parseSPDX(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range components {
+		components[i].Line = lineOf(data, components[i].PURL, components[i].Name)
+	}
+	return components, nil
+}
+
+type cycloneDXComponent struct {
+	Type               string `json:"type"`
+	Name               string `json:"name"`
+	Group              string `json:"group"`
+	Version            string `json:"version"`
+	Scope              string `json:"scope"`
+	PURL               string `json:"purl"`
+	ExternalReferences []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"externalReferences"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// parseCycloneDX flattens the component tree of a CycloneDX BOM. The
+// metadata component describing the BOM's own subject is left out.
+func parseCycloneDX(data []byte) ([]Component, error) {
+	var bom struct {
+		Components []cycloneDXComponent `json:"components"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	var walk func([]cycloneDXComponent)
+	walk = func(list []cycloneDXComponent) {
+		for _, c := range list {
+			component := Component{
+				Name:    c.Name,
+				Version: c.Version,
+				Type:    c.Type,
+				Scope:   c.Scope,
+				PURL:    c.PURL,
+			}
+			if c.Group != "" {
+				component.Name = c.Group + "/" + c.Name
+			}
+			// the specification defaults a missing scope to required
+			if component.Scope == "" {
+				component.Scope = "required"
+			}
+			for _, refType := range []string{"vcs", "website"} {
+				for _, ref := range c.ExternalReferences {
+					if ref.Type == refType {
+						component.URLs = append(component.URLs, ref.URL)
+					}
+				}
+			}
+			components = append(components, component)
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return components, nil
+}
+
+// parseSPDX returns the packages of an SPDX document other than the ones the
+// document describes, which are the SBOM's own subject.
+func parseSPDX(data []byte) ([]Component, error) {
+	var doc struct {
+		DocumentDescribes []string `json:"documentDescribes"`
+		Packages          []struct {
+			SPDXID                string `json:"SPDXID"`
+			Name                  string `json:"name"`
+			VersionInfo           string `json:"versionInfo"`
+			PrimaryPackagePurpose string `json:"primaryPackagePurpose"`
+			DownloadLocation      string `json:"downloadLocation"`
+			Homepage              string `json:"homepage"`
+			ExternalRefs          []struct {
+				ReferenceCategory string `json:"referenceCategory"`
+				ReferenceType     string `json:"referenceType"`
+				ReferenceLocator  string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Relationships []struct {
+			SPDXElementID      string `json:"spdxElementId"`
+			RelationshipType   string `json:"relationshipType"`
+			RelatedSPDXElement string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	described := make(map[string]bool)
+	for _, id := range doc.DocumentDescribes {
+		described[id] = true
+	}
+	for _, rel := range doc.Relationships {
+		if rel.SPDXElementID == "SPDXRef-DOCUMENT" && rel.RelationshipType == "DESCRIBES" {
+			described[rel.RelatedSPDXElement] = true
+		}
+	}
+
+	var components []Component
+	for _, p := range doc.Packages {
+		if described[p.SPDXID] {
+			continue
+		}
+		component := Component{
+			Name:    p.Name,
+			Version: p.VersionInfo,
+			Type:    strings.ReplaceAll(strings.ToLower(p.PrimaryPackagePurpose), "_", "-"),
+		}
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" && component.PURL == "" {
+				component.PURL = ref.ReferenceLocator
+			}
+		}
+		for _, location := range []string{p.DownloadLocation, p.Homepage} {
+			if location != "" && location != "NOASSERTION" && location != "NONE" {
+				component.URLs = append(component.URLs, location)
+			}
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+// Repo returns the GitHub repository a component can be mapped to without a
+// registry lookup: a GitHub VCS or website reference, a pkg:github purl, a
+// pkg:golang purl for a github.com module, or a purl vcs_url qualifier.
+func (c Component) Repo() (string, bool) {
+	if purl, err := ParsePURL(c.PURL); err == nil {
+		switch purl.Type {
+		case "github":
+			if purl.Namespace != "" && !strings.Contains(purl.Namespace, "/") {
+				return purl.Namespace + "/" + purl.Name, true
+			}
+		case "golang":
+			if repo, ok := github.RepoFromURL("https://" + purl.Path()); ok {
+				return repo, true
+			}
+		}
+		for _, qualifier := range []string{"vcs_url", "repository_url"} {
+			if repo, ok := github.RepoFromURL(purl.Qualifiers[qualifier]); ok {
+				return repo, true
+			}
+		}
+	}
+	for _, u := range c.URLs {
+		if repo, ok := github.RepoFromURL(u); ok {
+			return repo, true
+		}
+	}
+	return "", false
+}
+
+// PURL is a parsed package URL, pkg:type/namespace/name@version?qualifiers#subpath.
+type PURL struct {
+	Type       string
+	Namespace  string
+	Name       string
+	Version    string
+	Qualifiers map[string]string
+	Subpath    string
+}
+
+// Path returns the namespace and name joined with a slash.
+func (p PURL) Path() string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+	return p.Namespace + "/" + p.Name
+}
+
+// ParsePURL parses a package URL, decoding its percent-encoded parts.
+func ParsePURL(s string) (PURL, error) {
+	rest, ok := strings.CutPrefix(s, "pkg:")
+	if !ok {
+		return PURL{}, fmt.Errorf("invalid purl %q: missing pkg: scheme", s)
+	}
+
+	var purl PURL
+	rest, subpath, _ := strings.Cut(rest, "#")
+	purl.Subpath = strings.Trim(subpath, "/")
+	rest, query, _ := strings.Cut(rest, "?")
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return PURL{}, fmt.Errorf("invalid purl %q: %w", s, err)
+		}
+		purl.Qualifiers = make(map[string]string, len(values))
+		for key, value := range values {
+			purl.Qualifiers[strings.ToLower(key)] = value[0]
+		}
+	}
+
+	typ, path, found := strings.Cut(strings.TrimLeft(rest, "/"), "/")
+	if !found || typ == "" {
+		return PURL{}, fmt.Errorf("invalid purl %q: missing type or name", s)
+	}
+	purl.Type = strings.ToLower(typ)
+
+	if at := strings.LastIndex(path, "@"); at >= 0 {
+		version, err := url.PathUnescape(path[at+1:])
+		if err != nil {
+			return PURL{}, fmt.Errorf("invalid purl %q: %w", s, err)
+		}
+		purl.Version = version
+		path = path[:at]
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return PURL{}, fmt.Errorf("invalid purl %q: %w", s, err)
+		}
+		segments[i] = decoded
+	}
+	purl.Name = segments[len(segments)-1]
+	purl.Namespace = strings.Join(segments[:len(segments)-1], "/")
+	if purl.Name == "" {
+		return PURL{}, fmt.Errorf("invalid purl %q: missing name", s)
+	}
+	return purl, nil
+}
+
+// lineOf returns the line of the first JSON string equal to one of values.
+func lineOf(data []byte, values ...string) int {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if i := bytes.Index(data, []byte(`"`+value+`"`)); i >= 0 {
+			return 1 + bytes.Count(data[:i], []byte("\n"))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package sbom
+
+import (
+	"errors"
+	"testing"
+)
+
+const testCycloneDX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"type": "container", "name": "ghcr.io/owner/app"}},
+  "components": [
+    {
+      "type": "library",
+      "name": "cobra",
+      "version": "v1.10.2",
+      "purl": "pkg:golang/github.com/spf13/cobra@v1.10.2"
+    },
+    {
+      "type": "library",
+      "group": "@tanstack",
+      "name": "react-query",
+      "scope": "optional",
+      "purl": "pkg:npm/%40tanstack/react-query@5.0.0",
+      "externalReferences": [
+        {"type": "website", "url": "https://tanstack.com/query"},
+        {"type": "vcs", "url": "git+https://github.com/TanStack/query.git"}
+      ]
+    },
+    {
+      "type": "application",
+      "name": "tool",
+      "scope": "excluded",
+      "purl": "pkg:github/owner/tool@v2.0.0",
+      "components": [
+        {"type": "library", "name": "zlib", "purl": "pkg:generic/zlib@1.3"}
+      ]
+    }
+  ]
+}`
+
+const testSPDX = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "documentDescribes": ["SPDXRef-root"],
+  "packages": [
+    {"SPDXID": "SPDXRef-root", "name": "app", "downloadLocation": "NOASSERTION"},
+    {
+      "SPDXID": "SPDXRef-requests",
+      "name": "requests",
+      "versionInfo": "2.31.0",
+      "primaryPackagePurpose": "LIBRARY",
+      "downloadLocation": "NOASSERTION",
+      "externalRefs": [
+        {"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:pypi/requests@2.31.0"}
+      ]
+    },
+    {
+      "SPDXID": "SPDXRef-curl",
+      "name": "curl",
+      "primaryPackagePurpose": "OPERATING_SYSTEM",
+      "downloadLocation": "git+https://github.com/curl/curl.git"
+    }
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantNames []string
+		wantRepos []string
+		wantLines []int
+	}{
+		{
+			name:      "CycloneDX",
+			data:      testCycloneDX,
+			wantNames: []string{"cobra", "@tanstack/react-query", "tool", "zlib"},
+			wantRepos: []string{"spf13/cobra", "TanStack/query", "owner/tool", ""},
+			wantLines: []int{10, 17, 27, 29},
+		},
+		{
+			name:      "SPDX",
+			data:      testSPDX,
+			wantNames: []string{"requests", "curl"},
+			wantRepos: []string{"", "curl/curl"},
+			wantLines: []int{14, 19},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(got) != len(tt.wantNames) {
+				t.Fatalf("Parse() = %+v, want %d components", got, len(tt.wantNames))
+			}
+			for i, component := range got {
+				repo, _ := component.Repo()
+				if component.Name != tt.wantNames[i] || repo != tt.wantRepos[i] || component.Line != tt.wantLines[i] {
+					t.Errorf("Parse()[%d] = %s (repo %q, line %d), want %s (repo %q, line %d)",
+						i, component.Name, repo, component.Line, tt.wantNames[i], tt.wantRepos[i], tt.wantLines[i])
+				}
+			}
+		})
+	}
+
+	if _, err := Parse([]byte(`{"name": "not an sbom"}`)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	components, err := Parse([]byte(testCycloneDX))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	spdx, err := Parse([]byte(testSPDX))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	filter := Filter{Types: []string{"library", "operating-system"}, Scopes: []string{"required"}}
+	var got []string
+	for _, component := range append(components, spdx...) {
+		if filter.Match(component) {
+			got = append(got, component.Name)
+		}
+	}
+	want := []string{"cobra", "zlib", "requests", "curl"}
+	if len(got) != len(want) {
+		t.Fatalf("matched %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("matched[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParsePURL(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    PURL
+		wantErr bool
+	}{
+		{input: "pkg:github/owner/repo@v1.0.0", want: PURL{Type: "github", Namespace: "owner", Name: "repo", Version: "v1.0.0"}},
+		{input: "pkg:golang/github.com/aws/aws-sdk-go-v2/service/s3@v1.2.3", want: PURL{Type: "golang", Namespace: "github.com/aws/aws-sdk-go-v2/service", Name: "s3", Version: "v1.2.3"}},
+		{input: "pkg:npm/%40scope/name@1.0.0", want: PURL{Type: "npm", Namespace: "@scope", Name: "name", Version: "1.0.0"}},
+		{input: "pkg:pypi/requests", want: PURL{Type: "pypi", Name: "requests"}},
+		{input: "pkg:deb/debian/curl@8.5.0?arch=amd64#src", want: PURL{Type: "deb", Namespace: "debian", Name: "curl", Version: "8.5.0", Qualifiers: map[string]string{"arch": "amd64"}, Subpath: "src"}},
+		{input: "https://github.com/owner/repo", wantErr: true},
+		{input: "pkg:npm", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePURL(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Type != tt.want.Type || got.Namespace != tt.want.Namespace || got.Name != tt.want.Name ||
+				got.Version != tt.want.Version || got.Subpath != tt.want.Subpath || len(got.Qualifiers) != len(tt.want.Qualifiers) {
+				t.Errorf("ParsePURL() = %+v, want %+v", got, tt.want)
+			}
+			for key, value := range tt.want.Qualifiers {
+				if got.Qualifiers[key] != value {
+					t.Errorf("ParsePURL() qualifier %s = %v, want %v", key, got.Qualifiers[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// pattern is a compiled gitignore-style glob.
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// compilePattern compiles a gitignore-style glob. Patterns containing a slash
+// other than a trailing one are anchored to base, others match at any depth.
+// It returns nil for blank lines and comments.
+func compilePattern(line, base string) *pattern {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	p := &pattern{}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return nil
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if base != "" {
+		expr.WriteString(regexp.QuoteMeta(base) + "/")
+	}
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	expr.WriteString(globExpr(line))
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil
+	}
+	p.re = re
+	return p
+}
+
+// globExpr translates a glob with *, ?, [...] and ** into a regular expression.
+func globExpr(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				expr.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				expr.WriteString(".*")
+				i++
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
+
+// match reports whether the slash-separated relative path matches p.
+func (p *pattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	return p.re.MatchString(rel)
+}
+
+// ignoreRules holds the patterns of every .gitignore file seen so far.
+// Later patterns take precedence, as in git.
+type ignoreRules struct {
+	patterns []*pattern
+}
+
+// add appends the patterns of a .gitignore file found in the directory base,
+// given relative to the scan root with forward slashes.
+func (r *ignoreRules) add(data []byte, base string) {
+	if base == "." {
+		base = ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if p := compilePattern(scanner.Text(), base); p != nil {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+}
+
+// ignored reports whether the relative path is ignored.
+func (r *ignoreRules) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, p := range r.patterns {
+		if p.match(rel, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// globs is a list of include or exclude patterns given on the command line.
+type globs []*pattern
+
+func compileGlobs(list []string) globs {
+	var compiled globs
+	for _, glob := range list {
+		if p := compilePattern(glob, ""); p != nil {
+			compiled = append(compiled, p)
+		}
+	}
+	return compiled
+}
+
+// match reports whether any glob matches the relative path.
+func (g globs) match(rel string, isDir bool) bool {
+	for _, p := range g {
+		if p.match(rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
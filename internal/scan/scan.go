@@ -0,0 +1,193 @@
+// Package scan walks a project tree and detects every manifest that one of
+// the ghreleases2rss sources can read, by file name and content.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/internal/flake"
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/images"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/packages"
+	"github.com/toozej/ghreleases2rss/internal/terraform"
+	"github.com/toozej/ghreleases2rss/internal/tools"
+	"github.com/toozej/ghreleases2rss/internal/workflows"
+)
+
+// Kind is the source a detected file is read by.
+type Kind string
+
+// Detected file kinds, one per source.
+const (
+	KindInput     Kind = "input"
+	KindGoMod     Kind = "gomod"
+	KindWorkflows Kind = "workflows"
+	KindImages    Kind = "images"
+	KindPackages  Kind = "packages"
+	KindSBOM      Kind = "sbom"
+	KindTerraform Kind = "terraform"
+	KindFlake     Kind = "flake"
+	KindTools     Kind = "tools"
+)
+
+// maxSniffSize limits which files are read to detect their kind by content.
+const maxSniffSize = 4 << 20
+
+// File is a detected manifest.
+type File struct {
+	Path string
+	Kind Kind
+}
+
+// Options configure Walk.
+type Options struct {
+	// Include limits detection to files matching one of these globs, relative
+	// to the scan root. Globs without a slash match at any depth.
+	Include []string
+	// Exclude skips files and directories matching one of these globs.
+	Exclude []string
+	// NoGitignore disables skipping paths ignored by .gitignore files.
+	NoGitignore bool
+	// ReadFile reads a file whose kind depends on its content. Files that
+	// need it are skipped when it is nil.
+	ReadFile func(path string) ([]byte, error)
+}
+
+// Walk returns the supported manifests below root in walk order. The .git
+// directory and paths ignored by .gitignore files are skipped.
+func Walk(root string, opts Options) ([]File, error) {
+	include := compileGlobs(opts.Include)
+	exclude := compileGlobs(opts.Exclude)
+	rules := &ignoreRules{}
+
+	var files []File
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel == "." {
+				rel = ""
+			} else if d.Name() == ".git" || exclude.match(rel, true) || (!opts.NoGitignore && rules.ignored(rel, true)) {
+				log.Debugf("Skipping directory %s", path)
+				return filepath.SkipDir
+			}
+			if !opts.NoGitignore && opts.ReadFile != nil {
+				if data, err := opts.ReadFile(filepath.Join(path, ".gitignore")); err == nil {
+					rules.add(data, rel)
+				}
+			}
+			return nil
+		}
+		if rel == "." {
+			// root is a single file
+			rel = d.Name()
+		}
+
+		if exclude.match(rel, false) || (!opts.NoGitignore && rules.ignored(rel, false)) {
+			return nil
+		}
+		if len(include) > 0 && !include.match(rel, false) {
+			return nil
+		}
+		if kind := Detect(path, opts.ReadFile); kind != "" {
+			files = append(files, File{Path: path, Kind: kind})
+		}
+		return nil
+	})
+	return files, err
+}
+
+var (
+	yamlImageKey = regexp.MustCompile(`(?m)^\s*(?:-\s+)?image:`)
+	repoLine     = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*/[A-Za-z0-9._-]+(?:@\S+)?$`)
+)
+
+// Detect returns the kind of the file at path, or "" if no source reads it.
+// Most manifests are recognised by name; YAML files are image manifests if
+// they have image keys, JSON files are SBOMs if they declare a CycloneDX or
+// SPDX format, and .txt and .list files are input lists if every line names
+// a GitHub repository. read loads the file for content checks.
+func Detect(path string, read func(string) ([]byte, error)) Kind {
+	name := filepath.Base(path)
+	switch {
+	case name == "go.mod":
+		return KindGoMod
+	case workflows.IsWorkflowFile(path):
+		return KindWorkflows
+	case images.IsDockerfile(name):
+		return KindImages
+	case packages.IsManifest(name):
+		return KindPackages
+	case name == terraform.LockFileName:
+		return KindTerraform
+	case name == flake.LockFileName:
+		return KindFlake
+	case tools.IsManifest(name):
+		return KindTools
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if read == nil || (ext != ".json" && ext != ".txt" && ext != ".list" && !images.IsYAML(name)) {
+		return ""
+	}
+	data, err := read(path)
+	if err != nil || len(data) > maxSniffSize {
+		return ""
+	}
+
+	switch {
+	case images.IsYAML(name):
+		if yamlImageKey.Match(data) {
+			return KindImages
+		}
+	case ext == ".json":
+		if bytes.Contains(data, []byte(`"bomFormat"`)) || bytes.Contains(data, []byte(`"spdxVersion"`)) {
+			return KindSBOM
+		}
+	default:
+		if isInputList(data) {
+			return KindInput
+		}
+	}
+	return ""
+}
+
+// isInputList reports whether every line of data other than comments and
+// section headers names a GitHub repository as owner/repo, a github.com URL
+// or a GHCR image.
+func isInputList(data []byte) bool {
+	repos := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		if _, ok := input.SectionName(text); text == "" || strings.HasPrefix(text, "#") || ok {
+			continue
+		}
+		// Only the repo is checked, not the options that may follow it
+		text = strings.Fields(text)[0]
+		_, isURL := github.RepoFromURL(text)
+		if !isURL && !repoLine.MatchString(text) && !strings.HasPrefix(text, "ghcr.io/") {
+			return false
+		}
+		repos++
+	}
+	return repos > 0
+}
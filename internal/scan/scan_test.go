@@ -0,0 +1,136 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":                           "dist/\n*.generated.yaml\n!keep.generated.yaml\n",
+		"repos.txt":                            "# infra\nowner/repo\n[Tools]\nhttps://github.com/other/repo\n",
+		"notes.txt":                            "remember to update owner/repo\n",
+		"go.mod":                               "module example.com/app\n",
+		".github/workflows/ci.yml":             "on: push\n",
+		"deploy/compose.yaml":                  "services:\n  web:\n    image: nginx\n",
+		"deploy/values.yaml":                   "replicas: 2\n",
+		"deploy/app.generated.yaml":            "image: nginx\n",
+		"deploy/keep.generated.yaml":           "image: nginx\n",
+		"web/package.json":                     "{}",
+		"web/node/.gitignore":                  "/local.txt\n",
+		"web/node/local.txt":                   "owner/ignored\n",
+		"sbom/app.cdx.json":                    `{"bomFormat": "CycloneDX"}`,
+		"sbom/other.json":                      `{"name": "config"}`,
+		"infra/.terraform.lock.hcl":            "",
+		"infra/vendor/.terraform.lock.hcl":     "",
+		"nix/flake.lock":                       "{}",
+		".tool-versions":                       "nodejs 20\n",
+		"dist/Dockerfile":                      "FROM scratch\n",
+		"services/api/Dockerfile":              "FROM golang:1.26\n",
+		"services/api/requirements-dev.txt":    "pytest\n",
+		"services/api/testdata/Dockerfile":     "FROM alpine\n",
+		".git/config":                          "[core]\n",
+		"services/api/.github/workflows/x.yml": "on: push\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want map[string]Kind
+	}{
+		{
+			name: "Defaults",
+			opts: Options{Exclude: []string{"testdata", "infra/vendor/**"}},
+			want: map[string]Kind{
+				".github/workflows/ci.yml":             KindWorkflows,
+				".tool-versions":                       KindTools,
+				"deploy/compose.yaml":                  KindImages,
+				"deploy/keep.generated.yaml":           KindImages,
+				"go.mod":                               KindGoMod,
+				"infra/.terraform.lock.hcl":            KindTerraform,
+				"nix/flake.lock":                       KindFlake,
+				"repos.txt":                            KindInput,
+				"sbom/app.cdx.json":                    KindSBOM,
+				"services/api/.github/workflows/x.yml": KindWorkflows,
+				"services/api/Dockerfile":              KindImages,
+				"services/api/requirements-dev.txt":    KindPackages,
+				"web/package.json":                     KindPackages,
+			},
+		},
+		{
+			name: "Include and no gitignore",
+			opts: Options{Include: []string{"**/Dockerfile", "web/**/*.txt"}, NoGitignore: true},
+			want: map[string]Kind{
+				"dist/Dockerfile":                  KindImages,
+				"services/api/Dockerfile":          KindImages,
+				"services/api/testdata/Dockerfile": KindImages,
+				"web/node/local.txt":               KindInput,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ReadFile = os.ReadFile
+			got, err := Walk(dir, tt.opts)
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("Walk() found %d files, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for _, file := range got {
+				rel, _ := filepath.Rel(dir, file.Path)
+				if want := tt.want[filepath.ToSlash(rel)]; file.Kind != want {
+					t.Errorf("Walk() detected %s as %q, want %q", rel, file.Kind, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		base    string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{pattern: "*.log", path: "a/b/debug.log", want: true},
+		{pattern: "/build", path: "build", isDir: true, want: true},
+		{pattern: "/build", path: "src/build", isDir: true, want: false},
+		{pattern: "out/", path: "out", want: false},
+		{pattern: "out/", path: "pkg/out", isDir: true, want: true},
+		{pattern: "docs/*.md", path: "docs/a.md", want: true},
+		{pattern: "docs/*.md", path: "docs/sub/a.md", want: false},
+		{pattern: "**/fixtures", path: "a/b/fixtures", isDir: true, want: true},
+		{pattern: "a/**/z", path: "a/b/c/z", want: true},
+		{pattern: "file[0-9].txt", path: "file7.txt", want: true},
+		{pattern: "local.txt", base: "web", path: "local.txt", want: false},
+		{pattern: "local.txt", base: "web", path: "web/x/local.txt", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			p := compilePattern(tt.pattern, tt.base)
+			if p == nil {
+				t.Fatalf("compilePattern(%q) = nil", tt.pattern)
+			}
+			if got := p.match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,872 @@
+// Package server fetches the release feeds of tracked repos in the background
+// and serves them merged into one Atom feed per category, and proxies the
+// release and tag feeds of repos through a cache and optional filter,
+// along with feeds of newly seen container image tags.
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/internal/advisory"
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/classify"
+	"github.com/toozej/ghreleases2rss/internal/enrich"
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/filter"
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/imagetags"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/oci"
+)
+
+// DefaultBaseURL is where release feeds are fetched from.
+const DefaultBaseURL = "https://github.com"
+
+// DefaultInterval is the time between refreshes for a zero Server.Interval.
+const DefaultInterval = time.Hour
+
+// ErrNotReady is returned by Feed before the first refresh has finished.
+var ErrNotReady = errors.New("feeds have not been fetched yet")
+
+// Category is a named group of repos served as one merged feed.
+type Category struct {
+	// Slug names the category in feed URLs, e.g. /feeds/dev-tools.atom.
+	Slug  string
+	Name  string
+	Repos []Repo
+}
+
+// Repo is a repo in a category with the filter its releases are passed
+// through, if any.
+type Repo struct {
+	Name   string
+	Filter *filter.Filter
+}
+
+// Categories groups input lines into categories by their [Section] header.
+// Lines outside a section go into defaultName. Filter options after the repo
+// on a line apply to that repo's entries. Repos are kept in input order
+// without duplicates, and categories are sorted by slug. Lines that do not
+// name a GitHub repo or have invalid options are logged and left out.
+func Categories(lines []input.Line, defaultName string) []Category {
+	bySlug := make(map[string]*Category)
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		text, f, err := filter.Cut(line.Text)
+		if err != nil {
+			log.Warnf("Leaving out '%s' (%s:%d): %v", line.Text, line.Source, line.Number, err)
+			continue
+		}
+		repo, err := github.ParseRepo(text)
+		if err != nil {
+			log.Warnf("Leaving out '%s' (%s:%d): %v", line.Text, line.Source, line.Number, err)
+			continue
+		}
+		name := line.Section
+		if Slug(name) == "" {
+			name = defaultName
+		}
+		slug := Slug(name)
+		category, ok := bySlug[slug]
+		if !ok {
+			category = &Category{Slug: slug, Name: name}
+			bySlug[slug] = category
+		}
+		if key := slug + " " + strings.ToLower(repo) + " " + f.String(); !seen[key] {
+			seen[key] = true
+			category.Repos = append(category.Repos, Repo{Name: repo, Filter: f})
+		}
+	}
+
+	categories := make([]Category, 0, len(bySlug))
+	for _, category := range bySlug {
+		categories = append(categories, *category)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Slug < categories[j].Slug })
+	return categories
+}
+
+// Slug lowercases name and replaces every run of characters other than
+// letters and digits with a single dash.
+func Slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// Server keeps the release feeds of every repo in its categories up to date
+// and serves them merged per category. Load is called on every refresh, so
+// changes to the input are picked up without a restart.
+type Server struct {
+	// Load returns the categories to serve.
+	Load func() ([]Category, error)
+	// BaseURL is where owner/repo/releases.atom feeds are fetched from.
+	// DefaultBaseURL is used when it is empty.
+	BaseURL string
+	// Cache fetches and stores feeds. A memory-only cache with its defaults
+	// is used when nil.
+	Cache *cache.Cache
+	// Enricher adds assets, authors, compare links and rendered notes from
+	// the GitHub API to release entries. Release feeds are served as
+	// published when nil.
+	Enricher *enrich.Enricher
+	// API reads the security advisories served as advisories feeds, which
+	// are not served when nil.
+	API *github.API
+	// ImageTags lists the tags of container images served as image tag
+	// feeds, which are not served when nil.
+	ImageTags *imagetags.Tracker
+	// ProxyAny proxies the feeds of any repo. Only the repos in the
+	// categories are proxied when it is unset, so anyone who can reach the
+	// server cannot fill the cache or spend the GitHub rate limit on others.
+	ProxyAny bool
+	// Classify marks release entries that are major version bumps or
+	// mention breaking changes, deprecations or security fixes with title
+	// markers such as [MAJOR] and Atom categories, after enriching them.
+	Classify bool
+	// Interval is the time between refreshes, DefaultInterval when zero.
+	Interval time.Duration
+	// Spread is the time window the upstream fetches of a refresh are spread
+	// across, so hundreds of feeds are not requested at once. It is capped at
+	// Interval; zero fetches every feed immediately.
+	Spread time.Duration
+	// Limit caps the number of entries per merged feed. Zero means no limit.
+	Limit int
+
+	cacheOnce  sync.Once
+	mu         sync.RWMutex
+	categories map[string]Category
+	releases   map[string][]feed.Entry
+	advisories map[string][]feed.Entry
+	tracked    map[string]bool
+	loaded     bool
+	refreshed  time.Time
+}
+
+// Run serves the copies of the feeds found in the cache right away, then
+// refreshes them from upstream immediately and every Interval until ctx is
+// cancelled. Failed refreshes are logged and retried on the next tick.
+func (s *Server) Run(ctx context.Context) {
+	if err := s.Warm(); err != nil {
+		log.Errorf("Error loading cached feeds: %v", err)
+	}
+	ticker := time.NewTicker(s.interval())
+	defer ticker.Stop()
+	for {
+		if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("Error refreshing feeds: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) interval() time.Duration {
+	if s.Interval <= 0 {
+		return DefaultInterval
+	}
+	return s.Interval
+}
+
+func (s *Server) cache() *cache.Cache {
+	s.cacheOnce.Do(func() {
+		if s.Cache == nil {
+			s.Cache = &cache.Cache{}
+		}
+	})
+	return s.Cache
+}
+
+// Warm loads the categories and serves the cached release feed of every repo
+// in them without contacting upstream, so feeds are available right after a
+// restart. The tracked repos are proxied even when no feed is cached.
+func (s *Server) Warm() error {
+	categories, err := s.Load()
+	if err != nil {
+		return err
+	}
+	repos := repoNames(categories)
+	s.publish(categories, repos)
+
+	cached := make(map[string][]feed.Entry, len(repos))
+	for _, repo := range repos {
+		resp, ok := s.cache().Cached(s.upstreamURL(repo, releasesFile))
+		if !ok {
+			continue
+		}
+		parsed, err := feed.Parse(resp.Body)
+		if err != nil {
+			log.Warnf("Ignoring cached releases of %s: %v", repo, err)
+			continue
+		}
+		cached[repo] = parsed.Entries
+	}
+	if len(cached) == 0 {
+		return nil
+	}
+	s.update(repos, cached, nil)
+	log.Infof("Loaded %d of %d release feeds from the cache", len(cached), len(repos))
+	return nil
+}
+
+// Refresh loads the categories and fetches the release feed of every repo in
+// them through the cache, along with its security advisories when API is
+// set, spreading the fetches across Spread. A repo whose feed or advisories
+// cannot be fetched keeps the entries of its last successful fetch.
+func (s *Server) Refresh(ctx context.Context) error {
+	categories, err := s.Load()
+	if err != nil {
+		return err
+	}
+	repos := repoNames(categories)
+	s.publish(categories, repos)
+
+	releases, advisories := s.fetchAll(ctx, repos)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	failed := s.update(repos, releases, advisories)
+
+	log.Infof("Refreshed %d release feeds in %d categories, %d failed", len(repos), len(categories), failed)
+	return nil
+}
+
+// repoNames returns the distinct repos of categories in order.
+func repoNames(categories []Category) []string {
+	var repos []string
+	seen := make(map[string]bool)
+	for _, category := range categories {
+		for _, repo := range category.Repos {
+			if !seen[repo.Name] {
+				seen[repo.Name] = true
+				repos = append(repos, repo.Name)
+			}
+		}
+	}
+	return repos
+}
+
+// publish replaces the served categories and the tracked repos, so the
+// proxy knows which repos it serves before their feeds are fetched.
+func (s *Server) publish(categories []Category, repos []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// GitHub names are case-insensitive, so proxy URLs may differ in case
+	s.tracked = make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		s.tracked[strings.ToLower(repo)] = true
+	}
+	s.categories = make(map[string]Category, len(categories))
+	for _, category := range categories {
+		s.categories[category.Slug] = category
+	}
+	s.loaded = true
+}
+
+// update replaces the releases of repos with fetched, keeping the previous
+// releases of repos missing from it, and likewise their advisories unless
+// advisories is nil. It returns the number of repos whose releases were
+// missing.
+func (s *Server) update(repos []string, fetched, advisories map[string][]feed.Entry) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	releases := make(map[string][]feed.Entry, len(repos))
+	missing := 0
+	for _, repo := range repos {
+		if entries, ok := fetched[repo]; ok {
+			releases[repo] = entries
+		} else {
+			releases[repo] = s.releases[repo]
+			missing++
+		}
+	}
+	s.releases = releases
+	if advisories != nil {
+		kept := make(map[string][]feed.Entry, len(repos))
+		for _, repo := range repos {
+			if entries, ok := advisories[repo]; ok {
+				kept[repo] = entries
+			} else {
+				kept[repo] = s.advisories[repo]
+			}
+		}
+		s.advisories = kept
+	}
+	s.refreshed = time.Now()
+	return missing
+}
+
+// fetchAll fetches the release feeds of repos, and their security
+// advisories when API is set, starting each repo at a random time within its
+// share of Spread. The caches limit how many requests run at once per host.
+// Repos whose feed or advisories could not be fetched are missing from the
+// respective result.
+func (s *Server) fetchAll(ctx context.Context, repos []string) (releases, advisories map[string][]feed.Entry) {
+	spread := min(s.Spread, s.interval())
+	var slot time.Duration
+	if len(repos) > 0 {
+		slot = spread / time.Duration(len(repos))
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	releases = make(map[string][]feed.Entry, len(repos))
+	advisories = make(map[string][]feed.Entry, len(repos))
+	for i, repo := range repos {
+		var delay time.Duration
+		if slot > 0 {
+			delay = time.Duration(i)*slot + rand.N(slot) // #nosec G404 -- jitter does not need a secure random source
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			if entries, ok := s.fetchReleases(ctx, repo); ok {
+				mu.Lock()
+				releases[repo] = entries
+				mu.Unlock()
+			}
+			if entries, ok := s.fetchAdvisories(ctx, repo); ok {
+				mu.Lock()
+				advisories[repo] = entries
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return releases, advisories
+}
+
+// fetchReleases fetches, enriches and classifies the release entries of
+// repo. It reports false when the feed cannot be fetched.
+func (s *Server) fetchReleases(ctx context.Context, repo string) ([]feed.Entry, bool) {
+	resp, err := s.cache().Get(ctx, s.upstreamURL(repo, releasesFile))
+	if err != nil {
+		log.Warnf("Error fetching releases of %s: %v", repo, err)
+		return nil, false
+	}
+	releases, err := feed.Parse(resp.Body)
+	if err != nil {
+		log.Warnf("Error parsing releases of %s: %v", repo, err)
+		return nil, false
+	}
+	log.Debugf("Fetched %d releases of %s", len(releases.Entries), repo)
+	return s.classify(s.enrich(ctx, repo, releases.Entries)), true
+}
+
+// fetchAdvisories fetches the security advisory entries of repo from API.
+// A repo without advisories has none. It reports false when API is not set
+// or the advisories cannot be fetched.
+func (s *Server) fetchAdvisories(ctx context.Context, repo string) ([]feed.Entry, bool) {
+	if s.API == nil {
+		return nil, false
+	}
+	advisories, err := s.API.Advisories(ctx, repo)
+	switch {
+	case errors.Is(err, cache.ErrNotFound):
+		return nil, true
+	case err != nil:
+		log.Warnf("Error fetching advisories of %s: %v", repo, err)
+		return nil, false
+	}
+	log.Debugf("Fetched %d advisories of %s", len(advisories), repo)
+	return advisory.Feed(repo, advisories).Entries, true
+}
+
+// enrich returns entries enriched by the Enricher, or entries unchanged
+// when there is none or enriching them fails.
+func (s *Server) enrich(ctx context.Context, repo string, entries []feed.Entry) []feed.Entry {
+	if s.Enricher == nil {
+		return entries
+	}
+	enriched, err := s.Enricher.Enrich(ctx, repo, entries)
+	if err != nil {
+		log.Warnf("Error enriching releases of %s: %v", repo, err)
+		return entries
+	}
+	return enriched
+}
+
+// classify returns entries marked by classify.Apply, or entries unchanged
+// when Classify is unset.
+func (s *Server) classify(entries []feed.Entry) []feed.Entry {
+	if !s.Classify {
+		return entries
+	}
+	return classify.Apply(entries)
+}
+
+// Feeds of a repo that are proxied. Upstream they are read from the Atom
+// file of the same name, except for advisories, which are built from the
+// GitHub API.
+const (
+	releasesFeed   = "releases"
+	tagsFeed       = "tags"
+	advisoriesFeed = "advisories"
+	releasesFile   = releasesFeed + ".atom"
+)
+
+// upstreamURL returns the URL of a feed file of repo below BaseURL.
+func (s *Server) upstreamURL(repo, file string) string {
+	baseURL := strings.TrimSuffix(s.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return fmt.Sprintf("%s/%s/%s", baseURL, repo, file)
+}
+
+// Categories returns the categories last loaded, sorted by slug.
+func (s *Server) Categories() []Category {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	categories := make([]Category, 0, len(s.categories))
+	for _, category := range s.categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Slug < categories[j].Slug })
+	return categories
+}
+
+// Feed returns the merged feed of the category with the given slug. Each
+// repo's entries are passed through its filter, entry titles are prefixed
+// with their owner/repo, entries sharing an ID are only included once, and
+// entries are ordered newest first with ties broken by ID so the order is the
+// same on every request. It reports false for unknown categories.
+func (s *Server) Feed(slug string) (*feed.Feed, bool, error) {
+	return s.feed(slug, nil)
+}
+
+// feed returns the merged feed of a category like Feed, keeping only the
+// entries that also pass f.
+func (s *Server) feed(slug string, f *filter.Filter) (*feed.Feed, bool, error) {
+	s.mu.RLock()
+	ready := !s.refreshed.IsZero()
+	category, ok := s.categories[slug]
+	s.mu.RUnlock()
+	if !ready {
+		return nil, false, ErrNotReady
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	id, title := "urn:ghreleases2rss:feeds:"+slug, "GitHub releases: "+category.Name
+	if query := f.Values().Encode(); query != "" {
+		id += "?" + query
+		title += " (" + f.String() + ")"
+	}
+	merged, err := s.merge(id, title, category.Repos, f)
+	return merged, err == nil, err
+}
+
+// MergedFeed returns a feed with the given ID and title merging the entries
+// of repos the way Feed merges a category's.
+func (s *Server) MergedFeed(id, title string, repos []Repo) (*feed.Feed, error) {
+	return s.merge(id, title, repos, nil)
+}
+
+// merge merges the entries of repos that also pass f, before applying Limit.
+func (s *Server) merge(id, title string, repos []Repo, f *filter.Filter) (*feed.Feed, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.refreshed.IsZero() {
+		return nil, ErrNotReady
+	}
+	return s.mergeEntries(id, title, repos, func(repo Repo) []feed.Entry {
+		return f.Apply(repo.Filter.Apply(s.releases[repo.Name]))
+	}), nil
+}
+
+// mergeEntries returns a feed with the given ID and title merging the
+// entries returned by entries for each repo, titled with their owner/repo,
+// without repeated IDs and ordered newest first with ties broken by ID, up
+// to Limit entries.
+func (s *Server) mergeEntries(id, title string, repos []Repo, entries func(Repo) []feed.Entry) *feed.Feed {
+	merged := &feed.Feed{ID: id, Title: title}
+	seen := make(map[string]bool)
+	for _, repo := range repos {
+		for _, entry := range entries(repo) {
+			if entry.ID == "" {
+				entry.ID = entry.Link
+			}
+			if seen[entry.ID] {
+				continue
+			}
+			seen[entry.ID] = true
+			entry.Title = repo.Name + ": " + entry.Title
+			merged.Entries = append(merged.Entries, entry)
+		}
+	}
+	sort.SliceStable(merged.Entries, func(i, j int) bool {
+		a, b := merged.Entries[i], merged.Entries[j]
+		if !a.Updated.Equal(b.Updated) {
+			return a.Updated.After(b.Updated)
+		}
+		return a.ID < b.ID
+	})
+	if s.Limit > 0 && len(merged.Entries) > s.Limit {
+		merged.Entries = merged.Entries[:s.Limit]
+	}
+	if len(merged.Entries) > 0 {
+		merged.Updated = merged.Entries[0].Updated
+	}
+	return merged
+}
+
+// Releases returns the last fetched entries of repo that pass its filter,
+// newest first as published.
+func (s *Server) Releases(repo Repo) ([]feed.Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.refreshed.IsZero() {
+		return nil, ErrNotReady
+	}
+	return repo.Filter.Apply(s.releases[repo.Name]), nil
+}
+
+// Handler returns the HTTP handler serving an index of the categories at /,
+// their merged feeds at /feeds/{slug}, the feed proxy at
+// /proxy/{owner}/{repo}/{feed}, advisories at /advisories/{slug} and image
+// tags at /image-tags/{image}. Feeds are served as Atom, JSON Feed, RSS or
+// iCalendar by extension or Accept header.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.serveIndex)
+	mux.HandleFunc("GET /feeds/{file}", s.serveFeed)
+	mux.HandleFunc("GET /advisories/{file}", s.serveCategoryAdvisories)
+	mux.HandleFunc("GET /proxy/{owner}/{repo}/{file}", s.serveProxy)
+	mux.HandleFunc("GET /image-tags/{image...}", s.serveImageTags)
+	return mux
+}
+
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, category := range s.Categories() {
+		fmt.Fprintf(w, "%s\t%s/feeds/%s.atom\t%d repos", category.Name, baseURL(r), category.Slug, len(category.Repos))
+		if s.API != nil {
+			fmt.Fprintf(w, "\t%s/advisories/%s.atom", baseURL(r), category.Slug)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request) {
+	slug, format, ok := requestFormat(w, r, r.PathValue("file"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := filter.Parse(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	merged, ok, err := s.feed(slug, f)
+	if errors.Is(err, ErrNotReady) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeFeed(w, r, merged, format)
+}
+
+// serveProxy serves a repo's release or tag feed from the cache. Without
+// query parameters, and for release feeds without an Enricher or Classify,
+// the upstream document is passed through unchanged; otherwise release
+// entries are enriched and classified and only the entries that pass the
+// filter given by the query parameters are served. Unless ProxyAny is set,
+// only repos in the categories are served, and advisories only ever are.
+func (s *Server) serveProxy(w http.ResponseWriter, r *http.Request) {
+	repo := r.PathValue("owner") + "/" + r.PathValue("repo")
+	name, format, ok := requestFormat(w, r, r.PathValue("file"))
+	if !ok || (name != releasesFeed && name != tagsFeed && (name != advisoriesFeed || s.API == nil)) {
+		http.NotFound(w, r)
+		return
+	}
+	file := name + ".atom"
+	if _, err := github.ParseRepo(repo); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Advisories are read with the GitHub token, so they are only served for
+	// tracked repos even when ProxyAny is set
+	if !s.ProxyAny || name == advisoriesFeed {
+		tracked, err := s.isTracked(repo)
+		if errors.Is(err, ErrNotReady) {
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if !tracked {
+			http.Error(w, repo+" is not tracked by this server", http.StatusNotFound)
+			return
+		}
+	}
+	if name == advisoriesFeed {
+		s.serveAdvisories(w, r, repo, format)
+		return
+	}
+	f, err := filter.Parse(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.cache().Get(r.Context(), s.upstreamURL(repo, file))
+	switch {
+	case errors.Is(err, cache.ErrNotFound):
+		http.NotFound(w, r)
+		return
+	case err != nil:
+		log.Warnf("Error fetching %s of %s: %v", file, repo, err)
+		http.Error(w, "error fetching feed", http.StatusBadGateway)
+		return
+	}
+	if resp.Stale {
+		w.Header().Set("X-Cache", "STALE")
+	}
+
+	rewritten := (s.Enricher != nil || s.Classify) && file == releasesFile
+	if len(r.URL.Query()) == 0 && format == feed.FormatAtom && !rewritten {
+		modified, _ := http.ParseTime(resp.LastModified)
+		serveBytes(w, r, resp.Body, format, modified)
+		return
+	}
+
+	upstream, err := feed.Parse(resp.Body)
+	if err != nil {
+		log.Warnf("Error parsing %s of %s: %v", file, repo, err)
+		http.Error(w, "error parsing feed", http.StatusBadGateway)
+		return
+	}
+	if rewritten {
+		upstream.Entries = s.classify(s.enrich(r.Context(), repo, upstream.Entries))
+	}
+	upstream.Entries = f.Apply(upstream.Entries)
+	if len(r.URL.Query()) > 0 {
+		upstream.ID += "?" + f.Values().Encode()
+		upstream.Title += " (" + f.String() + ")"
+	}
+	if len(upstream.Entries) > 0 {
+		upstream.Updated = upstream.Entries[0].Updated
+	}
+	writeFeed(w, r, upstream, format)
+}
+
+// isTracked reports whether repo is in the categories. It returns
+// ErrNotReady before the categories are first loaded.
+func (s *Server) isTracked(repo string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.loaded {
+		return false, ErrNotReady
+	}
+	return s.tracked[strings.ToLower(repo)], nil
+}
+
+// serveAdvisories serves the security advisories of repo from the GitHub
+// API. Advisories have no tags, so filter options are rejected.
+func (s *Server) serveAdvisories(w http.ResponseWriter, r *http.Request, repo string, format feed.Format) {
+	if len(r.URL.Query()) > 0 {
+		http.Error(w, "advisories feeds take no filter options", http.StatusBadRequest)
+		return
+	}
+	advisories, err := s.API.Advisories(r.Context(), repo)
+	switch {
+	case errors.Is(err, cache.ErrNotFound):
+		http.NotFound(w, r)
+		return
+	case err != nil:
+		log.Warnf("Error fetching advisories of %s: %v", repo, err)
+		http.Error(w, "error fetching advisories", http.StatusBadGateway)
+		return
+	}
+	writeFeed(w, r, advisory.Feed(repo, advisories), format)
+}
+
+// Advisories returns a feed merging the security advisories of the repos in
+// the category with the given slug as fetched by the last refresh, merged
+// like Feed merges releases. It returns ErrNotReady before advisories are
+// first fetched and reports false for unknown categories.
+func (s *Server) Advisories(slug string) (*feed.Feed, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.advisories == nil {
+		return nil, false, ErrNotReady
+	}
+	category, ok := s.categories[slug]
+	if !ok {
+		return nil, false, nil
+	}
+	merged := s.mergeEntries("urn:ghreleases2rss:advisories:"+slug, "Security advisories: "+category.Name, category.Repos, func(repo Repo) []feed.Entry {
+		return s.advisories[repo.Name]
+	})
+	return merged, true, nil
+}
+
+// serveCategoryAdvisories serves the merged security advisories of a
+// category when API is set.
+func (s *Server) serveCategoryAdvisories(w http.ResponseWriter, r *http.Request) {
+	slug, format, ok := requestFormat(w, r, r.PathValue("file"))
+	if !ok || s.API == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if len(r.URL.Query()) > 0 {
+		http.Error(w, "advisories feeds take no filter options", http.StatusBadRequest)
+		return
+	}
+	merged, ok, err := s.Advisories(slug)
+	if errors.Is(err, ErrNotReady) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeFeed(w, r, merged, format)
+}
+
+// serveImageTags serves the tags of a container image first seen by
+// ImageTags, filtered by the query parameters like release feeds.
+func (s *Server) serveImageTags(w http.ResponseWriter, r *http.Request) {
+	if s.ImageTags == nil {
+		http.NotFound(w, r)
+		return
+	}
+	// Image names contain the dots of registry hosts, so only a known
+	// extension is cut off
+	image, format, ok := feed.CutExtension(r.PathValue("image"))
+	if !ok {
+		w.Header().Add("Vary", "Accept")
+		format = feed.Negotiate(r.Header.Get("Accept"))
+	}
+	f, err := filter.Parse(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tags, err := s.ImageTags.Feed(image)
+	switch {
+	case errors.Is(err, imagetags.ErrInvalidImage):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, imagetags.ErrRegistryNotAllowed):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, imagetags.ErrImageNotTracked):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, oci.ErrNotFound):
+		http.NotFound(w, r)
+		return
+	case err != nil:
+		log.Warnf("Error listing tags of %s: %v", image, err)
+		http.Error(w, "error listing tags", http.StatusBadGateway)
+		return
+	}
+	tags.Entries = f.Apply(tags.Entries)
+	if len(r.URL.Query()) > 0 {
+		tags.ID += "?" + f.Values().Encode()
+		tags.Title += " (" + f.String() + ")"
+	}
+	if len(tags.Entries) > 0 {
+		tags.Updated = tags.Entries[0].Updated
+	}
+	writeFeed(w, r, tags, format)
+}
+
+// requestFormat splits the requested file name into the feed name and the
+// format given by its extension or, without one, by the Accept header. ok is
+// false for unsupported extensions.
+func requestFormat(w http.ResponseWriter, r *http.Request, file string) (string, feed.Format, bool) {
+	if name, format, ok := feed.CutExtension(file); ok {
+		return name, format, true
+	}
+	if strings.Contains(file, ".") {
+		return "", "", false
+	}
+	w.Header().Add("Vary", "Accept")
+	return file, feed.Negotiate(r.Header.Get("Accept")), true
+}
+
+// writeFeed serves f in format.
+func writeFeed(w http.ResponseWriter, r *http.Request, f *feed.Feed, format feed.Format) {
+	var buf bytes.Buffer
+	if err := feed.Write(&buf, f, format, baseURL(r)+r.URL.RequestURI()); err != nil {
+		log.Errorf("Error writing feed %s: %v", r.URL.Path, err)
+		http.Error(w, "error writing feed", http.StatusInternalServerError)
+		return
+	}
+	serveBytes(w, r, buf.Bytes(), format, f.Updated)
+}
+
+// serveBytes serves a feed document in format with an ETag derived from its
+// content, answering conditional requests by ETag and modification time.
+func serveBytes(w http.ResponseWriter, r *http.Request, body []byte, format feed.Format, modified time.Time) {
+	sum := sha256.Sum256(body)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Content-Type", format.ContentType())
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
+
+// ProxyURL returns the URL of repo's release feed on the proxy served at
+// base, with the options of f encoded as query parameters.
+func ProxyURL(base, repo string, f *filter.Filter) string {
+	proxyURL := fmt.Sprintf("%s/proxy/%s/releases.atom", strings.TrimSuffix(base, "/"), repo)
+	if query := f.Values().Encode(); query != "" {
+		proxyURL += "?" + query
+	}
+	return proxyURL
+}
+
+// baseURL returns the scheme and host the request was made to, honouring
+// X-Forwarded-Proto from a reverse proxy.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,813 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/enrich"
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/filter"
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/imagetags"
+	"github.com/toozej/ghreleases2rss/internal/input"
+	"github.com/toozej/ghreleases2rss/internal/oci"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Dev Tools":     "dev-tools",
+		"  CI / CD  ":   "ci-cd",
+		"kubernetes":    "kubernetes",
+		"Go 1.26 libs!": "go-1-26-libs",
+		"---":           "",
+	}
+	for name, want := range tests {
+		if got := Slug(name); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCategories(t *testing.T) {
+	lines := []input.Line{
+		{Source: "a.txt", Number: 1, Text: "owner/top"},
+		{Source: "a.txt", Number: 3, Text: "https://github.com/owner/cli", Section: "Dev Tools"},
+		{Source: "a.txt", Number: 4, Text: "not a repo", Section: "Dev Tools"},
+		{Source: "a.txt", Number: 6, Text: "owner/k8s@v1.30.0", Section: "Infra"},
+		{Source: "b.txt", Number: 2, Text: "owner/cli", Section: "dev tools"},
+		{Source: "b.txt", Number: 3, Text: "owner/fmt", Section: "dev tools"},
+		{Source: "b.txt", Number: 4, Text: "owner/mono prefix=cli/", Section: "dev tools"},
+		{Source: "b.txt", Number: 5, Text: "owner/mono prefix=cli/ prefix=sdk/", Section: "dev tools"},
+		{Source: "b.txt", Number: 6, Text: "owner/bad flavour=vanilla", Section: "dev tools"},
+	}
+	got := Categories(lines, "Releases")
+	want := map[string][]string{
+		"dev-tools": {"owner/cli", "owner/fmt", "owner/mono prefix=cli/", "owner/mono prefix=cli/ prefix=sdk/"},
+		"infra":     {"owner/k8s"},
+		"releases":  {"owner/top"},
+	}
+	names := map[string]string{"dev-tools": "Dev Tools", "infra": "Infra", "releases": "Releases"}
+	if len(got) != len(want) {
+		t.Fatalf("Categories() = %+v, want %d categories", got, len(want))
+	}
+	for i, slug := range []string{"dev-tools", "infra", "releases"} {
+		if got[i].Slug != slug || got[i].Name != names[slug] {
+			t.Errorf("Categories()[%d] = %s %q, want %s %q", i, got[i].Slug, got[i].Name, slug, names[slug])
+		}
+		var repos []string
+		for _, repo := range got[i].Repos {
+			repos = append(repos, strings.TrimSpace(repo.Name+" "+repo.Filter.String()))
+		}
+		if !reflect.DeepEqual(repos, want[slug]) {
+			t.Errorf("Categories()[%d].Repos = %v, want %v", i, repos, want[slug])
+		}
+	}
+}
+
+// repos returns unfiltered category repos.
+func repos(names ...string) []Repo {
+	var list []Repo
+	for _, name := range names {
+		list = append(list, Repo{Name: name})
+	}
+	return list
+}
+
+const upstreamFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>tag:github.com,2008:https://github.com/%[1]s/releases</id>
+  <title>Release notes from %[1]s</title>
+  <updated>2026-03-01T10:00:00Z</updated>
+  %[2]s
+</feed>`
+
+const upstreamEntry = `<entry>
+    <id>tag:github.com,2008:Repository/%[1]s</id>
+    <updated>%[2]s</updated>
+    <link rel="alternate" href="https://github.com/%[3]s/releases/tag/%[5]s"/>
+    <title>%[4]s</title>
+    <content type="html">&lt;p&gt;Notes&lt;/p&gt;</content>
+  </entry>`
+
+const upstreamTags = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Tags from mono</title></feed>`
+
+// upstreamRelease formats a feed entry, escaping slashes in the tag link as
+// GitHub does.
+func upstreamRelease(id, updated, repo, tag string) string {
+	return fmt.Sprintf(upstreamEntry, id, updated, repo, tag, url.PathEscape(tag))
+}
+
+func newUpstream(t *testing.T, failing *atomic.Bool) *httptest.Server {
+	t.Helper()
+	entries := map[string]string{
+		"owner/a": upstreamRelease("1/v2.0.0", "2026-03-01T10:00:00Z", "owner/a", "v2.0.0") +
+			upstreamRelease("1/v1.0.0", "2026-01-01T10:00:00Z", "owner/a", "v1.0.0"),
+		"owner/b": upstreamRelease("2/v0.9.0", "2026-02-01T10:00:00Z", "owner/b", "v0.9.0"),
+		// owner/renamed redirects to owner/a on GitHub, so its entries share IDs.
+		"owner/renamed": upstreamRelease("1/v2.0.0", "2026-03-01T10:00:00Z", "owner/a", "v2.0.0"),
+		"owner/tie":     upstreamRelease("3/v3.0.0", "2026-03-01T10:00:00Z", "owner/tie", "v3.0.0"),
+		"owner/mono": upstreamRelease("4/cli/v2.1.0", "2026-04-01T10:00:00Z", "owner/mono", "cli/v2.1.0") +
+			upstreamRelease("4/sdk/v0.3.0", "2026-03-20T10:00:00Z", "owner/mono", "sdk/v0.3.0") +
+			upstreamRelease("4/cli/v2.0.0-rc.1", "2026-03-10T10:00:00Z", "owner/mono", "cli/v2.0.0-rc.1") +
+			upstreamRelease("4/cli/v1.2.0", "2026-02-10T10:00:00Z", "owner/mono", "cli/v1.2.0"),
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/owner/mono/tags.atom" {
+			fmt.Fprint(w, upstreamTags)
+			return
+		}
+		repo := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/releases.atom")
+		body, ok := entries[repo]
+		if !ok || (failing != nil && failing.Load()) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, upstreamFeed, repo, body)
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func TestServerFeed(t *testing.T) {
+	var failing atomic.Bool
+	upstream := newUpstream(t, &failing)
+	s := &Server{
+		BaseURL: upstream.URL,
+		Cache:   &cache.Cache{MaxAge: time.Nanosecond},
+		Spread:  20 * time.Millisecond,
+		Load: func() ([]Category, error) {
+			return []Category{
+				{Slug: "all", Name: "All", Repos: repos("owner/b", "owner/a", "owner/renamed", "owner/tie", "owner/missing")},
+				{Slug: "small", Name: "Small", Repos: repos("owner/b")},
+			}, nil
+		},
+	}
+
+	if _, _, err := s.Feed("all"); err != ErrNotReady {
+		t.Errorf("Feed() before refresh error = %v, want ErrNotReady", err)
+	}
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	merged, ok, err := s.Feed("all")
+	if err != nil || !ok {
+		t.Fatalf("Feed() = %v, %v", ok, err)
+	}
+	var titles []string
+	for _, entry := range merged.Entries {
+		titles = append(titles, entry.Title)
+	}
+	want := []string{"owner/a: v2.0.0", "owner/tie: v3.0.0", "owner/b: v0.9.0", "owner/a: v1.0.0"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Errorf("Feed() titles = %v, want %v", titles, want)
+	}
+	if merged.Title != "GitHub releases: All" || !merged.Updated.Equal(merged.Entries[0].Updated) {
+		t.Errorf("Feed() = %q updated %v", merged.Title, merged.Updated)
+	}
+
+	s.Limit = 1
+	if merged, _, _ := s.Feed("all"); len(merged.Entries) != 1 {
+		t.Errorf("Feed() with Limit 1 returned %d entries", len(merged.Entries))
+	}
+	if _, ok, _ := s.Feed("unknown"); ok {
+		t.Error("Feed() should report unknown categories")
+	}
+
+	// Feeds that fail to refresh keep their last entries
+	failing.Store(true)
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if merged, _, _ := s.Feed("small"); len(merged.Entries) != 1 {
+		t.Errorf("Feed() after failed refresh returned %d entries, want 1", len(merged.Entries))
+	}
+}
+
+func TestServerHandler(t *testing.T) {
+	upstream := newUpstream(t, nil)
+	s := &Server{
+		BaseURL: upstream.URL,
+		Load: func() ([]Category, error) {
+			return []Category{{Slug: "dev-tools", Name: "Dev Tools", Repos: repos("owner/a", "owner/b")}}, nil
+		},
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/feeds/dev-tools.atom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GET before refresh status = %d, want 503", resp.StatusCode)
+	}
+
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		accept     string
+		wantStatus int
+		wantType   string
+	}{
+		{name: "Atom", path: "/feeds/dev-tools.atom", wantStatus: http.StatusOK, wantType: feed.AtomContentType},
+		{name: "JSON Feed", path: "/feeds/dev-tools.json", wantStatus: http.StatusOK, wantType: feed.JSONFeedContentType},
+		{name: "RSS", path: "/feeds/dev-tools.rss", wantStatus: http.StatusOK, wantType: feed.RSSContentType},
+		{name: "iCalendar", path: "/feeds/dev-tools.ics", wantStatus: http.StatusOK, wantType: feed.ICSContentType},
+		{name: "Extension wins over Accept", path: "/feeds/dev-tools.rss", accept: "application/feed+json", wantStatus: http.StatusOK, wantType: feed.RSSContentType},
+		{name: "Accept", path: "/feeds/dev-tools", accept: "application/atom+xml;q=0.5, application/feed+json", wantStatus: http.StatusOK, wantType: feed.JSONFeedContentType},
+		{name: "Default format", path: "/feeds/dev-tools", accept: "*/*", wantStatus: http.StatusOK, wantType: feed.AtomContentType},
+		{name: "Filtered", path: "/feeds/dev-tools.ics?level=major", wantStatus: http.StatusOK, wantType: feed.ICSContentType},
+		{name: "Invalid filter", path: "/feeds/dev-tools.atom?level=huge", wantStatus: http.StatusBadRequest},
+		{name: "Unknown extension", path: "/feeds/dev-tools.html", wantStatus: http.StatusNotFound},
+		{name: "Unknown category", path: "/feeds/infra.atom", wantStatus: http.StatusNotFound},
+		{name: "Index", path: "/", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("Content-Type"); tt.wantType != "" && got != tt.wantType {
+				t.Errorf("GET %s Content-Type = %q, want %q", tt.path, got, tt.wantType)
+			}
+		})
+	}
+
+	client := &http.Client{}
+	resp, err = client.Get(ts.URL + "/feeds/dev-tools.atom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != feed.AtomContentType {
+		t.Errorf("Content-Type = %q", got)
+	}
+	parsed, err := feed.Fetch(nil, ts.URL+"/feeds/dev-tools.atom")
+	if err != nil {
+		t.Fatalf("Fetch() of served feed error = %v", err)
+	}
+	if len(parsed.Entries) != 3 || parsed.Entries[0].Title != "owner/a: v2.0.0" {
+		t.Errorf("served feed entries = %+v", parsed.Entries)
+	}
+	major, err := feed.Fetch(nil, ts.URL+"/feeds/dev-tools.atom?level=major")
+	if err != nil {
+		t.Fatalf("Fetch() of filtered feed error = %v", err)
+	}
+	if len(major.Entries) != 2 || major.Entries[1].Title != "owner/a: v1.0.0" || major.Title != "GitHub releases: Dev Tools (level=major)" {
+		t.Errorf("filtered feed = %q with entries %+v", major.Title, major.Entries)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/feeds/dev-tools.atom", nil)
+	req.Header.Set("If-Modified-Since", resp.Header.Get("Last-Modified"))
+	notModified, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notModified.Body.Close()
+	if notModified.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional GET status = %d, want 304", notModified.StatusCode)
+	}
+}
+
+func TestServerProxy(t *testing.T) {
+	upstream := newUpstream(t, nil)
+	s := &Server{BaseURL: upstream.URL, ProxyAny: true}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantTags   []string
+	}{
+		{
+			name:       "No filter",
+			wantStatus: http.StatusOK,
+			wantTags:   []string{"cli/v2.1.0", "sdk/v0.3.0", "cli/v2.0.0-rc.1", "cli/v1.2.0"},
+		},
+		{
+			name:       "Prefix without prereleases",
+			query:      "prefix=cli/&prerelease=false",
+			wantStatus: http.StatusOK,
+			wantTags:   []string{"cli/v2.1.0", "cli/v1.2.0"},
+		},
+		{
+			name:       "Constraint",
+			query:      "prefix=cli/&constraint=" + url.QueryEscape(">=2.0 <3"),
+			wantStatus: http.StatusOK,
+			wantTags:   []string{"cli/v2.1.0"},
+		},
+		{
+			name:       "Exclude",
+			query:      "exclude=" + url.QueryEscape("^sdk/"),
+			wantStatus: http.StatusOK,
+			wantTags:   []string{"cli/v2.1.0", "cli/v2.0.0-rc.1", "cli/v1.2.0"},
+		},
+		{
+			name:       "Unknown option",
+			query:      "flavour=vanilla",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid constraint",
+			query:      "constraint=banana",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + "/proxy/owner/mono/releases.atom?" + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proxied, err := feed.Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var tags []string
+			for _, entry := range proxied.Entries {
+				tags = append(tags, entry.Tag())
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", tags, tt.wantTags)
+			}
+		})
+	}
+
+	for path, wantStatus := range map[string]int{
+		"/proxy/owner/missing/releases.atom": http.StatusNotFound,
+		"/proxy/owner/mono/commits.atom":     http.StatusNotFound,
+		"/proxy/owner/mono/releases.html":    http.StatusNotFound,
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != wantStatus {
+			t.Errorf("GET %s status = %d, want %d", path, resp.StatusCode, wantStatus)
+		}
+	}
+
+	// Other formats are converted from the same entries and filtered alike
+	resp, err := http.Get(ts.URL + "/proxy/owner/mono/releases.json?prefix=sdk/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonFeed struct {
+		Items []struct {
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&jsonFeed)
+	resp.Body.Close()
+	if err != nil || len(jsonFeed.Items) != 1 || jsonFeed.Items[0].Title != "sdk/v0.3.0" {
+		t.Errorf("releases.json = %+v, %v, want only sdk/v0.3.0", jsonFeed, err)
+	}
+
+	// Unfiltered feeds are passed through unchanged and can be revalidated
+	resp, err = http.Get(ts.URL + "/proxy/owner/mono/tags.atom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != upstreamTags || resp.Header.Get("ETag") == "" {
+		t.Errorf("tags.atom = %q with ETag %q", data, resp.Header.Get("ETag"))
+	}
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/proxy/owner/mono/tags.atom", nil)
+	req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+	notModified, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notModified.Body.Close()
+	if notModified.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional GET status = %d, want 304", notModified.StatusCode)
+	}
+}
+
+func TestServerProxyTracked(t *testing.T) {
+	upstream := newUpstream(t, nil)
+	s := &Server{
+		BaseURL: upstream.URL,
+		Load: func() ([]Category, error) {
+			return []Category{{Slug: "all", Name: "All", Repos: repos("owner/a")}}, nil
+		},
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	get := func(path string) int {
+		t.Helper()
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := get("/proxy/owner/a/releases.atom"); status != http.StatusServiceUnavailable {
+		t.Errorf("GET before refresh status = %d, want 503", status)
+	}
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/proxy/owner/a/releases.atom", http.StatusOK},
+		{"/proxy/owner/mono/releases.atom", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if status := get(tt.path); status != tt.wantStatus {
+			t.Errorf("GET %s status = %d, want %d", tt.path, status, tt.wantStatus)
+		}
+	}
+	if tracked, err := s.isTracked("Owner/A"); err != nil || !tracked {
+		t.Errorf("isTracked(Owner/A) = %v, %v, want names compared case-insensitively", tracked, err)
+	}
+}
+
+func TestServerProxyEnriched(t *testing.T) {
+	upstream := newUpstream(t, nil)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/mono/releases":
+			fmt.Fprint(w, `[
+				{"tag_name": "cli/v2.1.0", "published_at": "2026-04-01T10:00:00Z", "html_url": "https://github.com/owner/mono/releases/tag/cli/v2.1.0",
+				 "assets": [{"name": "cli.tar.gz", "size": 10, "browser_download_url": "https://github.com/owner/mono/releases/download/cli/v2.1.0/cli.tar.gz"}]},
+				{"tag_name": "sdk/v0.3.0", "prerelease": true, "published_at": "2026-03-20T10:00:00Z", "html_url": "https://github.com/owner/mono/releases/tag/sdk/v0.3.0"}
+			]`)
+		case "/repos/owner/mono/releases/latest":
+			fmt.Fprint(w, `{"tag_name": "cli/v2.1.0"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+	s := &Server{BaseURL: upstream.URL, ProxyAny: true, Enricher: &enrich.Enricher{API: github.NewAPI(api.URL, "", nil)}}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	get := func(path string) *feed.Feed {
+		t.Helper()
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		parsed, err := feed.Parse(data)
+		if err != nil {
+			t.Fatalf("GET %s: Parse() error = %v", path, err)
+		}
+		return parsed
+	}
+
+	// Unfiltered release feeds are enriched rather than passed through
+	enriched := get("/proxy/owner/mono/releases.atom")
+	if first := enriched.Entries[0]; len(first.Links) != 1 || first.Links[0].Rel != "enclosure" || first.Content != "<p>Notes</p>" || !slices.Contains(first.Categories, feed.CategoryLatest) {
+		t.Errorf("enriched Entries[0] = %+v", first)
+	}
+	if title := enriched.Entries[1].Title; title != "[prerelease] sdk/v0.3.0" {
+		t.Errorf("enriched Entries[1].Title = %q", title)
+	}
+
+	// Releases marked as prereleases on GitHub are filtered out too
+	var tags []string
+	for _, entry := range get("/proxy/owner/mono/releases.atom?prerelease=false").Entries {
+		tags = append(tags, entry.Tag())
+	}
+	if want := []string{"cli/v2.1.0", "cli/v1.2.0"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+}
+
+func TestServerClassify(t *testing.T) {
+	upstream := newUpstream(t, nil)
+	s := &Server{
+		BaseURL:  upstream.URL,
+		Classify: true,
+		ProxyAny: true,
+		Load: func() ([]Category, error) {
+			return []Category{{Slug: "all", Name: "All", Repos: repos("owner/a", "owner/b")}}, nil
+		},
+	}
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	merged, _, err := s.Feed("all")
+	if err != nil {
+		t.Fatalf("Feed() error = %v", err)
+	}
+	var titles []string
+	for _, entry := range merged.Entries {
+		titles = append(titles, entry.Title)
+	}
+	if want := []string{"owner/a: [MAJOR] v2.0.0", "owner/b: v0.9.0", "owner/a: v1.0.0"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("Feed() titles = %v, want %v", titles, want)
+	}
+	if categories := merged.Entries[0].Categories; !reflect.DeepEqual(categories, []string{"major"}) {
+		t.Errorf("Feed() Entries[0].Categories = %v", categories)
+	}
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/proxy/owner/mono/releases.atom?prefix=cli/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	proxied, err := feed.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	titles = nil
+	for _, entry := range proxied.Entries {
+		titles = append(titles, entry.Title)
+	}
+	// Prereleases are skipped as previous release, so the release candidate
+	// and the final release are both major bumps over cli/v1.2.0
+	if want := []string{"[MAJOR] cli/v2.1.0", "[MAJOR] cli/v2.0.0-rc.1", "cli/v1.2.0"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("proxied titles = %v, want %v", titles, want)
+	}
+}
+
+func TestServerAdvisories(t *testing.T) {
+	var fetches atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/a/security-advisories":
+			fetches.Add(1)
+			fmt.Fprint(w, `[{"ghsa_id": "GHSA-jfh8-c2jp-5v3q", "html_url": "https://github.com/owner/a/security/advisories/GHSA-jfh8-c2jp-5v3q",
+				"summary": "Path traversal", "severity": "critical", "updated_at": "2026-03-02T10:00:00Z",
+				"vulnerabilities": [{"package": {"name": "owner/a"}, "vulnerable_version_range": "< 2.0.1", "patched_versions": "2.0.1"}]}]`)
+		case "/repos/owner/failing/security-advisories":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+	s := &Server{
+		BaseURL:  newUpstream(t, nil).URL,
+		ProxyAny: true,
+		API:      github.NewAPI(api.URL, "", nil),
+		Load: func() ([]Category, error) {
+			return []Category{{Slug: "all", Name: "All", Repos: repos("owner/a", "owner/missing", "owner/failing")}}, nil
+		},
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	if _, _, err := s.Advisories("all"); !errors.Is(err, ErrNotReady) {
+		t.Errorf("Advisories() before Refresh() error = %v, want ErrNotReady", err)
+	}
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	// Advisories are fetched with the releases rather than on request
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("Refresh() fetched advisories of owner/a %d times, want 1", n)
+	}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/proxy/owner/a/advisories.atom", http.StatusOK, "<title>[CRITICAL] GHSA-jfh8-c2jp-5v3q: Path traversal</title>"},
+		{"/proxy/owner/a/advisories.json", http.StatusOK, `"title": "[CRITICAL] GHSA-jfh8-c2jp-5v3q: Path traversal"`},
+		{"/proxy/owner/a/advisories.atom?prerelease=false", http.StatusBadRequest, "no filter options"},
+		{"/proxy/owner/missing/advisories.atom", http.StatusNotFound, ""},
+		{"/proxy/owner/failing/advisories.atom", http.StatusBadGateway, ""},
+		// Advisories of untracked repos are not fetched, even with ProxyAny
+		{"/proxy/owner/b/advisories.atom", http.StatusNotFound, "not tracked"},
+		{"/proxy/owner/b/releases.atom", http.StatusOK, "v0.9.0"},
+		{"/advisories/all.atom", http.StatusOK, "<title>owner/a: [CRITICAL] GHSA-jfh8-c2jp-5v3q: Path traversal</title>"},
+		{"/advisories/all.json", http.StatusOK, `"title": "Security advisories: All"`},
+		{"/advisories/all.atom?prerelease=false", http.StatusBadRequest, "no filter options"},
+		{"/advisories/infra.atom", http.StatusNotFound, ""},
+		{"/", http.StatusOK, "/advisories/all.atom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("GET %s = %d %s", tt.path, resp.StatusCode, body)
+			}
+		})
+	}
+
+	// Without an API client advisories are not served
+	noAPI := httptest.NewServer((&Server{}).Handler())
+	defer noAPI.Close()
+	resp, err := http.Get(noAPI.URL + "/proxy/owner/a/advisories.atom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET advisories without API = %d, want 404", resp.StatusCode)
+	}
+	resp, err = http.Get(noAPI.URL + "/advisories/all.atom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET category advisories without API = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestServerImageTags(t *testing.T) {
+	var listed atomic.Int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/org/app/tags/list":
+			// The first listing is the baseline, later ones add two tags
+			if listed.Add(1) == 1 {
+				fmt.Fprint(w, `{"name":"org/app","tags":["1.0.0"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"name":"org/app","tags":["1.0.0","1.1.0","2.0.0-rc.1"]}`)
+		case strings.HasPrefix(r.URL.Path, "/v2/org/app/manifests/"):
+			w.Header().Set("Docker-Content-Digest", "sha256:"+path.Base(r.URL.Path))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+	s := &Server{ImageTags: &imagetags.Tracker{
+		Client:     &oci.Client{PlainHTTP: true},
+		Registries: []string{host},
+		Images:     []string{host + "/org/app", host + "/org/missing"},
+		MaxAge:     time.Nanosecond,
+	}}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+		notBody    string
+	}{
+		{"/image-tags/" + host + "/org/app.atom", http.StatusOK, "<title>Tags of " + host + "/org/app</title>", "<entry>"},
+		{"/image-tags/" + host + "/org/app.atom", http.StatusOK, "<title>2.0.0-rc.1</title>", "<title>1.0.0</title>"},
+		{"/image-tags/" + host + "/org/app.json?prerelease=false", http.StatusOK, `"title": "1.1.0"`, "2.0.0-rc.1"},
+		{"/image-tags/" + host + "/org/app", http.StatusOK, "sha256:1.1.0", ""},
+		{"/image-tags/" + host + "/org/app:1.0.0.atom", http.StatusBadRequest, "", ""},
+		{"/image-tags/ghcr.io/owner/image.atom", http.StatusForbidden, "", ""},
+		{"/image-tags/" + host + "/org/missing.atom", http.StatusNotFound, "", ""},
+		{"/image-tags/" + host + "/org/other.atom", http.StatusNotFound, "not tracked", ""},
+		{"/image-tags/" + host + "/org/app.atom?level=bogus", http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) ||
+				(tt.notBody != "" && strings.Contains(string(body), tt.notBody)) {
+				t.Errorf("GET %s = %d %s", tt.path, resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestServerWarm(t *testing.T) {
+	upstream := newUpstream(t, nil)
+	dir := t.TempDir()
+	load := func() ([]Category, error) {
+		return []Category{{Slug: "all", Name: "All", Repos: repos("owner/a", "owner/b")}}, nil
+	}
+	first := &Server{BaseURL: upstream.URL, Cache: &cache.Cache{Dir: dir}, Load: load}
+	if err := first.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	upstream.Close()
+
+	// A restarted server serves the cached feeds before its first refresh
+	restarted := &Server{BaseURL: upstream.URL, Cache: &cache.Cache{Dir: dir}, Load: load}
+	if err := restarted.Warm(); err != nil {
+		t.Fatalf("Warm() error = %v", err)
+	}
+	merged, ok, err := restarted.Feed("all")
+	if err != nil || !ok || len(merged.Entries) != 3 {
+		t.Errorf("Feed() after Warm() = %v, %v, %v", merged, ok, err)
+	}
+}
+
+func TestServerWarmCold(t *testing.T) {
+	upstream := newUpstream(t, nil)
+	s := &Server{
+		BaseURL: upstream.URL,
+		Load: func() ([]Category, error) {
+			return []Category{{Slug: "all", Name: "All", Repos: repos("owner/a")}}, nil
+		},
+	}
+	if err := s.Warm(); err != nil {
+		t.Fatalf("Warm() error = %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	// Without cached feeds the merged feeds wait for the first refresh, but
+	// the proxy already knows which repos are tracked
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/feeds/all.atom", http.StatusServiceUnavailable},
+		{"/proxy/owner/a/releases.atom", http.StatusOK},
+		{"/proxy/owner/mono/releases.atom", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("GET %s status = %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+		}
+	}
+}
+
+func TestProxyURL(t *testing.T) {
+	_, f, err := filter.Cut(`owner/mono prefix=cli/ constraint=">=2.0 <3"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		filter *filter.Filter
+		want   string
+	}{
+		{name: "Unfiltered", want: "https://feeds.example.com/proxy/owner/mono/releases.atom"},
+		{name: "Filtered", filter: f, want: "https://feeds.example.com/proxy/owner/mono/releases.atom?constraint=%3E%3D2.0+%3C3&prefix=cli%2F"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProxyURL("https://feeds.example.com/", "owner/mono", tt.filter); got != tt.want {
+				t.Errorf("ProxyURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
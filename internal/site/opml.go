@@ -0,0 +1,78 @@
+package site
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/server"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Created string        `xml:"head>dateCreated"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Type     string        `xml:"type,attr,omitempty"`
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline,omitempty"`
+}
+
+// writeOPML writes an OPML 2.0 subscription list with an outline per
+// category holding the release feed of each of its repos, and with
+// opts.BaseURL set, an outline holding the merged feeds of the site.
+func writeOPML(w io.Writer, categories []server.Category, opts Options) error {
+	doc := opmlDocument{Version: "2.0", Title: opts.Title, Created: opts.Now.UTC().Format(time.RFC1123Z)}
+	for _, category := range categories {
+		outline := opmlOutline{Text: category.Name, Title: category.Name}
+		for _, repo := range category.Repos {
+			outline.Outlines = append(outline.Outlines, opmlOutline{
+				Type:    "rss",
+				Text:    repo.Name,
+				Title:   repo.Name,
+				XMLURL:  feedURL(repo, opts.ProxyURL),
+				HTMLURL: "https://github.com/" + repo.Name + "/releases",
+			})
+		}
+		doc.Body = append(doc.Body, outline)
+	}
+
+	if opts.BaseURL != "" {
+		baseURL := strings.TrimSuffix(opts.BaseURL, "/")
+		merged := opmlOutline{Text: opts.Title, Title: opts.Title}
+		feeds := []opmlOutline{{Text: "Latest releases", XMLURL: baseURL + "/" + releasesFeed + feed.FormatAtom.Extension(), HTMLURL: baseURL + "/index.html"}}
+		for _, category := range categories {
+			feeds = append(feeds, opmlOutline{
+				Text:    category.Name,
+				XMLURL:  baseURL + "/" + path.Join("feeds", category.Slug) + feed.FormatAtom.Extension(),
+				HTMLURL: baseURL + "/" + categoryPath(category.Slug),
+			})
+		}
+		for _, outline := range feeds {
+			outline.Type, outline.Title = "rss", outline.Text
+			merged.Outlines = append(merged.Outlines, outline)
+		}
+		doc.Body = append(doc.Body, merged)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,362 @@
+// Package site renders the release feeds of tracked repos as a static
+// dashboard: HTML pages of the latest releases across all repos, of each
+// category and of each repo's history, along with the merged feeds in every
+// format and an OPML list of the feeds to subscribe to.
+package site
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/filter"
+	"github.com/toozej/ghreleases2rss/internal/github"
+	"github.com/toozej/ghreleases2rss/internal/server"
+)
+
+// DefaultTitle is the site title for an empty Options.Title.
+const DefaultTitle = "Dependency releases"
+
+// DefaultLimit is the number of releases on the index for a zero
+// Options.Limit.
+const DefaultLimit = 50
+
+// Files written at the root of the site besides index.html. The releases
+// feed is written once per format.
+const (
+	releasesFeed = "releases"
+	opmlFile     = "subscriptions.opml"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+var funcs = template.FuncMap{
+	"date":    func(t time.Time) string { return t.UTC().Format("2006-01-02") },
+	"rfc3339": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}
+
+var (
+	releasesPage = template.Must(template.New("").Funcs(funcs).ParseFS(templates, "templates/layout.html", "templates/releases.html"))
+	repoPage     = template.Must(template.New("").Funcs(funcs).ParseFS(templates, "templates/layout.html", "templates/repo.html"))
+)
+
+// formatNames label the feed formats in page footers.
+var formatNames = map[feed.Format]string{
+	feed.FormatAtom: "Atom",
+	feed.FormatJSON: "JSON Feed",
+	feed.FormatRSS:  "RSS",
+	feed.FormatICS:  "iCalendar",
+}
+
+// pathSegment matches owner and repo names that are safe to use as file
+// names.
+var pathSegment = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Options configure Build.
+type Options struct {
+	// Title names the site, DefaultTitle when empty.
+	Title string
+	// Limit caps the number of releases on the index and in the feed of all
+	// releases, DefaultLimit when zero.
+	Limit int
+	// BaseURL is the URL the site is published at. When set, feeds link to
+	// themselves and the OPML file lists the merged feeds of the site.
+	BaseURL string
+	// ProxyURL is the URL of a filtering feed proxy. When set, the OPML file
+	// points repos with filter options at it, as subscribing commands do.
+	ProxyURL string
+	// Now is the generation time shown on every page, the current time when
+	// zero.
+	Now time.Time
+}
+
+// page is the data of an HTML page.
+type page struct {
+	SiteTitle  string
+	Title      string
+	Root       string
+	OPML       string
+	Generated  time.Time
+	Categories []link
+	Feeds      []feedLink
+	Repos      []link
+	RepoURL    string
+	Releases   []release
+}
+
+type link struct {
+	Name string
+	Href string
+}
+
+type feedLink struct {
+	Name string
+	Type string
+	Href string
+}
+
+type release struct {
+	Repo       string
+	RepoHref   string
+	Title      string
+	Link       string
+	Updated    time.Time
+	Prerelease bool
+	// Notes are the release notes from the feed, which GitHub sanitizes,
+	// or rendered by the enricher without raw HTML.
+	Notes template.HTML
+}
+
+// Build writes the site to dir from the releases s last fetched for its
+// categories:
+//
+//	index.html                          latest releases across all repos
+//	releases.{atom,json,rss,ics}        feed of the latest releases
+//	categories/{slug}.html              releases of each category
+//	feeds/{slug}.{atom,json,rss,ics}    merged feed of each category
+//	repos/{owner}/{repo}.html           release history of each repo
+//	subscriptions.opml                  feeds of the repos by category
+//
+// Links between pages are relative, so the site works from any path. A repo
+// in several categories gets one history page, using the filter options of
+// its first listing.
+func Build(dir string, s *server.Server, opts Options) error {
+	if opts.Title == "" {
+		opts.Title = DefaultTitle
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultLimit
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	b := &builder{dir: dir, server: s, opts: opts, repoPages: make(map[string]string)}
+
+	categories := s.Categories()
+	var repos []server.Repo
+	for _, category := range categories {
+		b.nav = append(b.nav, link{Name: category.Name, Href: categoryPath(category.Slug)})
+		for _, repo := range category.Repos {
+			if _, ok := b.repoPages[repo.Name]; ok {
+				continue
+			}
+			repos = append(repos, repo)
+			if p, err := repoPath(repo.Name); err != nil {
+				log.Warnf("Leaving out the history page of %s: %v", repo.Name, err)
+				b.repoPages[repo.Name] = ""
+			} else {
+				b.repoPages[repo.Name] = p
+			}
+		}
+	}
+
+	for _, repo := range repos {
+		if err := b.writeRepo(repo); err != nil {
+			return err
+		}
+	}
+
+	var all []server.Repo
+	for _, category := range categories {
+		merged, _, err := s.Feed(category.Slug)
+		if err != nil {
+			return err
+		}
+		feedPath := path.Join("feeds", category.Slug)
+		if err := b.writeFeeds(feedPath, merged); err != nil {
+			return err
+		}
+		if err := b.writeReleases(categoryPath(category.Slug), category.Name, category.Repos, feedPath, merged.Entries); err != nil {
+			return err
+		}
+		all = append(all, category.Repos...)
+	}
+
+	latest, err := s.MergedFeed("urn:ghreleases2rss:site", opts.Title, all)
+	if err != nil {
+		return err
+	}
+	if len(latest.Entries) > opts.Limit {
+		latest.Entries = latest.Entries[:opts.Limit]
+	}
+	if err := b.writeFeeds(releasesFeed, latest); err != nil {
+		return err
+	}
+	if err := b.writeReleases("index.html", "Latest releases", nil, releasesFeed, latest.Entries); err != nil {
+		return err
+	}
+
+	var opml bytes.Buffer
+	if err := writeOPML(&opml, categories, opts); err != nil {
+		return err
+	}
+	if err := b.writeFile(opmlFile, opml.Bytes()); err != nil {
+		return err
+	}
+	log.Infof("Built site in %s with %d categories and %d repos", dir, len(categories), len(repos))
+	return nil
+}
+
+// builder writes the files of a site.
+type builder struct {
+	dir    string
+	server *server.Server
+	opts   Options
+	nav    []link
+	// repoPages maps repo names to the path of their history page, or ""
+	// when they have none.
+	repoPages map[string]string
+}
+
+func categoryPath(slug string) string {
+	return path.Join("categories", slug+".html")
+}
+
+// repoPath returns the path of the history page of repo.
+func repoPath(repo string) (string, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	for _, segment := range []string{owner, name} {
+		if !ok || !pathSegment.MatchString(segment) || strings.Trim(segment, ".") == "" {
+			return "", fmt.Errorf("unsupported repo name %q", repo)
+		}
+	}
+	return path.Join("repos", owner, name+".html"), nil
+}
+
+func (b *builder) writeRepo(repo server.Repo) error {
+	p := b.repoPages[repo.Name]
+	if p == "" {
+		return nil
+	}
+	entries, err := b.server.Releases(repo)
+	if err != nil {
+		return err
+	}
+
+	data := b.page(p, repo.Name, "")
+	data.RepoURL = "https://github.com/" + repo.Name
+	for _, entry := range entries {
+		data.Releases = append(data.Releases, b.release(entry, repo.Name, p))
+	}
+	return b.render(p, repoPage, data)
+}
+
+// writeReleases writes the page listing entries, merged from repos, whose
+// feed is written at feedPath in every format.
+func (b *builder) writeReleases(p, title string, repos []server.Repo, feedPath string, entries []feed.Entry) error {
+	data := b.page(p, title, feedPath)
+	for _, repo := range repos {
+		href := "https://github.com/" + repo.Name
+		if repoPage := b.repoPages[repo.Name]; repoPage != "" {
+			href = data.Root + repoPage
+		}
+		data.Repos = append(data.Repos, link{Name: repo.Name, Href: href})
+	}
+	for _, entry := range entries {
+		// Merged feeds prefix titles with the repo, which the page shows in
+		// its own column
+		repo, title, _ := strings.Cut(entry.Title, ": ")
+		entry.Title = title
+		data.Releases = append(data.Releases, b.release(entry, repo, p))
+	}
+	return b.render(p, releasesPage, data)
+}
+
+func (b *builder) page(p, title, feedPath string) page {
+	root := strings.Repeat("../", strings.Count(p, "/"))
+	data := page{
+		SiteTitle:  b.opts.Title,
+		Title:      title,
+		Root:       root,
+		OPML:       opmlFile,
+		Generated:  b.opts.Now,
+		Categories: b.nav,
+	}
+	if feedPath != "" {
+		for _, format := range feed.Formats {
+			data.Feeds = append(data.Feeds, feedLink{
+				Name: formatNames[format],
+				Type: strings.Split(format.ContentType(), ";")[0],
+				Href: root + feedPath + format.Extension(),
+			})
+		}
+	}
+	return data
+}
+
+// release returns the row of entry, published by repo, on the page at p.
+func (b *builder) release(entry feed.Entry, repo, p string) release {
+	href := "https://github.com/" + repo
+	if repoPage := b.repoPages[repo]; repoPage != "" {
+		href = strings.Repeat("../", strings.Count(p, "/")) + repoPage
+	}
+	version, _ := feed.ParseVersion(entry.Tag())
+	return release{
+		Repo:       repo,
+		RepoHref:   href,
+		Title:      entry.Title,
+		Link:       entry.Link,
+		Updated:    entry.Updated,
+		Prerelease: slices.Contains(entry.Categories, feed.CategoryPrerelease) || filter.IsPrerelease(entry.Tag(), version),
+		Notes:      template.HTML(entry.Content), // #nosec G203 -- notes are sanitized by GitHub or rendered without raw HTML
+	}
+}
+
+func (b *builder) render(p string, t *template.Template, data page) error {
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return fmt.Errorf("error rendering %s: %w", p, err)
+	}
+	return b.writeFile(p, buf.Bytes())
+}
+
+// writeFeeds writes f at p in every format.
+func (b *builder) writeFeeds(p string, f *feed.Feed) error {
+	for _, format := range feed.Formats {
+		var self string
+		if b.opts.BaseURL != "" {
+			self = strings.TrimSuffix(b.opts.BaseURL, "/") + "/" + p + format.Extension()
+		}
+		var buf bytes.Buffer
+		if err := feed.Write(&buf, f, format, self); err != nil {
+			return fmt.Errorf("error writing %s: %w", p+format.Extension(), err)
+		}
+		if err := b.writeFile(p+format.Extension(), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) writeFile(p string, data []byte) error {
+	target := filepath.Join(b.dir, filepath.FromSlash(p))
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return err
+	}
+	// #nosec G306 -- the site is meant to be published
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", target, err)
+	}
+	return nil
+}
+
+// feedURL returns the feed a reader subscribes to for repo.
+func feedURL(repo server.Repo, proxyURL string) string {
+	if proxyURL != "" && repo.Filter != nil {
+		return server.ProxyURL(proxyURL, repo.Name, repo.Filter)
+	}
+	feedURL, _ := github.GetReleaseFeedURL(repo.Name)
+	return feedURL
+}
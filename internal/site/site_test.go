@@ -0,0 +1,173 @@
+package site
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/filter"
+	"github.com/toozej/ghreleases2rss/internal/server"
+)
+
+const upstreamFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>tag:github.com,2008:https://github.com/%[1]s/releases</id>
+  <title>Release notes from %[1]s</title>
+  %[2]s
+</feed>`
+
+const upstreamEntry = `<entry>
+    <id>tag:github.com,2008:Repository/%[1]s/%[3]s</id>
+    <updated>%[2]s</updated>
+    <link rel="alternate" href="https://github.com/%[1]s/releases/tag/%[3]s"/>
+    <title>%[3]s</title>
+    <content type="html">&lt;p&gt;Notes for %[3]s&lt;/p&gt;</content>
+  </entry>`
+
+func newTestServer(t *testing.T) *server.Server {
+	t.Helper()
+	entries := map[string][]string{
+		"owner/a": {fmt.Sprintf(upstreamEntry, "owner/a", "2026-03-01T10:00:00Z", "v2.0.0"), fmt.Sprintf(upstreamEntry, "owner/a", "2026-01-01T10:00:00Z", "v1.0.0")},
+		"owner/b": {fmt.Sprintf(upstreamEntry, "owner/b", "2026-02-01T10:00:00Z", "v0.9.0-rc.1")},
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/releases.atom")
+		list, ok := entries[repo]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, upstreamFeed, repo, strings.Join(list, "\n"))
+	}))
+	t.Cleanup(upstream.Close)
+
+	stable, err := filter.Parse(map[string][]string{filter.KeyPrerelease: {"false"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server.Server{
+		BaseURL: upstream.URL,
+		Load: func() ([]server.Category, error) {
+			return []server.Category{
+				{Slug: "dev-tools", Name: "Dev Tools", Repos: []server.Repo{{Name: "owner/a"}, {Name: "owner/b"}}},
+				{Slug: "stable", Name: "Stable", Repos: []server.Repo{{Name: "owner/b", Filter: stable}}},
+			}, nil
+		},
+	}
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	return s
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Limit: 2, BaseURL: "https://deps.example.com/", ProxyURL: "https://feeds.example.com", Now: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)}
+	if err := Build(dir, newTestServer(t), opts); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Build() did not write %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	for _, want := range []string{
+		`<title>Latest releases · Dependency releases</title>`,
+		`<a href="repos/owner/a.html">owner/a</a>`,
+		`<a href="https://github.com/owner/a/releases/tag/v2.0.0">v2.0.0</a>`,
+		`<a href="categories/dev-tools.html">Dev Tools</a>`,
+		`<link rel="alternate" type="application/feed&#43;json" title="JSON Feed" href="releases.json">`,
+		`<span class="prerelease">prerelease</span>`,
+		`Generated <time datetime="2026-03-02T00:00:00Z">2026-03-02</time>`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html is missing %s:\n%s", want, index)
+		}
+	}
+	if strings.Contains(index, "v1.0.0") {
+		t.Errorf("index.html should be limited to the %d latest releases:\n%s", opts.Limit, index)
+	}
+
+	stable := read("categories/stable.html")
+	if !strings.Contains(stable, `<a href="../repos/owner/b.html">owner/b</a>`) || strings.Contains(stable, "v0.9.0-rc.1") {
+		t.Errorf("categories/stable.html should link owner/b without its prerelease:\n%s", stable)
+	}
+
+	history := read("repos/owner/a.html")
+	if !strings.Contains(history, `<a href="../../index.html">`) || !strings.Contains(history, "<p>Notes for v1.0.0</p>") {
+		t.Errorf("repos/owner/a.html = \n%s", history)
+	}
+
+	merged, err := feed.Parse([]byte(read("feeds/dev-tools.atom")))
+	if err != nil || len(merged.Entries) != 3 {
+		t.Errorf("feeds/dev-tools.atom = %+v, %v, want 3 entries", merged, err)
+	}
+	for _, name := range []string{"releases.atom", "releases.json", "releases.rss", "feeds/stable.json", "feeds/stable.rss"} {
+		read(name)
+	}
+	if self := read("releases.json"); !strings.Contains(self, `"feed_url": "https://deps.example.com/releases.json"`) {
+		t.Errorf("releases.json is missing its feed URL:\n%s", self)
+	}
+
+	var opml struct {
+		Body []struct {
+			Text     string `xml:"text,attr"`
+			Outlines []struct {
+				Text   string `xml:"text,attr"`
+				XMLURL string `xml:"xmlUrl,attr"`
+			} `xml:"outline"`
+		} `xml:"body>outline"`
+	}
+	if err := xml.Unmarshal([]byte(read("subscriptions.opml")), &opml); err != nil {
+		t.Fatalf("subscriptions.opml is invalid: %v", err)
+	}
+	if len(opml.Body) != 3 || opml.Body[0].Text != "Dev Tools" || opml.Body[2].Text != "Dependency releases" {
+		t.Fatalf("subscriptions.opml outlines = %+v", opml.Body)
+	}
+	if got := opml.Body[0].Outlines[0].XMLURL; got != "https://github.com/owner/a/releases.atom" {
+		t.Errorf("owner/a feed = %s", got)
+	}
+	if got := opml.Body[1].Outlines[0].XMLURL; got != "https://feeds.example.com/proxy/owner/b/releases.atom?prerelease=false" {
+		t.Errorf("filtered owner/b feed = %s, want the proxy", got)
+	}
+	if got := opml.Body[2].Outlines[1].XMLURL; got != "https://deps.example.com/feeds/dev-tools.atom" {
+		t.Errorf("merged Dev Tools feed = %s", got)
+	}
+}
+
+func TestRepoPath(t *testing.T) {
+	tests := []struct {
+		repo    string
+		want    string
+		wantErr bool
+	}{
+		{repo: "owner/repo", want: "repos/owner/repo.html"},
+		{repo: "my-org/repo.go", want: "repos/my-org/repo.go.html"},
+		{repo: "owner/..", wantErr: true},
+		{repo: "../etc", wantErr: true},
+		{repo: "owner", wantErr: true},
+		{repo: "owner/a/b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			got, err := repoPath(tt.repo)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("repoPath(%q) = %q, %v, want %q, error %v", tt.repo, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
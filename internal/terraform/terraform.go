@@ -0,0 +1,190 @@
+// Package terraform reads the providers pinned in .terraform.lock.hcl files
+// and maps them to their source repositories through the provider registry.
+package terraform
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+// DefaultHostname is the registry of providers whose address has no hostname.
+const DefaultHostname = "registry.terraform.io"
+
+// Provider is a provider pinned in a dependency lock file.
+type Provider struct {
+	// Hostname, Namespace and Type make up the provider address, as in
+	// registry.terraform.io/hashicorp/aws.
+	Hostname  string
+	Namespace string
+	Type      string
+	Version   string
+	Line      int
+}
+
+// Address returns the fully qualified provider address.
+func (p Provider) Address() string {
+	return p.Hostname + "/" + p.Namespace + "/" + p.Type
+}
+
+// LockFileName is the name of the dependency lock file written by terraform init.
+const LockFileName = ".terraform.lock.hcl"
+
+// Find returns the dependency lock files below dir, skipping .terraform
+// working directories, which hold copies of downloaded modules.
+func Find(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".git" || d.Name() == ".terraform") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == LockFileName {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+var (
+	providerBlock = regexp.MustCompile(`^\s*provider\s+"([^"]+)"\s*\{`)
+	versionAttr   = regexp.MustCompile(`^\s*version\s*=\s*"([^"]*)"`)
+)
+
+// Parse returns the providers of a .terraform.lock.hcl file. The lock file
+// format is generated by terraform and tofu, so a line-based reader is enough
+// to pick out provider blocks and their versions.
+func Parse(data []byte) ([]Provider, error) {
+	var providers []Provider
+	var current *Provider
+	depth := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		text := scanner.Text()
+		if depth == 0 {
+			if match := providerBlock.FindStringSubmatch(text); match != nil {
+				provider, err := ParseAddress(match[1])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+				}
+				provider.Line = lineNumber
+				providers = append(providers, provider)
+				current = &providers[len(providers)-1]
+			}
+		} else if depth == 1 && current != nil {
+			if match := versionAttr.FindStringSubmatch(text); match != nil {
+				current.Version = match[1]
+			}
+		}
+		depth += strings.Count(text, "{") - strings.Count(text, "}")
+		if depth <= 0 {
+			depth = 0
+			current = nil
+		}
+	}
+	return providers, scanner.Err()
+}
+
+// ParseAddress parses a [hostname/]namespace/type provider address.
+func ParseAddress(address string) (Provider, error) {
+	parts := strings.Split(address, "/")
+	switch {
+	case len(parts) == 2:
+		parts = append([]string{DefaultHostname}, parts...)
+	case len(parts) != 3:
+		return Provider{}, fmt.Errorf("invalid provider address %q", address)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return Provider{}, fmt.Errorf("invalid provider address %q", address)
+		}
+	}
+	return Provider{Hostname: strings.ToLower(parts[0]), Namespace: parts[1], Type: parts[2]}, nil
+}
+
+// Resolver maps providers to GitHub repositories using the source field of
+// the registry's /v1/providers/{namespace}/{type} metadata.
+type Resolver struct {
+	// Endpoint is the registry base URL used for every provider. When empty,
+	// each provider is looked up at https:// followed by its own hostname.
+	Endpoint string
+	// Client fetches provider metadata. http.DefaultClient is used when nil.
+	Client *http.Client
+
+	cache map[string]string
+}
+
+// Resolve returns the owner/repo GitHub repository of provider.
+func (r *Resolver) Resolve(provider Provider) (string, error) {
+	if r.cache == nil {
+		r.cache = make(map[string]string)
+	}
+	address := provider.Address()
+	repo, ok := r.cache[address]
+	if !ok {
+		source, err := r.lookup(provider)
+		if err != nil {
+			return "", err
+		}
+		repo, _ = github.RepoFromURL(source)
+		r.cache[address] = repo
+		if repo != "" {
+			log.Debugf("Resolved provider %s to %s", address, repo)
+		}
+	}
+	if repo == "" {
+		return "", fmt.Errorf("provider %s has no GitHub source in the registry", address)
+	}
+	return repo, nil
+}
+
+// lookup returns the source URL the registry lists for provider.
+func (r *Resolver) lookup(provider Provider) (string, error) {
+	endpoint := "https://" + provider.Hostname
+	if r.Endpoint != "" {
+		endpoint = strings.TrimSuffix(r.Endpoint, "/")
+	}
+	metadataURL := fmt.Sprintf("%s/v1/providers/%s/%s", endpoint, provider.Namespace, provider.Type)
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(metadataURL) // #nosec G107 -- provider hostnames come from the lock file being imported
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch provider %s from registry, status code: %d", provider.Address(), resp.StatusCode)
+	}
+
+	var metadata struct {
+		Source string `json:"source"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&metadata); err != nil {
+		return "", err
+	}
+	return metadata.Source, nil
+}
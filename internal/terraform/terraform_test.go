@@ -0,0 +1,106 @@
+package terraform
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testLockFile = `# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+
+provider "registry.terraform.io/integrations/github" {
+  version = "6.0.0"
+  hashes = [
+    "h1:def=",
+  ]
+}
+
+provider "registry.terraform.io/example/internal" {
+  version = "1.0.0"
+}
+`
+
+func TestParse(t *testing.T) {
+	got, err := Parse([]byte(testLockFile))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Provider{
+		{Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws", Version: "5.31.0", Line: 4},
+		{Hostname: "registry.terraform.io", Namespace: "integrations", Type: "github", Version: "6.0.0", Line: 12},
+		{Hostname: "registry.terraform.io", Namespace: "example", Type: "internal", Version: "1.0.0", Line: 19},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Parse() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Parse()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := Parse([]byte(`provider "a/b/c/d" {}`)); err == nil {
+		t.Error("Parse() should reject invalid provider addresses")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	lookups := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		switch r.URL.Path {
+		case "/v1/providers/hashicorp/aws":
+			fmt.Fprint(w, `{"namespace":"hashicorp","name":"aws","source":"https://github.com/hashicorp/terraform-provider-aws"}`)
+		case "/v1/providers/example/internal":
+			fmt.Fprint(w, `{"namespace":"example","name":"internal","source":"https://gitlab.com/example/terraform-provider-internal"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	resolver := &Resolver{Endpoint: mockServer.URL + "/"}
+
+	tests := []struct {
+		address string
+		want    string
+		wantErr bool
+	}{
+		{address: "registry.terraform.io/hashicorp/aws", want: "hashicorp/terraform-provider-aws"},
+		{address: "hashicorp/aws", want: "hashicorp/terraform-provider-aws"},
+		{address: "registry.terraform.io/example/internal", wantErr: true},
+		{address: "registry.terraform.io/example/missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			provider, err := ParseAddress(tt.address)
+			if err != nil {
+				t.Fatalf("ParseAddress() error = %v", err)
+			}
+			got, err := resolver.Resolve(provider)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the two hashicorp/aws addresses share one lookup
+	if lookups != 3 {
+		t.Errorf("resolver made %d registry requests, want 3", lookups)
+	}
+}
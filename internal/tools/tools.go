@@ -0,0 +1,320 @@
+// Package tools reads the CLIs pinned in asdf, mise, aqua and Homebrew
+// manifests and maps them to GitHub repositories through a tool registry.
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/toozej/ghreleases2rss/internal/github"
+)
+
+// Tool managers whose manifests are supported.
+const (
+	Asdf = "asdf"
+	Mise = "mise"
+	Aqua = "aqua"
+	Brew = "brew"
+)
+
+// Tool is a CLI pinned in a tool manifest.
+type Tool struct {
+	// Manager is the tool manager the manifest belongs to.
+	Manager string
+	// Name is the plugin, package or formula name the manager uses.
+	Name    string
+	Version string
+	// Repo is the owner/repo named by the manifest itself, such as a mise
+	// github: backend or an aqua package, or "" when the registry decides.
+	Repo string
+	Line int
+}
+
+// Find returns the .tool-versions, mise.toml, aqua.yaml and Brewfile
+// manifests below dir.
+func Find(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if IsManifest(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// IsManifest reports whether a file name is a supported tool manifest.
+func IsManifest(name string) bool {
+	return manager(name) != ""
+}
+
+// manager returns the tool manager of a manifest file name, or "".
+func manager(name string) string {
+	switch name {
+	case ".tool-versions":
+		return Asdf
+	case "mise.toml", ".mise.toml", "mise.local.toml", ".mise.local.toml":
+		return Mise
+	case "aqua.yaml", "aqua.yml", ".aqua.yaml", ".aqua.yml":
+		return Aqua
+	case "Brewfile":
+		return Brew
+	}
+	return ""
+}
+
+// Parse returns the tools pinned in a manifest, chosen by its name.
+func Parse(path string, data []byte) ([]Tool, error) {
+	switch manager(filepath.Base(path)) {
+	case Asdf:
+		return ParseToolVersions(data), nil
+	case Mise:
+		return ParseMise(data)
+	case Aqua:
+		return ParseAqua(data)
+	case Brew:
+		return ParseBrewfile(data), nil
+	}
+	return nil, fmt.Errorf("unsupported tool manifest %s", filepath.Base(path))
+}
+
+// ParseToolVersions returns the tools of an asdf .tool-versions file.
+func ParseToolVersions(data []byte) []Tool {
+	var tools []Tool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		tool := Tool{Manager: Asdf, Name: fields[0], Line: lineNumber}
+		if len(fields) > 1 {
+			tool.Version = fields[1]
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// ParseMise returns the tools of a mise.toml [tools] table. Tools installed
+// through the github:, ubi: and aqua: backends name their repository directly.
+func ParseMise(data []byte) ([]Tool, error) {
+	var config struct {
+		Tools map[string]any `toml:"tools"`
+	}
+	if err := toml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(config.Tools))
+	for name := range config.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := strings.Split(string(data), "\n")
+	var tools []Tool
+	for _, key := range names {
+		tool := Tool{Manager: Mise, Name: strings.TrimPrefix(key, "core:"), Version: miseVersion(config.Tools[key])}
+		if backend, name, ok := strings.Cut(tool.Name, ":"); ok {
+			switch backend {
+			case "github", "ubi":
+				// ubi:owner/repo[exe=name] may carry options in brackets
+				name, _, _ = strings.Cut(name, "[")
+				if repo, ok := github.RepoFromURL("https://github.com/" + name); ok {
+					tool.Repo = repo
+				}
+			case "aqua":
+				// aqua package names are looked up as aqua entries
+				tool = aquaTool(name, tool.Version)
+			case "go":
+				tool.Repo, _ = github.RepoFromURL("https://" + name)
+			}
+		}
+		tool.Line = keyLine(lines, key)
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// miseVersion returns the version of a mise tool entry, which is a string,
+// a list of versions or a table with a version key.
+func miseVersion(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			return fmt.Sprint(v[0])
+		}
+	case map[string]any:
+		if version, ok := v["version"].(string); ok {
+			return version
+		}
+	}
+	return ""
+}
+
+// keyLine returns the line declaring key in a TOML file, or 0.
+func keyLine(lines []string, key string) int {
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		for _, candidate := range []string{key, `"` + key + `"`, "'" + key + "'"} {
+			if rest, ok := strings.CutPrefix(line, candidate); ok && strings.HasPrefix(strings.TrimSpace(rest), "=") {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// ParseAqua returns the packages of an aqua.yaml file. Package names are
+// registry names, usually owner/repo, with an optional @version suffix.
+func ParseAqua(data []byte) ([]Tool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	var tools []Tool
+	packages := mappingValue(doc.Content[0], "packages")
+	if packages == nil || packages.Kind != yaml.SequenceNode {
+		return nil, nil
+	}
+	for _, item := range packages.Content {
+		name := mappingValue(item, "name")
+		if name == nil || name.Value == "" {
+			// import entries pull in other files and have no name
+			continue
+		}
+		version := ""
+		if v := mappingValue(item, "version"); v != nil {
+			version = v.Value
+		}
+		packageName := name.Value
+		if at := strings.LastIndex(packageName, "@"); at > 0 {
+			packageName, version = packageName[:at], packageName[at+1:]
+		}
+		tool := aquaTool(packageName, version)
+		tool.Line = name.Line
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// aquaTool returns an aqua package, whose two-part owner/repo names map to
+// their repository unless the registry says otherwise.
+func aquaTool(name, version string) Tool {
+	tool := Tool{Manager: Aqua, Name: name, Version: version}
+	if parts := strings.Split(name, "/"); len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+		tool.Repo = name
+	}
+	return tool
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// ParseBrewfile returns the formulae and casks of a Homebrew Brewfile. Tap
+// formulae such as hashicorp/tap/terraform are named by their last segment.
+func ParseBrewfile(data []byte) []Tool {
+	var tools []Tool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		text := strings.TrimSpace(scanner.Text())
+		keyword, rest, ok := strings.Cut(text, " ")
+		if !ok || (keyword != "brew" && keyword != "cask") {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if len(rest) < 2 || (rest[0] != '"' && rest[0] != '\'') {
+			continue
+		}
+		end := strings.IndexByte(rest[1:], rest[0])
+		if end < 0 {
+			continue
+		}
+		name := rest[1 : end+1]
+		tools = append(tools, Tool{Manager: Brew, Name: name[strings.LastIndex(name, "/")+1:], Line: lineNumber})
+	}
+	return tools
+}
+
+//go:embed registry.yaml
+var builtinRegistry []byte
+
+// Registry maps tool names to GitHub repositories.
+type Registry struct {
+	tools map[string]string
+}
+
+// NewRegistry returns the built-in registry, extended by each override file
+// in order. Entries in an override replace built-in ones, and an empty
+// repository removes a mapping.
+func NewRegistry(overrides ...[]byte) (*Registry, error) {
+	registry := &Registry{tools: make(map[string]string)}
+	for _, data := range append([][]byte{builtinRegistry}, overrides...) {
+		var file struct {
+			Tools map[string]string `yaml:"tools"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("invalid tool registry: %w", err)
+		}
+		for name, repo := range file.Tools {
+			if repo != "" {
+				if _, ok := github.RepoFromURL("https://github.com/" + repo); !ok {
+					return nil, fmt.Errorf("invalid tool registry: %s maps to %q, want owner/repo", name, repo)
+				}
+			}
+			registry.tools[strings.ToLower(name)] = repo
+		}
+	}
+	return registry, nil
+}
+
+// Resolve returns the GitHub repository of tool. A manager-qualified registry
+// entry wins over a plain one, which wins over the repository the manifest
+// names itself.
+func (r *Registry) Resolve(tool Tool) (string, bool) {
+	for _, key := range []string{tool.Manager + ":" + tool.Name, tool.Name} {
+		if repo, ok := r.tools[strings.ToLower(key)]; ok {
+			return repo, repo != ""
+		}
+	}
+	return tool.Repo, tool.Repo != ""
+}
@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"testing"
+)
+
+const testToolVersions = `# runtimes
+nodejs 20.11.0
+golang 1.26.0 1.25.0
+
+internal-cli 0.1.0 # no public repo
+`
+
+const testMise = `[env]
+FOO = "bar"
+
+[tools]
+terraform = "1.9"
+"github:cli/cli" = "latest"
+"ubi:BurntSushi/ripgrep[exe=rg]" = "14"
+"aqua:kubernetes/kubectl" = ["1.30"]
+python = { version = "3.12" }
+"npm:prettier" = "3"
+`
+
+const testAqua = `registries:
+  - type: standard
+    ref: v4.0.0
+packages:
+  - name: suzuki-shunsuke/tfcmt@v4.9.0
+  - name: kubernetes/kubectl
+    version: v1.30.0
+  - import: aqua/*.yaml
+  - name: golang.org/x/tools/gopls@v0.16.0
+`
+
+const testBrewfile = `tap "hashicorp/tap"
+brew "jq"
+brew "hashicorp/tap/terraform", restart_service: false
+cask "firefox"
+mas "Xcode", id: 497799835
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want []Tool
+	}{
+		{
+			path: ".tool-versions",
+			data: testToolVersions,
+			want: []Tool{
+				{Manager: Asdf, Name: "nodejs", Version: "20.11.0", Line: 2},
+				{Manager: Asdf, Name: "golang", Version: "1.26.0", Line: 3},
+				{Manager: Asdf, Name: "internal-cli", Version: "0.1.0", Line: 5},
+			},
+		},
+		{
+			path: "mise.toml",
+			data: testMise,
+			want: []Tool{
+				{Manager: Aqua, Name: "kubernetes/kubectl", Version: "1.30", Repo: "kubernetes/kubectl", Line: 8},
+				{Manager: Mise, Name: "github:cli/cli", Version: "latest", Repo: "cli/cli", Line: 6},
+				{Manager: Mise, Name: "npm:prettier", Version: "3", Line: 10},
+				{Manager: Mise, Name: "python", Version: "3.12", Line: 9},
+				{Manager: Mise, Name: "terraform", Version: "1.9", Line: 5},
+				{Manager: Mise, Name: "ubi:BurntSushi/ripgrep[exe=rg]", Version: "14", Repo: "BurntSushi/ripgrep", Line: 7},
+			},
+		},
+		{
+			path: "aqua.yaml",
+			data: testAqua,
+			want: []Tool{
+				{Manager: Aqua, Name: "suzuki-shunsuke/tfcmt", Version: "v4.9.0", Repo: "suzuki-shunsuke/tfcmt", Line: 5},
+				{Manager: Aqua, Name: "kubernetes/kubectl", Version: "v1.30.0", Repo: "kubernetes/kubectl", Line: 6},
+				{Manager: Aqua, Name: "golang.org/x/tools/gopls", Version: "v0.16.0", Line: 9},
+			},
+		},
+		{
+			path: "Brewfile",
+			data: testBrewfile,
+			want: []Tool{
+				{Manager: Brew, Name: "jq", Line: 2},
+				{Manager: Brew, Name: "terraform", Line: 3},
+				{Manager: Brew, Name: "firefox", Line: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := Parse(tt.path, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Parse()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry, err := NewRegistry([]byte(`tools:
+  internal-cli: example/internal-cli
+  brew:firefox: mozilla/gecko-dev
+  jq: ""
+`))
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	tests := []struct {
+		tool   Tool
+		want   string
+		wantOK bool
+	}{
+		{tool: Tool{Manager: Asdf, Name: "nodejs"}, want: "nodejs/node", wantOK: true},
+		{tool: Tool{Manager: Brew, Name: "Terraform"}, want: "hashicorp/terraform", wantOK: true},
+		{tool: Tool{Manager: Asdf, Name: "internal-cli"}, want: "example/internal-cli", wantOK: true},
+		{tool: Tool{Manager: Brew, Name: "firefox"}, want: "mozilla/gecko-dev", wantOK: true},
+		{tool: Tool{Manager: Asdf, Name: "firefox"}},
+		{tool: Tool{Manager: Brew, Name: "jq"}},
+		{tool: Tool{Manager: Aqua, Name: "kubernetes/kubectl", Repo: "kubernetes/kubectl"}, want: "kubernetes/kubernetes", wantOK: true},
+		{tool: Tool{Manager: Aqua, Name: "suzuki-shunsuke/tfcmt", Repo: "suzuki-shunsuke/tfcmt"}, want: "suzuki-shunsuke/tfcmt", wantOK: true},
+		{tool: Tool{Manager: Mise, Name: "npm:prettier"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool.Manager+"/"+tt.tool.Name, func(t *testing.T) {
+			got, ok := registry.Resolve(tt.tool)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Resolve() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, err := NewRegistry([]byte("tools:\n  bad: not-a-repo\n")); err == nil {
+		t.Error("NewRegistry() should reject mappings that are not owner/repo")
+	}
+}
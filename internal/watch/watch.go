@@ -0,0 +1,236 @@
+// Package watch polls the release feeds of tracked repos, remembers the
+// last release seen per repo in a state file and sends releases published
+// since to notification sinks.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/notify"
+	"github.com/toozej/ghreleases2rss/internal/server"
+)
+
+// DefaultInterval is the time between polls for a zero Watcher.Interval.
+const DefaultInterval = 15 * time.Minute
+
+// Seen is the newest release seen of a repo.
+type Seen struct {
+	ID      string    `json:"id"`
+	Updated time.Time `json:"updated"`
+}
+
+// State records the newest release seen of each watched repo.
+type State struct {
+	Repos map[string]Seen `json:"repos"`
+}
+
+// LoadState reads the state file at path. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	state := &State{Repos: make(map[string]Seen)}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	if state.Repos == nil {
+		state.Repos = make(map[string]Seen)
+	}
+	return state, nil
+}
+
+// Save writes the state to path through a temporary file, so an interrupted
+// write never leaves a truncated state file.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// NewEntries returns the entries published after seen, oldest first, and
+// what is seen after them. entries are newest first, as in release feeds.
+// When the seen entry is still in the feed, every entry above it is new;
+// otherwise entries updated after it are. Nothing is new for a repo not seen
+// before, so watching a repo starts from its current release instead of
+// sending its whole history.
+func NewEntries(entries []feed.Entry, seen Seen) ([]feed.Entry, Seen) {
+	if len(entries) == 0 {
+		return nil, seen
+	}
+	newest := Seen{ID: entryID(entries[0]), Updated: entries[0].Updated}
+	if seen.ID == "" {
+		return nil, newest
+	}
+
+	var fresh []feed.Entry
+	found := false
+	for _, entry := range entries {
+		if entryID(entry) == seen.ID {
+			found = true
+			break
+		}
+		fresh = append(fresh, entry)
+	}
+	if !found {
+		fresh = fresh[:0]
+		for _, entry := range entries {
+			if entry.Updated.After(seen.Updated) {
+				fresh = append(fresh, entry)
+			}
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, seen
+	}
+	for i, j := 0, len(fresh)-1; i < j; i, j = i+1, j-1 {
+		fresh[i], fresh[j] = fresh[j], fresh[i]
+	}
+	return fresh, newest
+}
+
+func entryID(entry feed.Entry) string {
+	if entry.ID == "" {
+		return entry.Link
+	}
+	return entry.ID
+}
+
+// Watcher polls the release feeds of the repos a Server tracks and sends
+// new releases to Sinks.
+type Watcher struct {
+	// Server fetches the release feeds of the repos in its categories.
+	Server *server.Server
+	// StatePath is the file the newest release seen per repo is kept in.
+	StatePath string
+	// Sinks receive every new release.
+	Sinks []notify.Sink
+	// Retry configures how often a failed delivery is attempted.
+	Retry notify.Retry
+	// Interval is the time between polls, DefaultInterval when zero.
+	Interval time.Duration
+}
+
+// Run polls immediately and then every Interval until ctx is cancelled.
+// Failed polls are logged and retried on the next tick.
+func (w *Watcher) Run(ctx context.Context) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Check(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("Error checking for new releases: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check fetches the release feeds once and sends the releases published
+// since the last check to every sink, oldest first. A repo is only marked
+// as seen once every sink received its releases, so failed deliveries are
+// retried on the next check, at the cost of repeating them to the sinks that
+// succeeded. A repo whose feed cannot be fetched is logged and skipped, and
+// the state of the others is still saved.
+func (w *Watcher) Check(ctx context.Context) error {
+	state, err := LoadState(w.StatePath)
+	if err != nil {
+		return err
+	}
+	if err := w.Server.Refresh(ctx); err != nil {
+		return err
+	}
+
+	sent, failed := 0, 0
+	for _, repo := range repos(w.Server.Categories()) {
+		entries, err := w.Server.Releases(repo)
+		if err != nil {
+			log.Errorf("Error fetching releases of %s: %v", repo.Name, err)
+			continue
+		}
+		key := stateKey(repo)
+		fresh, seen := NewEntries(entries, state.Repos[key])
+		delivered := true
+		for _, entry := range fresh {
+			release := notify.NewRelease(repo.Name, entry)
+			log.Infof("New release %s", release.Summary())
+			for _, sink := range w.Sinks {
+				if err := notify.Deliver(ctx, sink, release, w.Retry); err != nil {
+					log.Errorf("%v", err)
+					delivered = false
+					failed++
+					continue
+				}
+				sent++
+			}
+		}
+		if delivered && seen != (Seen{}) {
+			state.Repos[key] = seen
+		}
+	}
+	if err := state.Save(w.StatePath); err != nil {
+		return fmt.Errorf("error saving state: %w", err)
+	}
+	if sent+failed > 0 {
+		log.Infof("Sent %d notifications, %d failed", sent, failed)
+	}
+	return nil
+}
+
+// repos returns the distinct repos of categories, keeping a repo listed with
+// different filter options once per filter.
+func repos(categories []server.Category) []server.Repo {
+	var list []server.Repo
+	seen := make(map[string]bool)
+	for _, category := range categories {
+		for _, repo := range category.Repos {
+			if key := stateKey(repo); !seen[key] {
+				seen[key] = true
+				list = append(list, repo)
+			}
+		}
+	}
+	return list
+}
+
+// stateKey identifies repo in the state by its name and filter options.
+func stateKey(repo server.Repo) string {
+	return strings.TrimSpace(repo.Name + " " + repo.Filter.String())
+}
@@ -0,0 +1,183 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/toozej/ghreleases2rss/internal/cache"
+	"github.com/toozej/ghreleases2rss/internal/feed"
+	"github.com/toozej/ghreleases2rss/internal/notify"
+	"github.com/toozej/ghreleases2rss/internal/server"
+)
+
+func entry(id string, day int) feed.Entry {
+	return feed.Entry{ID: id, Title: id, Updated: time.Date(2026, 3, day, 10, 0, 0, 0, time.UTC)}
+}
+
+func TestNewEntries(t *testing.T) {
+	entries := []feed.Entry{entry("v3", 3), entry("v2", 2), entry("v1", 1)}
+	tests := []struct {
+		name     string
+		entries  []feed.Entry
+		seen     Seen
+		want     []string
+		wantSeen string
+	}{
+		{name: "first sight", entries: entries, want: nil, wantSeen: "v3"},
+		{name: "nothing new", entries: entries, seen: Seen{ID: "v3", Updated: entries[0].Updated}, want: nil, wantSeen: "v3"},
+		{name: "new releases oldest first", entries: entries, seen: Seen{ID: "v1", Updated: entries[2].Updated}, want: []string{"v2", "v3"}, wantSeen: "v3"},
+		{name: "seen release removed", entries: entries, seen: Seen{ID: "v1.5", Updated: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}, want: []string{"v2", "v3"}, wantSeen: "v3"},
+		{name: "empty feed", seen: Seen{ID: "v1"}, want: nil, wantSeen: "v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fresh, seen := NewEntries(tt.entries, tt.seen)
+			var ids []string
+			for _, e := range fresh {
+				ids = append(ids, e.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("NewEntries() = %v, want %v", ids, tt.want)
+			}
+			if seen.ID != tt.wantSeen {
+				t.Errorf("NewEntries() seen = %q, want %q", seen.ID, tt.wantSeen)
+			}
+		})
+	}
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch", "state.json")
+	state, err := LoadState(path)
+	if err != nil || len(state.Repos) != 0 {
+		t.Fatalf("LoadState() of missing file = %v, %v", state, err)
+	}
+	state.Repos["owner/repo"] = Seen{ID: "v1", Updated: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, state) {
+		t.Errorf("LoadState() = %v, want %v", loaded, state)
+	}
+}
+
+// recorder is a sink that records the releases sent to it and fails while
+// failing is set.
+type recorder struct {
+	mu      sync.Mutex
+	sent    []string
+	failing atomic.Bool
+}
+
+func (r *recorder) Name() string { return "recorder" }
+
+func (r *recorder) Send(ctx context.Context, release notify.Release) error {
+	if r.failing.Load() {
+		return notify.Permanent(errors.New("rejected"))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, release.Summary())
+	return nil
+}
+
+const upstreamFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Release notes from repo</title>%s
+</feed>`
+
+const upstreamEntry = `
+  <entry>
+    <id>tag:github.com,2008:Repository/1/%[1]s</id>
+    <updated>2026-03-0%[2]dT10:00:00Z</updated>
+    <link rel="alternate" type="text/html" href="https://github.com/owner/repo/releases/tag/%[1]s"/>
+    <title>%[1]s</title>
+  </entry>`
+
+func TestWatcherCheck(t *testing.T) {
+	var mu sync.Mutex
+	tags := []string{"v1.0.0"}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/owner/repo/releases.atom" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		var entries strings.Builder
+		for i := len(tags) - 1; i >= 0; i-- {
+			fmt.Fprintf(&entries, upstreamEntry, tags[i], i+1)
+		}
+		fmt.Fprintf(w, upstreamFeed, entries.String())
+	}))
+	defer upstream.Close()
+	release := func(tag string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tags = append(tags, tag)
+	}
+
+	sink := &recorder{}
+	w := &Watcher{
+		Server: &server.Server{
+			BaseURL: upstream.URL,
+			Cache:   &cache.Cache{MaxAge: time.Nanosecond},
+			Load: func() ([]server.Category, error) {
+				return []server.Category{{Slug: "releases", Name: "Releases", Repos: []server.Repo{{Name: "owner/missing"}, {Name: "owner/repo"}}}}, nil
+			},
+		},
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Sinks:     []notify.Sink{sink},
+		Retry:     notify.Retry{Attempts: 1},
+	}
+	check := func(want ...string) {
+		t.Helper()
+		sink.mu.Lock()
+		sink.sent = nil
+		sink.mu.Unlock()
+		if err := w.Check(context.Background()); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if !reflect.DeepEqual(sink.sent, want) {
+			t.Errorf("Check() sent %v, want %v", sink.sent, want)
+		}
+	}
+
+	// The first check records the current release without sending it, even
+	// though the feed of owner/missing cannot be fetched
+	check()
+	state, err := LoadState(w.StatePath)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if _, ok := state.Repos["owner/repo"]; !ok {
+		t.Errorf("state = %v, want owner/repo saved", state.Repos)
+	}
+	check()
+
+	release("v1.1.0")
+	release("v1.2.0")
+	check("owner/repo v1.1.0", "owner/repo v1.2.0")
+	check()
+
+	// Failed deliveries are repeated on the next check
+	release("v1.3.0")
+	sink.failing.Store(true)
+	check()
+	sink.failing.Store(false)
+	check("owner/repo v1.3.0")
+}
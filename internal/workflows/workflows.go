@@ -0,0 +1,130 @@
+// Package workflows extracts the GitHub Actions and reusable workflows
+// referenced by `uses:` keys in workflow and composite action files.
+package workflows
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Action is a third-party action or reusable workflow referenced by `uses:`.
+type Action struct {
+	// Repo is the owner/repo that hosts the action.
+	Repo string
+	// Path is the optional subdirectory or workflow file within Repo.
+	Path string
+	// Ref is the tag, branch or commit SHA after the @.
+	Ref string
+	// Line is where the reference appears in its file.
+	Line int
+}
+
+// Find returns the workflow files under dir's .github/workflows directories
+// and every composite action.yml or action.yaml file below dir.
+func Find(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if IsWorkflowFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// IsWorkflowFile reports whether path is a workflow or composite action file.
+func IsWorkflowFile(path string) bool {
+	name := filepath.Base(path)
+	return isWorkflow(path) || name == "action.yml" || name == "action.yaml"
+}
+
+// isWorkflow reports whether path is a YAML file directly inside .github/workflows.
+func isWorkflow(path string) bool {
+	ext := filepath.Ext(path)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+	parent := filepath.Dir(path)
+	return filepath.Base(parent) == "workflows" && filepath.Base(filepath.Dir(parent)) == ".github"
+}
+
+// Parse returns the remote actions referenced by `uses:` keys anywhere in a
+// workflow or action file, covering job steps, reusable workflow jobs and
+// composite action steps. Local ./ actions and docker:// images are ignored.
+func Parse(data []byte) ([]Action, error) {
+	var actions []Action
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return actions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		walk(&doc, func(value *yaml.Node) {
+			if action, ok := ParseUses(value.Value); ok {
+				action.Line = value.Line
+				actions = append(actions, action)
+			}
+		})
+	}
+}
+
+// walk calls fn for the scalar value of every `uses` key below node.
+func walk(node *yaml.Node, fn func(*yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "uses" && value.Kind == yaml.ScalarNode {
+				fn(value)
+				continue
+			}
+			walk(value, fn)
+		}
+		return
+	}
+	for _, child := range node.Content {
+		walk(child, fn)
+	}
+}
+
+// ParseUses parses an owner/repo[/path]@ref reference. It returns false for
+// local actions, docker:// images and malformed references.
+func ParseUses(uses string) (Action, bool) {
+	uses = strings.TrimSpace(uses)
+	if uses == "" || strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "../") || strings.HasPrefix(uses, "docker://") {
+		return Action{}, false
+	}
+
+	target, ref, found := strings.Cut(uses, "@")
+	if !found || ref == "" || strings.Contains(target, "${{") {
+		return Action{}, false
+	}
+	parts := strings.SplitN(target, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return Action{}, false
+	}
+
+	action := Action{Repo: parts[0] + "/" + parts[1], Ref: ref}
+	if len(parts) == 3 {
+		action.Path = parts[2]
+	}
+	return action, true
+}
@@ -0,0 +1,116 @@
+package workflows
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testWorkflow = `name: ci
+on: [push]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/local
+      - uses: docker://alpine:3.20
+      - name: Setup
+        uses: "actions/setup-go@0a12ed9d6a96ab950c8f026ed9f722fe0da7ef32"
+      - uses: github/codeql-action/init@v3
+  release:
+    uses: toozej/workflows/.github/workflows/release.yml@main
+`
+
+const testCompositeAction = `name: composite
+runs:
+  using: composite
+  steps:
+    - uses: aquasecurity/trivy-action@0.28.0
+    - run: echo done
+      shell: bash
+`
+
+func TestParse(t *testing.T) {
+	got, err := Parse([]byte(testWorkflow))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Action{
+		{Repo: "actions/checkout", Ref: "v4", Line: 7},
+		{Repo: "actions/setup-go", Ref: "0a12ed9d6a96ab950c8f026ed9f722fe0da7ef32", Line: 11},
+		{Repo: "github/codeql-action", Path: "init", Ref: "v3", Line: 12},
+		{Repo: "toozej/workflows", Path: ".github/workflows/release.yml", Ref: "main", Line: 14},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Parse() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Parse()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseUses(t *testing.T) {
+	tests := []struct {
+		uses   string
+		wantOK bool
+	}{
+		{"actions/checkout@v4", true},
+		{"./local-action", false},
+		{"docker://ghcr.io/owner/image:1", false},
+		{"actions/checkout", false},
+		{"owner@v1", false},
+		{"${{ matrix.action }}@v1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uses, func(t *testing.T) {
+			if _, ok := ParseUses(tt.uses); ok != tt.wantOK {
+				t.Errorf("ParseUses(%q) ok = %v, want %v", tt.uses, ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".github/workflows/ci.yml":            testWorkflow,
+		".github/workflows/notes.md":          "not a workflow",
+		".github/actions/scan/action.yml":     testCompositeAction,
+		"node_modules/pkg/action.yml":         testCompositeAction,
+		"deploy/.github/workflows/cd.yaml":    testWorkflow,
+		"config/workflows/not-github.yml":     testWorkflow,
+		"services/api/.github/dependabot.yml": "version: 2",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Find(dir)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, ".github", "actions", "scan", "action.yml"),
+		filepath.Join(dir, ".github", "workflows", "ci.yml"),
+		filepath.Join(dir, "deploy", ".github", "workflows", "cd.yaml"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Find() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Find()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -11,9 +11,11 @@
 //  3. Default values (if any)
 //
 // Security features:
-//   - Path traversal protection for .env file loading
+//   - An allowlist of directories that local files may be read from, which
+//     defaults to the current working directory
+//   - Symlink resolution before checking a file against the allowlist
+//   - Path traversal protection for .env and input file loading
 //   - Secure file path resolution using filepath.Abs and filepath.Rel
-//   - Validation against directory traversal attempts
 //
 // Example usage:
 //
@@ -33,6 +35,7 @@ import (
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+	log "github.com/sirupsen/logrus"
 )
 
 // Config represents the application configuration structure.
@@ -44,6 +47,13 @@ import (
 // Currently supported configuration:
 //   - MinifluxAPIKey: The API key for Miniflux RSS reader
 //   - MinifluxURL: The URL endpoint for Miniflux API
+//   - InputToken: Bearer token for remote input sources
+//   - CacheDir: Directory for cached remote data
+//   - AllowedDirs: Directories that local files may be read from
+//   - NPMRegistryURL, PyPIURL, CratesURL: Package registry base URLs
+//   - TerraformRegistryURL: Terraform provider registry base URL
+//   - GitHubToken, GitHubAPIURL: GitHub REST API token and endpoint
+//   - WebhookToken, NtfyToken, SMTPUsername, SMTPPassword: Notification sink credentials
 //
 // Example:
 //
@@ -61,31 +71,100 @@ type Config struct {
 	// It is loaded from the MINIFLUX_URL environment variable.
 	// This field is required for the application to function.
 	MinifluxURL string `env:"MINIFLUX_URL"`
+
+	// InputToken specifies a bearer token sent to HTTPS and git+https input sources.
+	// It is loaded from the INPUT_TOKEN environment variable.
+	// This field is optional and only needed for private remote input lists.
+	InputToken string `env:"INPUT_TOKEN"`
+
+	// CacheDir specifies where remote input sources and their ETags are cached.
+	// It is loaded from the CACHE_DIR environment variable.
+	// When empty, a ghreleases2rss directory in the user cache directory is used.
+	CacheDir string `env:"CACHE_DIR"`
+
+	// AllowedDirs lists the directories that input files and the .env file may
+	// be read from, after resolving symlinks. It is loaded from the comma-separated
+	// ALLOWED_DIRS environment variable and extended by the --allowed-dir flag.
+	// GetEnvVars replaces it with the resolved absolute roots, defaulting to the
+	// current working directory.
+	AllowedDirs []string `env:"ALLOWED_DIRS" envSeparator:","`
+
+	// NPMRegistryURL specifies the npm registry used to resolve package.json dependencies.
+	// It is loaded from the NPM_REGISTRY_URL environment variable.
+	// When empty, https://registry.npmjs.org is used.
+	NPMRegistryURL string `env:"NPM_REGISTRY_URL"`
+
+	// PyPIURL specifies the Python package index used to resolve Python dependencies.
+	// It is loaded from the PYPI_URL environment variable.
+	// When empty, https://pypi.org is used.
+	PyPIURL string `env:"PYPI_URL"`
+
+	// CratesURL specifies the crates registry used to resolve Cargo.toml dependencies.
+	// It is loaded from the CRATES_URL environment variable.
+	// When empty, https://crates.io is used.
+	CratesURL string `env:"CRATES_URL"`
+
+	// TerraformRegistryURL specifies the provider registry used to resolve
+	// .terraform.lock.hcl providers. It is loaded from the TERRAFORM_REGISTRY_URL
+	// environment variable. When empty, each provider's own registry hostname is used.
+	TerraformRegistryURL string `env:"TERRAFORM_REGISTRY_URL"`
+
+	// GitHubToken specifies the token used to authenticate GitHub REST API requests.
+	// It is loaded from the GITHUB_TOKEN environment variable.
+	// This field is optional, but unauthenticated requests are limited to 60 per hour.
+	GitHubToken string `env:"GITHUB_TOKEN"`
+
+	// GitHubAPIURL specifies the GitHub REST API endpoint, e.g. for GitHub Enterprise Server.
+	// It is loaded from the GITHUB_API_URL environment variable.
+	// When empty, https://api.github.com is used.
+	GitHubAPIURL string `env:"GITHUB_API_URL"`
+
+	// WebhookToken specifies a bearer token sent to the watch command's webhook.
+	// It is loaded from the WEBHOOK_TOKEN environment variable.
+	// This field is optional.
+	WebhookToken string `env:"WEBHOOK_TOKEN"`
+
+	// NtfyToken specifies a bearer token sent to the watch command's ntfy topic.
+	// It is loaded from the NTFY_TOKEN environment variable.
+	// This field is optional and only needed for protected topics.
+	NtfyToken string `env:"NTFY_TOKEN"`
+
+	// SMTPUsername and SMTPPassword authenticate the watch command with its SMTP server.
+	// They are loaded from the SMTP_USERNAME and SMTP_PASSWORD environment variables.
+	// These fields are optional; mail is sent unauthenticated when SMTPUsername is empty.
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
 }
 
 // GetEnvVars loads and returns the application configuration from environment
 // variables and .env files with comprehensive security validation.
 //
 // This function performs the following operations:
-//  1. Securely determines the current working directory
-//  2. Constructs and validates the .env file path to prevent traversal attacks
-//  3. Loads .env file if it exists in the current directory
+//  1. Determines the allowed directories from the allowedDirs arguments and
+//     the ALLOWED_DIRS environment variable, defaulting to the current directory
+//  2. Checks that the .env file in the current directory, if it exists,
+//     resolves to a path inside an allowed directory, and skips it with a
+//     warning otherwise
+//  3. Loads the .env file if it exists and is allowed
 //  4. Parses environment variables into the Config struct
-//  5. Returns the populated configuration
+//  5. Resolves the final allowed directories, including any set in .env
+//  6. Returns the populated configuration
 //
 // Security measures implemented:
+//   - The same allowlist policy as input files, via ResolveAllowedPath
+//   - Symlink resolution so a .env symlink cannot point outside the allowlist
 //   - Path traversal detection and prevention using filepath.Rel
-//   - Absolute path resolution for secure path operations
-//   - Validation against ".." sequences in relative paths
 //   - Safe file existence checking before loading
 //
 // The function will terminate the program with os.Exit(1) if any critical
 // errors occur during configuration loading, such as:
 //   - Current directory access failures
-//   - Path traversal attempts detected
+//   - Allowed directories that do not exist
 //   - .env file parsing errors
 //   - Environment variable parsing failures
-//   - Required configuration values missing
+//
+// Parameters:
+//   - allowedDirs: Optional extra allowed directories, e.g. from a flag
 //
 // Returns:
 //   - Config: A populated configuration struct with values from environment
@@ -100,7 +179,7 @@ type Config struct {
 //	if conf.MinifluxAPIKey != "" {
 //		fmt.Printf("API Key configured\n")
 //	}
-func GetEnvVars() Config {
+func GetEnvVars(allowedDirs ...string) Config {
 	// Get current working directory for secure file operations
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -108,29 +187,25 @@ func GetEnvVars() Config {
 		os.Exit(1)
 	}
 
-	// Construct secure path for .env file within current directory
-	envPath := filepath.Join(cwd, ".env")
-
-	// Ensure the path is within our expected directory (prevent traversal)
-	cleanEnvPath, err := filepath.Abs(envPath)
-	if err != nil {
-		fmt.Printf("Error resolving .env file path: %s\n", err)
-		os.Exit(1)
+	// Determine the allowed directories before anything is read from disk
+	envDirs := allowedDirs
+	if fromEnv := os.Getenv("ALLOWED_DIRS"); fromEnv != "" {
+		envDirs = append(append([]string{}, allowedDirs...), strings.Split(fromEnv, ",")...)
 	}
-	cleanCwd, err := filepath.Abs(cwd)
+	roots, err := AllowedRoots(envDirs)
 	if err != nil {
-		fmt.Printf("Error resolving current directory: %s\n", err)
-		os.Exit(1)
-	}
-	relPath, err := filepath.Rel(cleanCwd, cleanEnvPath)
-	if err != nil || strings.Contains(relPath, "..") {
-		fmt.Printf("Error: .env file path traversal detected\n")
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
 	}
 
-	// Load .env file if it exists
+	// Construct path for .env file within current directory
+	envPath := filepath.Join(cwd, ".env")
+
+	// Load .env file if it exists, but only from an allowed directory
 	if _, err := os.Stat(envPath); err == nil {
-		if err := godotenv.Load(envPath); err != nil {
+		if resolvedEnvPath, err := ResolveAllowedPath(envPath, roots); err != nil {
+			log.Warnf("Skipping .env file: %s", err)
+		} else if err := godotenv.Load(resolvedEnvPath); err != nil {
 			fmt.Printf("Error loading .env file: %s\n", err)
 			os.Exit(1)
 		}
@@ -143,9 +218,119 @@ func GetEnvVars() Config {
 		os.Exit(1)
 	}
 
+	// Allowed directories may also come from the .env file, so resolve the final list
+	conf.AllowedDirs, err = AllowedRoots(append(append([]string{}, allowedDirs...), conf.AllowedDirs...))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
 	return conf
 }
 
+// AllowedRoots resolves a list of allowed directories into absolute paths.
+//
+// Each directory is made absolute and has its symlinks resolved, so later
+// checks compare real locations on disk. Empty entries are ignored and
+// duplicates are removed. When no directories are given, the current working
+// directory is the only allowed root, which matches the historical behavior.
+//
+// Parameters:
+//   - dirs: Directories from flags, environment variables or configuration
+//
+// Returns:
+//   - []string: The resolved allowed root directories
+//   - error: An error if a directory cannot be resolved or is not a directory
+//
+// Example:
+//
+//	roots, err := config.AllowedRoots([]string{"/config"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func AllowedRoots(dirs []string) ([]string, error) {
+	var cleaned []string
+	for _, dir := range dirs {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			cleaned = append(cleaned, dir)
+		}
+	}
+	if len(cleaned) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("error getting current working directory: %w", err)
+		}
+		cleaned = []string{cwd}
+	}
+
+	var roots []string
+	seen := make(map[string]bool)
+	for _, dir := range cleaned {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving allowed directory %s: %w", dir, err)
+		}
+		absDir, err = filepath.EvalSymlinks(absDir)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving allowed directory %s: %w", dir, err)
+		}
+		info, err := os.Stat(absDir)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving allowed directory %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("allowed directory %s is not a directory", dir)
+		}
+		if !seen[absDir] {
+			seen[absDir] = true
+			roots = append(roots, absDir)
+		}
+	}
+	return roots, nil
+}
+
+// ResolveAllowedPath resolves a file path and checks it against the allowlist.
+//
+// The path is made absolute and its symlinks are resolved before it is
+// compared with each allowed root using filepath.Rel, so neither ".."
+// sequences nor symlinks can reach files outside the allowed directories.
+//
+// Parameters:
+//   - path: The file path to check, relative paths are resolved against the
+//     current working directory
+//   - roots: Allowed root directories, as returned by AllowedRoots
+//
+// Returns:
+//   - string: The resolved absolute path that is safe to open
+//   - error: An error naming the allowed roots if the path is outside all of them
+//
+// Example:
+//
+//	resolved, err := config.ResolveAllowedPath("/config/repos.txt", conf.AllowedDirs)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	file, err := os.Open(resolved)
+func ResolveAllowedPath(path string, roots []string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("error resolving file path: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return "", fmt.Errorf("error resolving file path: %w", err)
+	}
+
+	for _, root := range roots {
+		relPath, err := filepath.Rel(root, resolved)
+		if err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s resolves to %s, which is outside the allowed directories (%s); use --allowed-dir or ALLOWED_DIRS to allow it", path, resolved, strings.Join(roots, ", "))
+}
+
 // ValidateRequired validates that all required configuration values are present.
 //
 // This function checks that essential configuration fields are not empty.
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -98,3 +100,77 @@ func TestValidateRequired(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveAllowedPath(t *testing.T) {
+	base := t.TempDir()
+	allowed := filepath.Join(base, "config")
+	other := filepath.Join(base, "other")
+	for _, dir := range []string{allowed, other} {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{filepath.Join(allowed, "repos.txt"), filepath.Join(other, "secret.txt")} {
+		if err := os.WriteFile(file, []byte("username/repo\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(other, "secret.txt"), filepath.Join(allowed, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	roots, err := AllowedRoots([]string{allowed, "", allowed})
+	if err != nil {
+		t.Fatalf("AllowedRoots() error = %v", err)
+	}
+	if len(roots) != 1 {
+		t.Fatalf("AllowedRoots() = %v, want a single deduplicated root", roots)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		expectErr bool
+	}{
+		{name: "File in allowed directory", path: filepath.Join(allowed, "repos.txt"), expectErr: false},
+		{name: "Traversal out of allowed directory", path: filepath.Join(allowed, "..", "other", "secret.txt"), expectErr: true},
+		{name: "Symlink out of allowed directory", path: filepath.Join(allowed, "link.txt"), expectErr: true},
+		{name: "Missing file", path: filepath.Join(allowed, "missing.txt"), expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveAllowedPath(tt.path, roots)
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+			if tt.expectErr && err != nil && tt.name != "Missing file" && !strings.Contains(err.Error(), roots[0]) {
+				t.Errorf("Expected error to name the allowed directory, got: %v", err)
+			}
+		})
+	}
+
+	if _, err := AllowedRoots([]string{filepath.Join(allowed, "repos.txt")}); err == nil {
+		t.Errorf("Expected error for an allowed directory that is a file")
+	}
+}
+
+func TestGetEnvVarsSkipsDisallowedEnvFile(t *testing.T) {
+	cwd := t.TempDir()
+	if err := os.WriteFile(filepath.Join(cwd, ".env"), []byte("MINIFLUX_URL=https://from-env-file.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(cwd)
+	// Setenv restores the variable after the test, Unsetenv lets .env set it
+	t.Setenv("MINIFLUX_URL", "")
+	os.Unsetenv("MINIFLUX_URL")
+
+	// The current directory is not allowed once other directories are given
+	conf := GetEnvVars(t.TempDir())
+	if conf.MinifluxURL != "" {
+		t.Errorf("GetEnvVars() loaded .env outside the allowed directories, MinifluxURL = %q", conf.MinifluxURL)
+	}
+}